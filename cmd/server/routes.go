@@ -5,10 +5,15 @@ import (
 	"net/http"
 
 	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
 	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/handlers/rest"
+	"github.com/aarthikrao/timeMachine/process/clusterhealth"
 	"github.com/aarthikrao/timeMachine/process/cordinator"
 	"github.com/aarthikrao/timeMachine/process/nodemanager"
+	"github.com/aarthikrao/timeMachine/process/replicator"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -18,13 +23,18 @@ func InitTimeMachineHttpServer(
 	cp *cordinator.CordinatorProcess,
 	appDht dht.DHT,
 	con consensus.Consensus,
+	nodeConfig fsm.NodeConfig,
 	nodeMgr *nodemanager.NodeManager,
+	shardReplicator *replicator.Replicator,
+	healthChecker *clusterhealth.ClusterHealth,
 	log *zap.Logger,
 	port int,
 ) *http.Server {
 	r := gin.Default()
 	r.Use(cors.Default())
 	r.Use(gin.Recovery())
+	r.Use(metrics.GinMiddleware())
+	r.Use(tracing.GinMiddleware())
 	// gin.SetMode(gin.ReleaseMode)
 
 	// Health handler
@@ -35,26 +45,51 @@ func InitTimeMachineHttpServer(
 		})
 	})
 
+	// Prometheus metrics
+	r.GET("/metrics", metrics.Handler())
+
 	// Cluster handlers
-	crh := rest.CreateClusterRestHandler(con, appDht, nodeMgr, log)
+	crh := rest.CreateClusterRestHandler(con, nodeConfig, appDht, nodeMgr, shardReplicator, healthChecker, log)
 	cluster := r.Group("/cluster")
 	{
 		cluster.GET("", crh.GetStats)
 		cluster.GET("/servers", crh.GetConfigurations)
+		cluster.GET("/shards", crh.GetShards)
+		cluster.GET("/failovers", crh.GetFailovers)
+		cluster.GET("/health", crh.GetHealth)
 		cluster.POST("/join", crh.Join)
 		cluster.POST("/remove", crh.Remove)
 		cluster.POST("/configure", crh.Configure)
+		cluster.POST("/redistribute", crh.Redistribute)
+		cluster.POST("/drain/:nodeID", crh.Drain)
+		cluster.GET("/drain/:nodeID", crh.GetDrainStatus)
+		cluster.POST("/undrain/:nodeID", crh.Undrain)
+		cluster.GET("/replication", crh.GetReplicationStatus)
 	}
 
 	// Job handlers
 	jrh := rest.CreateJobRestHandler(cp, log)
 	job := r.Group("/job")
 	{
+		job.GET("/:collection", jrh.ListJobs)
 		job.GET("/:collection/:jobID", jrh.GetJob)
+		job.GET("/:collection/:jobID/status", jrh.GetJobStatus)
+		job.GET("/:collection/:jobID/history", jrh.GetJobHistory)
+		job.POST("/:collection/:jobID/cancel", jrh.CancelJob)
 		job.POST("/:collection", jrh.SetJob)
+		job.POST("/:collection/batch", jrh.SetJobs)
+		job.POST("/:collection/batch/delete", jrh.DeleteJobs)
 		job.DELETE("/:collection/:jobID", jrh.DeleteJob)
 	}
 
+	// Dead letter handlers
+	dlh := rest.CreateDeadLetterRestHandler(cp, log)
+	deadLetter := r.Group("/deadletter")
+	{
+		deadLetter.GET("/:collection", dlh.GetDeadLetterJobs)
+		deadLetter.POST("/:collection/:jobID/redrive", dlh.RedriveJob)
+	}
+
 	// Route Handlers
 	rrh := rest.CreateRouteRestHandler(cp, log)
 	route := r.Group("/route")
@@ -64,6 +99,15 @@ func InitTimeMachineHttpServer(
 		route.DELETE("/:id", rrh.DeleteRoute)
 	}
 
+	// Collection Handlers
+	colh := rest.CreateCollectionRestHandler(cp, log)
+	collection := r.Group("/collection")
+	{
+		collection.GET("/:name", colh.GetCollection)
+		collection.POST("/", colh.SetCollection)
+		collection.DELETE("/:name", colh.DeleteCollection)
+	}
+
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: r,
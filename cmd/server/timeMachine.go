@@ -9,10 +9,12 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aarthikrao/timeMachine/components/collectionstore"
 	"github.com/aarthikrao/timeMachine/components/consensus"
 	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
 	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/components/executor"
+	"github.com/aarthikrao/timeMachine/components/failuredetector"
 	"github.com/aarthikrao/timeMachine/components/network/server"
 	"github.com/aarthikrao/timeMachine/components/routestore"
 	"github.com/aarthikrao/timeMachine/models/jobmodels"
@@ -22,9 +24,12 @@ import (
 	dsm "github.com/aarthikrao/timeMachine/process/datastoremanager"
 	"github.com/aarthikrao/timeMachine/process/nodemanager"
 	"github.com/aarthikrao/timeMachine/process/publisher"
+	"github.com/aarthikrao/timeMachine/process/replicator"
 	"github.com/aarthikrao/timeMachine/utils/constants"
 	"github.com/aarthikrao/timeMachine/utils/httpclient"
 	"github.com/aarthikrao/timeMachine/utils/kafkaclient"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"go.uber.org/zap"
 )
 
@@ -35,6 +40,25 @@ var (
 	raftPort  = flag.Int("raftPort", 8101, "raft listening port")
 	httpPort  = flag.Int("httpPort", 8001, "http listening port")
 	bootstrap = flag.Bool("bootstrap", false, "Bootstrap mode. Should be `true` for the first node of the cluster")
+
+	misfirePolicy     = flag.String("misfirePolicy", string(jobmodels.DefaultMisfirePolicy), "Default misfire policy of the collections. fire_late, skip or dead_letter")
+	misfireThreshold  = flag.Duration("misfireThreshold", time.Minute, "Jobs picked up later than this after their trigger time are misfired")
+	catchUpWindow     = flag.Duration("catchUpWindow", 24*time.Hour, "Undelivered jobs due within this window are picked up when this node becomes a shard leader")
+	idempotencyWindow = flag.Duration("idempotencyWindow", 24*time.Hour, "Sets of a job with the same idempotency key within this window are applied only once")
+
+	traceExporter = flag.String("traceExporter", tracing.ExporterNone, "Exporter of the opentelemetry spans. none or stdout")
+
+	hashing      = flag.String("hashing", dht.HashingMod, "Hashing of the job IDs to the shards. mod or ring. Must be the same on all the nodes")
+	virtualNodes = flag.Int("virtualNodes", dht.DefaultVirtualNodes, "Points of every shard and node on the consistent hashing ring. Must be the same on all the nodes")
+
+	healthInterval   = flag.Duration("healthInterval", time.Second, "Interval of the health checks of the nodes by the raft leader")
+	healthTimeout    = flag.Duration("healthTimeout", 500*time.Millisecond, "Timeout of the health check of every node")
+	suspectThreshold = flag.Float64("suspectThreshold", 5, "Phi of the failure detector at which a node is suspected")
+	failureThreshold = flag.Float64("failureThreshold", 8, "Phi of the failure detector at which a node is replaced in the shard map")
+	balanceInterval  = flag.Duration("leaderBalanceInterval", time.Minute, "Interval at which the leadership of the shards is balanced across the nodes by the raft leader")
+	balanceShards    = flag.Int("leaderBalanceShards", 1, "Maximum number of shards whose leadership is handed over every balance interval. 0 disables the balancing")
+	maxFailoverLag   = flag.Int64("maxFailoverLag", 100, "Maximum number of wal entries a follower can be behind a failed leader to be promoted. A higher lag fails over sooner but can lose the writes only the failed leader has, 0 never loses them but leaves the shard unavailable till the leader returns")
+	catchUpTimeout   = flag.Duration("catchUpTimeout", 30*time.Minute, "Time given to the spare nodes to copy the shards of a failed node, and to the failed node to copy them again once it comes back")
 )
 
 func main() {
@@ -44,6 +68,17 @@ func main() {
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
+	if err := jobmodels.MisfirePolicy(*misfirePolicy).Valid(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	detectorConfig := failuredetector.DefaultConfig(*healthInterval)
+	detectorConfig.SuspectThreshold, detectorConfig.FailureThreshold = *suspectThreshold, *failureThreshold
+	if err := detectorConfig.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	// Prepare data and raft folder
 	baseDir := *dataDir + "/" + *nodeID
@@ -53,10 +88,22 @@ func main() {
 	log, _ := zap.NewDevelopment()
 	log = log.With(zap.String("nodeID", *nodeID))
 
+	shutdownTracing, err := tracing.Init(*traceExporter, *nodeID)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// appDht will store the distributed hash table of this node
+	appDht, err := dht.New(*hashing, *virtualNodes)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	var (
-		// appDht will store the distributed hash table of this node
-		appDht      dht.DHT                              = dht.Create()
 		rStore      *routestore.RouteStore               = routestore.InitRouteStore()
+		cStore      *collectionstore.CollectionStore     = collectionstore.InitCollectionStore()
 		dsmgr       *dsm.DataStoreManager                = dsm.CreateDataStore(boltDataDir, log)
 		connMgr     *connectionmanager.ConnectionManager = connectionmanager.CreateConnectionManager(log, 10*time.Second) // TODO: Add to config
 		jobChannel                                       = make(chan *jobmodels.Job)
@@ -65,6 +112,8 @@ func main() {
 		kafkaClient *kafkaclient.KafkaClient             = kafkaclient.NewKafkaClient()
 	)
 
+	metrics.RegisterExecutorHeapSize(exe.Len)
+
 	pubRouter := publisher.NewPublisher(
 		httpClient,
 		kafkaClient,
@@ -77,6 +126,7 @@ func main() {
 	fsmStore := fsm.NewConfigFSM(
 		appDht,
 		rStore,
+		cStore,
 		log,
 	)
 
@@ -93,6 +143,7 @@ func main() {
 		log.Fatal("Unable to start raft")
 		panic(err)
 	}
+	metrics.RegisterRaftStats(raft.Stats)
 
 	// Initialise node manager
 	nodeMgr := nodemanager.CreateNodeManager(
@@ -101,7 +152,10 @@ func main() {
 		connMgr,
 		appDht,
 		raft,
+		fsmStore,
 		exe,
+		*catchUpWindow,
+		*idempotencyWindow,
 		log,
 	)
 
@@ -109,17 +163,39 @@ func main() {
 	// We will initialise the connections in the nodeMgr with the latest cluster configuration
 	fsmStore.SetChangeHandler(nodeMgr.InitialiseNode)
 
+	// Keeps the follower shards on this node in sync with their leaders
+	shardReplicator := replicator.CreateReplicator(
+		*nodeID,
+		appDht,
+		dsmgr,
+		connMgr,
+		10*time.Second, // TODO: Move to config
+		log,
+	)
+
 	// Initialise process
 	cordinatorProcess := cordinator.CreateCordinatorProcess(
 		*nodeID,
 		nodeMgr,
 		rStore,
+		cStore,
 		raft,
 		appDht,
 		exe,
+		shardReplicator,
+		jobmodels.MisfirePolicy(*misfirePolicy),
+		*misfireThreshold,
+		*idempotencyWindow,
 		log,
 	)
 
+	// The cordinator claims the jobs before they are published, and records their delivery
+	// or reschedules the recurring jobs once they are published. The jobs that could not be
+	// published are retried or moved to the dead letter collection
+	pubRouter.SetDispatchHandler(cordinatorProcess.ClaimJob)
+	pubRouter.SetPublishedHandler(cordinatorProcess.CompleteJob)
+	pubRouter.SetFailedHandler(cordinatorProcess.FailJob)
+
 	if !*bootstrap {
 		nodeMgr.InitialiseNode()
 
@@ -133,20 +209,32 @@ func main() {
 		log.Warn("NodeVsSlot and datastores not yet initialised. Consider rebalancing the cluster once started")
 	}
 
-	clusterhealth.CreateClusterHealthChecker(
+	healthChecker, err := clusterhealth.CreateClusterHealthChecker(
 		appDht,
 		raft,
 		connMgr,
-		10*time.Second, // TODO: Move to config
-		2,
+		nodeMgr,
+		*healthInterval,
+		*healthTimeout,
+		detectorConfig,
+		*maxFailoverLag,
+		*catchUpTimeout,
+		*balanceInterval,
+		*balanceShards,
 		log,
 	)
+	if err != nil {
+		log.Fatal("Unable to start the health checker", zap.Error(err))
+	}
 
 	srv := InitTimeMachineHttpServer(
 		cordinatorProcess,
 		appDht,
 		raft,
+		fsmStore,
 		nodeMgr,
+		shardReplicator,
+		healthChecker,
 		log,
 		*httpPort,
 	)
@@ -174,6 +262,7 @@ func main() {
 	exe.Close()
 	pubRouter.Wait()
 	grpcServer.Close()
+	shutdownTracing(context.Background())
 
 	log.Info("shutdown completed")
 	log.Sync()
@@ -0,0 +1,63 @@
+package collectionstore
+
+import (
+	"sync"
+
+	cm "github.com/aarthikrao/timeMachine/models/collectionmodels"
+)
+
+type CollectionStore struct {
+	m  map[string]*cm.Collection
+	mu sync.RWMutex
+}
+
+func InitCollectionStore() *CollectionStore {
+	return &CollectionStore{
+		m: make(map[string]*cm.Collection),
+	}
+}
+
+func (cs *CollectionStore) AddCollection(name string, collection *cm.Collection) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.m[name] = collection
+}
+
+func (cs *CollectionStore) RemoveCollection(name string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	delete(cs.m, name)
+}
+
+func (cs *CollectionStore) GetCollection(name string) *cm.Collection {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	return cs.m[name]
+}
+
+// Snapshot returns a copy of the current snapshot of the collection store
+func (cs *CollectionStore) Snapshot() map[string]*cm.Collection {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+
+	m := make(map[string]*cm.Collection, len(cs.m))
+	for name, collection := range cs.m {
+		m[name] = collection
+	}
+
+	return m
+}
+
+// Load replaces the collections in the collection store with a copy of the map
+func (cs *CollectionStore) Load(m map[string]*cm.Collection) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.m = make(map[string]*cm.Collection, len(m))
+	for name, collection := range m {
+		cs.m[name] = collection
+	}
+}
@@ -134,7 +134,16 @@ func (r *raftConsensus) GetLeaderAddress() string {
 // Apply is used to apply a command to the FSM
 func (r *raftConsensus) Apply(cmd []byte) error {
 	applyResponse := r.raft.Apply(cmd, 500*time.Millisecond)
-	return applyResponse.Error()
+	if err := applyResponse.Error(); err != nil {
+		return err
+	}
+
+	// The FSM rejected the command
+	if err, ok := applyResponse.Response().(error); ok {
+		return err
+	}
+
+	return nil
 }
 
 // GetConfigurations returns the list of servers in the cluster
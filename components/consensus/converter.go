@@ -5,12 +5,16 @@ import (
 
 	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
 	"github.com/aarthikrao/timeMachine/components/dht"
+	cm "github.com/aarthikrao/timeMachine/models/collectionmodels"
 	rm "github.com/aarthikrao/timeMachine/models/routemodels"
 )
 
-func ConvertConfigSnapshot(shards map[dht.ShardID]dht.ShardLocation) ([]byte, error) {
+// ConvertConfigSnapshot converts the shard map to a raft command. epoch must be greater than the epoch
+// of the shard map the change was computed from, else the change is rejected with fsm.ErrStaleEpoch
+func ConvertConfigSnapshot(shards map[dht.ShardID]dht.ShardLocation, epoch uint64) ([]byte, error) {
 	cs := fsm.ConfigSnapshot{
 		Shards: shards,
+		Epoch:  epoch,
 	}
 	by, err := json.Marshal(&cs)
 	if err != nil {
@@ -25,6 +29,51 @@ func ConvertConfigSnapshot(shards map[dht.ShardID]dht.ShardLocation) ([]byte, er
 	return json.Marshal(&cmd)
 }
 
+// ConvertFailoverDecisions converts the failover decisions of the shards led by a failed node to a raft command
+func ConvertFailoverDecisions(decisions []fsm.FailoverDecision) ([]byte, error) {
+	by, err := json.Marshal(decisions)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := fsm.Command{
+		Operation: fsm.RecordFailover,
+		Data:      by,
+	}
+
+	return json.Marshal(&cmd)
+}
+
+// ConvertDrainNode converts the drain or undrain of a node to a raft command
+func ConvertDrainNode(nodeID dht.NodeID, drained bool) ([]byte, error) {
+	by, err := json.Marshal(&fsm.Drain{NodeID: nodeID, Drained: drained})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := fsm.Command{
+		Operation: fsm.DrainNode,
+		Data:      by,
+	}
+
+	return json.Marshal(&cmd)
+}
+
+// ConvertReplacement converts the shards of a replaced node to a raft command. No shards means that the node rejoined them
+func ConvertReplacement(nodeID dht.NodeID, shards map[dht.ShardID]dht.NodeID) ([]byte, error) {
+	by, err := json.Marshal(&fsm.Replacement{NodeID: nodeID, Shards: shards})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := fsm.Command{
+		Operation: fsm.RecordReplacement,
+		Data:      by,
+	}
+
+	return json.Marshal(&cmd)
+}
+
 func ConvertAddRoute(route *rm.Route) ([]byte, error) {
 	by, err := json.Marshal(&route)
 	if err != nil {
@@ -58,3 +107,31 @@ func ConvertRemoveRoute(routeName string) ([]byte, error) {
 
 	return json.Marshal(&cmd)
 }
+
+func ConvertAddCollection(collection *cm.Collection) ([]byte, error) {
+	by, err := json.Marshal(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := fsm.Command{
+		Operation: fsm.AddCollection,
+		Data:      by,
+	}
+
+	return json.Marshal(&cmd)
+}
+
+func ConvertRemoveCollection(name string) ([]byte, error) {
+	by, err := json.Marshal(&cm.Collection{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := fsm.Command{
+		Operation: fsm.RemoveCollection,
+		Data:      by,
+	}
+
+	return json.Marshal(&cmd)
+}
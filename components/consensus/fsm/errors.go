@@ -0,0 +1,11 @@
+package fsm
+
+import "errors"
+
+var (
+	// The snapshot was written by a newer version of timeMachine
+	ErrUnsupportedSnapshotVersion = errors.New("unsupported snapshot version")
+
+	// The change of the shard map was proposed from an older epoch than the one applied
+	ErrStaleEpoch = errors.New("stale shard map epoch")
+)
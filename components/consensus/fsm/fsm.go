@@ -1,8 +1,19 @@
 package fsm
 
+import "github.com/aarthikrao/timeMachine/components/dht"
+
 type NodeConfig interface {
 	// Returns the last updated time
 	GetLastUpdatedTime() int
 
 	SetChangeHandler(func() error)
+
+	// Returns the latest failover decisions, oldest first
+	GetFailovers() []FailoverDecision
+
+	// Returns true if the node is drained for maintenance
+	IsDrained(nodeID dht.NodeID) bool
+
+	// Returns the shards the node owned when it was replaced after a failure, along with the spares added in its place
+	GetReplacedShards(nodeID dht.NodeID) map[dht.ShardID]dht.NodeID
 }
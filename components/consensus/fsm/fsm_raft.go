@@ -4,21 +4,37 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"sort"
 	"sync"
 
+	"github.com/aarthikrao/timeMachine/components/collectionstore"
 	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/components/routestore"
+	cm "github.com/aarthikrao/timeMachine/models/collectionmodels"
 	rm "github.com/aarthikrao/timeMachine/models/routemodels"
 	"github.com/hashicorp/raft"
 	"go.uber.org/zap"
 )
 
+// maxFailovers is the number of latest failover decisions kept in the FSM
+const maxFailovers = 100
+
 // TODO: Optimise and check proper concurency
 type ConfigFSM struct {
 	lastUpdateTime int
 
+	// Latest failover decisions, oldest first
+	failovers []FailoverDecision
+
+	// Nodes drained for maintenance
+	drained map[dht.NodeID]bool
+
+	// Shards of the replaced nodes along with the spares added in their place
+	replaced map[dht.NodeID]map[dht.ShardID]dht.NodeID
+
 	dht    dht.DHT
 	rStore *routestore.RouteStore
+	cStore *collectionstore.CollectionStore
 
 	// This function will be called by the config FSM when a change in configuration occurs.
 	// You can use this function to update the node connections etc.
@@ -40,11 +56,13 @@ var (
 func NewConfigFSM(
 	dht dht.DHT,
 	rStore *routestore.RouteStore,
+	cStore *collectionstore.CollectionStore,
 	log *zap.Logger,
 ) *ConfigFSM {
 	return &ConfigFSM{
 		dht:    dht,
 		rStore: rStore,
+		cStore: cStore,
 		log:    log,
 	}
 }
@@ -64,6 +82,9 @@ func (c *ConfigFSM) Apply(rlog *raft.Log) interface{} {
 		err := c.handleChange(rlog.Data)
 		if err != nil {
 			c.log.Error("error in applying log command", zap.Error(err))
+
+			// The error is returned to the node that proposed the change
+			return err
 		}
 	}
 
@@ -76,10 +97,25 @@ func (c *ConfigFSM) Snapshot() (raft.FSMSnapshot, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Get bytes of node config
-	// TODO: Add snapshot of current node configuration
+	// The stores return copies, so the state can be persisted while new logs are applied
+	state := FSMState{
+		Version:        SnapshotVersion,
+		Shards:         c.dht.Snapshot(),
+		Epoch:          c.dht.Epoch(),
+		Routes:         c.rStore.Snapshot(),
+		Collections:    c.cStore.Snapshot(),
+		LastUpdateTime: c.lastUpdateTime,
+		Failovers:      append([]FailoverDecision{}, c.failovers...),
+		Drained:        c.getDrainedNodes(),
+		Replaced:       c.getReplacements(),
+	}
+
+	by, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
 
-	return NewSnapshot(nil), nil // Add bytes here
+	return NewSnapshot(by), nil
 }
 
 // Restore is used to restore an FSM from a Snapshot. It is not called
@@ -91,17 +127,46 @@ func (c *ConfigFSM) Restore(r io.ReadCloser) error {
 		return err
 	}
 
-	var cs ConfigSnapshot
-	err = json.Unmarshal(b, &cs)
-	if err != nil {
-		return err
+	var state FSMState
+	if len(b) > 0 {
+		// Older nodes persisted empty snapshots
+		if err = json.Unmarshal(b, &state); err != nil {
+			return err
+		}
+	}
+
+	if state.Version > SnapshotVersion {
+		return ErrUnsupportedSnapshotVersion
 	}
 
-	c.handleSlotNodeChange(&cs)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rStore.Load(state.Routes)
+	c.cStore.Load(state.Collections)
+	c.lastUpdateTime = state.LastUpdateTime
+	c.failovers = state.Failovers
+	c.drained = make(map[dht.NodeID]bool, len(state.Drained))
+	for _, nodeID := range state.Drained {
+		c.drained[nodeID] = true
+	}
+	c.replaced = make(map[dht.NodeID]map[dht.ShardID]dht.NodeID, len(state.Replaced))
+	for _, r := range state.Replaced {
+		c.recordReplacement(r)
+	}
+
+	c.log.Info("Restored snapshot",
+		zap.Int("version", state.Version),
+		zap.Int("shards", len(state.Shards)),
+		zap.Int("routes", len(state.Routes)),
+		zap.Int("collections", len(state.Collections)),
+	)
+
+	c.handleSlotNodeChange(&ConfigSnapshot{Shards: state.Shards, Epoch: state.Epoch})
 	return nil
 }
 
-// handleChange calls the respective method handler when there is a change
+// handleChange calls the respective method handler when there is a change.
 func (c *ConfigFSM) handleChange(data []byte) error {
 
 	var cmd Command
@@ -121,6 +186,16 @@ func (c *ConfigFSM) handleChange(data []byte) error {
 			return err
 		}
 
+		current := c.dht.Epoch()
+		if cs.Epoch == 0 {
+			// Changes proposed by older nodes do not have an epoch
+			cs.Epoch = current + 1
+		}
+		if cs.Epoch <= current {
+			c.log.Warn("Rejected stale shard map", zap.Uint64("epoch", cs.Epoch), zap.Uint64("currentEpoch", current))
+			return ErrStaleEpoch
+		}
+
 		c.handleSlotNodeChange(&cs)
 
 	case AddRoute:
@@ -140,6 +215,58 @@ func (c *ConfigFSM) handleChange(data []byte) error {
 		}
 
 		c.rStore.RemoveRoute(route.ID)
+
+	case AddCollection:
+		var collection cm.Collection
+		err := json.Unmarshal(cmd.Data, &collection)
+		if err != nil {
+			return err
+		}
+
+		c.cStore.AddCollection(collection.Name, &collection)
+
+	case RemoveCollection:
+		var collection cm.Collection
+		err := json.Unmarshal(cmd.Data, &collection)
+		if err != nil {
+			return err
+		}
+
+		c.cStore.RemoveCollection(collection.Name)
+
+	case RecordFailover:
+		var decisions []FailoverDecision
+		err := json.Unmarshal(cmd.Data, &decisions)
+		if err != nil {
+			return err
+		}
+
+		c.recordFailovers(decisions)
+
+	case DrainNode:
+		var drain Drain
+		err := json.Unmarshal(cmd.Data, &drain)
+		if err != nil {
+			return err
+		}
+
+		if drain.Drained {
+			if c.drained == nil {
+				c.drained = make(map[dht.NodeID]bool)
+			}
+			c.drained[drain.NodeID] = true
+		} else {
+			delete(c.drained, drain.NodeID)
+		}
+
+	case RecordReplacement:
+		var r Replacement
+		err := json.Unmarshal(cmd.Data, &r)
+		if err != nil {
+			return err
+		}
+
+		c.recordReplacement(r)
 	}
 
 	return nil
@@ -152,16 +279,109 @@ func (c *ConfigFSM) GetLastUpdatedTime() int {
 	return c.lastUpdateTime
 }
 
+func (c *ConfigFSM) GetFailovers() []FailoverDecision {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]FailoverDecision{}, c.failovers...)
+}
+
+func (c *ConfigFSM) IsDrained(nodeID dht.NodeID) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.drained[nodeID]
+}
+
+// getDrainedNodes returns the drained nodes sorted by their ID
+func (c *ConfigFSM) getDrainedNodes() []dht.NodeID {
+	nodes := make([]dht.NodeID, 0, len(c.drained))
+	for nodeID := range c.drained {
+		nodes = append(nodes, nodeID)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+
+	return nodes
+}
+
+func (c *ConfigFSM) GetReplacedShards(nodeID dht.NodeID) map[dht.ShardID]dht.NodeID {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	shards := make(map[dht.ShardID]dht.NodeID, len(c.replaced[nodeID]))
+	for shardID, spare := range c.replaced[nodeID] {
+		shards[shardID] = spare
+	}
+
+	return shards
+}
+
+// recordReplacement adds the shards to the replaced shards of the node, or forgets them if there are none
+func (c *ConfigFSM) recordReplacement(r Replacement) {
+	if len(r.Shards) == 0 {
+		delete(c.replaced, r.NodeID)
+		return
+	}
+
+	if c.replaced == nil {
+		c.replaced = make(map[dht.NodeID]map[dht.ShardID]dht.NodeID)
+	}
+	if c.replaced[r.NodeID] == nil {
+		c.replaced[r.NodeID] = make(map[dht.ShardID]dht.NodeID, len(r.Shards))
+	}
+	for shardID, spare := range r.Shards {
+		c.replaced[r.NodeID][shardID] = spare
+	}
+}
+
+// getReplacements returns the replaced shards of the nodes sorted by the node ID
+func (c *ConfigFSM) getReplacements() []Replacement {
+	replacements := make([]Replacement, 0, len(c.replaced))
+	for nodeID, shards := range c.replaced {
+		replacements = append(replacements, Replacement{NodeID: nodeID, Shards: shards})
+	}
+	sort.Slice(replacements, func(i, j int) bool { return replacements[i].NodeID < replacements[j].NodeID })
+
+	return replacements
+}
+
+// recordFailovers keeps the latest maxFailovers decisions
+func (c *ConfigFSM) recordFailovers(decisions []FailoverDecision) {
+	for _, d := range decisions {
+		c.log.Info("Failover decision",
+			zap.Int("shardID", int(d.ShardID)),
+			zap.String("failedLeader", string(d.FailedLeader)),
+			zap.String("promoted", string(d.Promoted)),
+			zap.Any("offsets", d.Offsets),
+			zap.Int64("highWaterMark", d.HighWaterMark),
+			zap.String("reason", d.Reason),
+		)
+	}
+
+	c.failovers = append(c.failovers, decisions...)
+	if len(c.failovers) > maxFailovers {
+		c.failovers = append([]FailoverDecision{}, c.failovers[len(c.failovers)-maxFailovers:]...)
+	}
+}
+
 func (c *ConfigFSM) SetChangeHandler(fn func() error) {
 	c.onChangeHandler = fn
 }
 
 // Called when there is a change in node vs slot change.
-// Assume that the state of node has changed and re-init everything
+// Assume that the state of node has changed and re-init everything.
 func (c *ConfigFSM) handleSlotNodeChange(cs *ConfigSnapshot) {
 	// Re-initialise the DHT
-	c.dht.Load(cs.Shards)
+	c.dht.Load(cs.Shards, cs.Epoch)
+
+	// The handler is not yet set when raft restores the last snapshot on startup.
+	// The node is initialised separately once the node manager is created
+	if c.onChangeHandler == nil {
+		return
+	}
 
 	// Update the connections
-	c.onChangeHandler()
+	if err := c.onChangeHandler(); err != nil {
+		c.log.Error("error in handling slot vs node change", zap.Error(err))
+	}
 }
@@ -0,0 +1,170 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/aarthikrao/timeMachine/components/collectionstore"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/routestore"
+	cm "github.com/aarthikrao/timeMachine/models/collectionmodels"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	rm "github.com/aarthikrao/timeMachine/models/routemodels"
+	"github.com/hashicorp/raft"
+	"go.uber.org/zap"
+)
+
+type bufferSink struct {
+	bytes.Buffer
+}
+
+func (s *bufferSink) ID() string    { return "test" }
+func (s *bufferSink) Cancel() error { return nil }
+func (s *bufferSink) Close() error  { return nil }
+
+var _ raft.SnapshotSink = &bufferSink{}
+
+func createConfigFSM() *ConfigFSM {
+	return NewConfigFSM(dht.Create(), routestore.InitRouteStore(), collectionstore.InitCollectionStore(), zap.NewNop())
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	shards, err := dht.InitialiseDHT(4, []string{"node1", "node2", "node3"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := createConfigFSM()
+	source.dht.Load(shards, 7)
+	source.rStore.AddRoute("route1", &rm.Route{ID: "route1", Type: rm.Http, WebhookURL: "http://localhost:8080"})
+	source.cStore.AddCollection("orders", &cm.Collection{Name: "orders", WriteConcern: jm.WriteConcernQuorum})
+	source.recordFailovers([]FailoverDecision{
+		{ShardID: 1, FailedLeader: "node1", Promoted: "node2", Offsets: map[dht.NodeID]int64{"node2": 10}, HighWaterMark: 10},
+	})
+	source.drained = map[dht.NodeID]bool{"node3": true}
+	source.recordReplacement(Replacement{NodeID: "node1", Shards: map[dht.ShardID]dht.NodeID{1: "node3", 2: ""}})
+
+	snap, err := source.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sink bufferSink
+	if err = snap.Persist(&sink); err != nil {
+		t.Fatal(err)
+	}
+
+	// The state on the restoring node must be discarded
+	target := createConfigFSM()
+	target.rStore.AddRoute("stale", &rm.Route{ID: "stale"})
+	changes := 0
+	target.SetChangeHandler(func() error {
+		changes++
+		return nil
+	})
+
+	if err = target.Restore(io.NopCloser(&sink)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(target.dht.Snapshot(), source.dht.Snapshot()) {
+		t.Errorf("shards = %v, want %v", target.dht.Snapshot(), source.dht.Snapshot())
+	}
+	if target.dht.Epoch() != source.dht.Epoch() {
+		t.Errorf("shards epoch = %d, want %d", target.dht.Epoch(), source.dht.Epoch())
+	}
+	if !reflect.DeepEqual(target.rStore.Snapshot(), source.rStore.Snapshot()) {
+		t.Errorf("routes = %v, want %v", target.rStore.Snapshot(), source.rStore.Snapshot())
+	}
+	if !reflect.DeepEqual(target.cStore.Snapshot(), source.cStore.Snapshot()) {
+		t.Errorf("collections = %v, want %v", target.cStore.Snapshot(), source.cStore.Snapshot())
+	}
+	if !reflect.DeepEqual(target.GetFailovers(), source.GetFailovers()) {
+		t.Errorf("failovers = %v, want %v", target.GetFailovers(), source.GetFailovers())
+	}
+	if !target.IsDrained("node3") || target.IsDrained("node1") {
+		t.Errorf("drained nodes = %v, want [node3]", target.getDrainedNodes())
+	}
+	if got, want := target.GetReplacedShards("node1"), source.GetReplacedShards("node1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("replaced shards = %v, want %v", got, want)
+	}
+	if changes != 1 {
+		t.Errorf("change handler called %d times, want 1", changes)
+	}
+}
+
+func TestRestoreVersions(t *testing.T) {
+	legacy, _ := json.Marshal(ConfigSnapshot{
+		Shards: map[dht.ShardID]dht.ShardLocation{
+			0: {ID: 0, Leader: dht.NodeDetails{ID: "node1"}, Followers: []dht.NodeDetails{}},
+		},
+	})
+	future, _ := json.Marshal(FSMState{Version: SnapshotVersion + 1})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		shards  int
+		wantErr error
+	}{
+		{name: "empty snapshot", data: nil, shards: 0},
+		{name: "shard map without version", data: legacy, shards: 1},
+		{name: "newer version", data: future, wantErr: ErrUnsupportedSnapshotVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := createConfigFSM()
+			err := c.Restore(io.NopCloser(bytes.NewReader(tt.data)))
+			if err != tt.wantErr {
+				t.Errorf("Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(c.dht.Snapshot()); tt.wantErr == nil && got != tt.shards {
+				t.Errorf("shards = %d, want %d", got, tt.shards)
+			}
+		})
+	}
+}
+
+func TestApplyEpochs(t *testing.T) {
+	shards, err := dht.InitialiseDHT(4, []string{"node1", "node2", "node3"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		epoch     uint64
+		wantErr   error
+		wantEpoch uint64
+	}{
+		{name: "newer epoch", epoch: 6, wantEpoch: 6},
+		{name: "next epoch", epoch: 4, wantEpoch: 4},
+		{name: "same epoch", epoch: 3, wantErr: ErrStaleEpoch, wantEpoch: 3},
+		{name: "older epoch", epoch: 2, wantErr: ErrStaleEpoch, wantEpoch: 3},
+		{name: "change without epoch", epoch: 0, wantEpoch: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := createConfigFSM()
+			c.dht.Load(map[dht.ShardID]dht.ShardLocation{}, 3)
+
+			cs, _ := json.Marshal(ConfigSnapshot{Shards: shards, Epoch: tt.epoch})
+			data, _ := json.Marshal(Command{Operation: SlotVsNodeChange, Data: cs})
+
+			resp := c.Apply(&raft.Log{Type: raft.LogCommand, Data: data})
+			if err, _ := resp.(error); err != tt.wantErr {
+				t.Errorf("Apply() = %v, want %v", resp, tt.wantErr)
+			}
+			if got := c.dht.Epoch(); got != tt.wantEpoch {
+				t.Errorf("epoch = %d, want %d", got, tt.wantEpoch)
+			}
+			if applied := len(c.dht.Snapshot()) > 0; applied != (tt.wantErr == nil) {
+				t.Errorf("shard map applied = %v, want %v", applied, tt.wantErr == nil)
+			}
+		})
+	}
+}
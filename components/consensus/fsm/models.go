@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 
 	"github.com/aarthikrao/timeMachine/components/dht"
+	cm "github.com/aarthikrao/timeMachine/models/collectionmodels"
+	rm "github.com/aarthikrao/timeMachine/models/routemodels"
 )
 
 type OperationType int
@@ -21,6 +23,21 @@ const (
 
 	// Remove route information
 	RemoveRoute OperationType = 4
+
+	// Add or update the defaults of a collection
+	AddCollection OperationType = 5
+
+	// Remove the defaults of a collection
+	RemoveCollection OperationType = 6
+
+	// Data will contain the failover decisions of the shards led by a failed node
+	RecordFailover OperationType = 7
+
+	// Data will contain the node that is drained or undrained
+	DrainNode OperationType = 8
+
+	// Data will contain the shards of a failed node that was replaced, or of a node that rejoined its shards
+	RecordReplacement OperationType = 9
 )
 
 // This is a wrapper to propagate the changes to all nodes
@@ -35,4 +52,76 @@ type Command struct {
 // It is replicated across all the nodes in the cluster with Raft.
 type ConfigSnapshot struct {
 	Shards map[dht.ShardID]dht.ShardLocation `json:"slots,omitempty" bson:"slots,omitempty"`
+
+	// Epoch of the shard map. It must be greater than the epoch of the shard map it replaces,
+	// else the change is rejected as it was computed from an older shard map
+	Epoch uint64 `json:"epoch,omitempty" bson:"epoch,omitempty"`
+}
+
+// FailoverDecision records how a new leader was chosen for a shard whose leader failed.
+// The decisions are committed to the raft log so that every failover can be audited.
+type FailoverDecision struct {
+	ShardID      dht.ShardID `json:"shard_id" bson:"shard_id"`
+	FailedLeader dht.NodeID  `json:"failed_leader" bson:"failed_leader"`
+
+	// Follower promoted to the leader. It is empty if the failover was refused
+	Promoted dht.NodeID `json:"promoted,omitempty" bson:"promoted,omitempty"`
+
+	// Latest wal offsets of the healthy followers that responded
+	Offsets map[dht.NodeID]int64 `json:"offsets,omitempty" bson:"offsets,omitempty"`
+
+	// Highest wal offset of the failed leader known to the followers
+	HighWaterMark int64 `json:"high_water_mark" bson:"high_water_mark"`
+
+	Reason string `json:"reason,omitempty" bson:"reason,omitempty"`
+
+	// Time of the decision in unix milliseconds
+	TimeMS int64 `json:"time_ms,omitempty" bson:"time_ms,omitempty"`
+}
+
+// Refused returns true if no follower was promoted
+func (fd FailoverDecision) Refused() bool {
+	return fd.Promoted == ""
+}
+
+// Drain marks a node as drained for maintenance, or restores it
+type Drain struct {
+	NodeID  dht.NodeID `json:"node_id" bson:"node_id"`
+	Drained bool       `json:"drained" bson:"drained"`
+}
+
+// Replacement records the shards a failed node owned when it was replaced in the shard map, along with
+// the spares added in its place, so that the node rejoins the shards when it comes back.
+// A replacement without shards means that the node has rejoined its shards.
+type Replacement struct {
+	NodeID dht.NodeID `json:"node_id" bson:"node_id"`
+
+	// Spare added to every shard in place of the node. It is empty if no spare was available
+	Shards map[dht.ShardID]dht.NodeID `json:"shards,omitempty" bson:"shards,omitempty"`
+}
+
+// Version of the FSM snapshot format written by this node.
+// Increase it whenever the format changes in a way older nodes cannot read.
+const SnapshotVersion = 1
+
+// FSMState is the complete state of the config FSM.
+// It is persisted by raft on log compaction and restored when a node restarts or falls behind the leader.
+type FSMState struct {
+	// Format of the snapshot. Snapshots without a version only contain the shard map
+	Version int `json:"version,omitempty" bson:"version,omitempty"`
+
+	Shards         map[dht.ShardID]dht.ShardLocation `json:"slots,omitempty" bson:"slots,omitempty"`
+	Epoch          uint64                            `json:"epoch,omitempty" bson:"epoch,omitempty"`
+	Routes         map[string]*rm.Route              `json:"routes,omitempty" bson:"routes,omitempty"`
+	Collections    map[string]*cm.Collection         `json:"collections,omitempty" bson:"collections,omitempty"`
+	LastUpdateTime int                               `json:"lastUpdateTime,omitempty" bson:"lastUpdateTime,omitempty"`
+
+	// Latest failover decisions, oldest first
+	Failovers []FailoverDecision `json:"failovers,omitempty" bson:"failovers,omitempty"`
+
+	// Nodes drained for maintenance
+	Drained []dht.NodeID `json:"drained,omitempty" bson:"drained,omitempty"`
+
+	// Shards of the failed nodes that were replaced, till the nodes rejoin them
+	Replaced []Replacement `json:"replaced,omitempty" bson:"replaced,omitempty"`
 }
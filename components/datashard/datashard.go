@@ -2,19 +2,40 @@ package datashard
 
 import (
 	"fmt"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/aarthikrao/timeMachine/components/datashard/datastore"
 	"github.com/aarthikrao/timeMachine/components/datashard/wal"
 	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/components/jobstore"
 	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	timeutil "github.com/aarthikrao/timeMachine/utils/time"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
 type DataShard struct {
+	slot            dht.ShardID
+	parentDirectory string
+
 	wal   wal.WAL
-	store jobstore.JobFetcher
+	store datastore.DataStore
+
+	// mu makes sure that the writes are applied to the
+	// datastore in the same order as they are added to the wal.
+	// It is also held while the datastore and wal are replaced by a snapshot.
+	mu sync.RWMutex
+
+	// snapshotMu guards the snapshot that is streamed to the other nodes
+	snapshotMu sync.Mutex
+	snapshot   *snapshotInfo
+
+	// Highest wal offset of the leader shard replicated to this shard, including the entries
+	// that are not yet applied. It is not persisted, and is guarded by mu
+	highWaterMark int64
 
 	log *zap.Logger
 }
@@ -22,44 +43,280 @@ type DataShard struct {
 var _ jobstore.JobFetcher = (*DataShard)(nil)
 
 func InitialiseDataShard(slot dht.ShardID, parentDirectory string, log *zap.Logger) (datashard *DataShard, err error) {
+	datashard = &DataShard{
+		slot:            slot,
+		parentDirectory: parentDirectory,
+		log:             log.With(zap.Int("slot", int(slot))),
+	}
+
+	if err = datashard.open(); err != nil {
+		return nil, err
+	}
+
+	log.Info("initialised data store node",
+		zap.Int("slot", int(slot)),
+		zap.String("path", datashard.dbPath()),
+	)
+	return datashard, nil
+}
+
+// open opens the datastore and the wal, and replays the wal entries that are not applied to the datastore
+func (ds *DataShard) open() error {
 	// Initialise the datastore
-	path := fmt.Sprintf("%s/%d.db", parentDirectory, slot)
-	ds, err := datastore.CreateBoltDataStore(path)
+	store, err := datastore.CreateBoltDataStore(ds.dbPath())
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Initalise the wal and wrap it aroung the datastore
-	walPath := fmt.Sprintf("%s/%d/", parentDirectory, slot)
 	w, err := wal.InitaliseWriteAheadLog(
-		walPath,
+		ds.walPath(),
 		10e6, // 10MB per file
 		5,    // 5 files // TODOD: Move to config
-		log,
+		ds.log,
 	)
 	if err != nil {
-		return nil, err
+		store.Close()
+		return err
 	}
 
-	log.Info("initialised data store node",
-		zap.Int("slot", int(slot)),
-		zap.String("path", path),
+	ds.wal = w
+	ds.store = store
+
+	// Apply the wal entries that did not make it to the datastore before the shard was closed
+	if err = ds.replay(); err != nil {
+		return errors.Wrap(err, "wal replay")
+	}
+	ds.recordOffset(w.GetLatestOffset())
+
+	return nil
+}
+
+// addEntry adds the entry to the wal with the next offset. It must be called with mu held
+func (ds *DataShard) addEntry(le wal.LogEntry) (int64, error) {
+	start := time.Now()
+	offset, err := ds.wal.AddEntry(le)
+	if err != nil {
+		return 0, err
+	}
+
+	metrics.WALAppendDuration.Observe(time.Since(start).Seconds())
+	ds.recordOffset(offset)
+	return offset, nil
+}
+
+// appendEntry appends the entry to the wal with its own offset. It must be called with mu held
+func (ds *DataShard) appendEntry(le wal.LogEntry) error {
+	start := time.Now()
+	if err := ds.wal.AppendEntry(le); err != nil {
+		return err
+	}
+
+	metrics.WALAppendDuration.Observe(time.Since(start).Seconds())
+	ds.recordOffset(le.Offset)
+	return nil
+}
+
+func (ds *DataShard) recordOffset(offset int64) {
+	metrics.WALOffset.WithLabelValues(strconv.Itoa(int(ds.slot))).Set(float64(offset))
+}
+
+func (ds *DataShard) dbPath() string {
+	return fmt.Sprintf("%s/%d.db", ds.parentDirectory, ds.slot)
+}
+
+func (ds *DataShard) walPath() string {
+	return fmt.Sprintf("%s/%d/", ds.parentDirectory, ds.slot)
+}
+
+// replay applies all the wal entries after the last offset applied to the datastore
+func (ds *DataShard) replay() error {
+	appliedOffset, err := ds.store.GetAppliedOffset()
+	if err != nil {
+		return err
+	}
+
+	latestOffset := ds.wal.GetLatestOffset()
+	if latestOffset < appliedOffset {
+		// The wal has been removed or recreated. The new entries will
+		// start from the latest wal offset and must not be skipped during the next replay.
+		ds.log.Warn("wal is behind the datastore",
+			zap.Int64("appliedOffset", appliedOffset),
+			zap.Int64("latestOffset", latestOffset),
+		)
+		return ds.store.SetAppliedOffset(latestOffset)
+	}
+
+	var replayed int
+	err = ds.wal.Replay(appliedOffset, func(le wal.LogEntry) error {
+		replayed++
+		return ds.apply(le)
+	})
+	if err != nil {
+		return err
+	}
+
+	ds.log.Info("replayed wal",
+		zap.Int64("appliedOffset", appliedOffset),
+		zap.Int64("latestOffset", latestOffset),
+		zap.Int("replayed", replayed),
 	)
-	return &DataShard{
-		wal:   w,
-		store: ds,
-		log:   log,
-	}, nil
+	return nil
+}
+
+// apply applies the wal entry to the datastore
+func (ds *DataShard) apply(le wal.LogEntry) error {
+	switch le.Operation {
+	case wal.SetLog:
+		job, err := jm.GetJobFromBytes(le.Data)
+		if err != nil {
+			return err
+		}
+		return ds.store.ApplySetJob(le.Offset, le.Collection, job)
+
+	case wal.DeleteLog:
+		err := ds.store.ApplyDeleteJob(le.Offset, le.Collection, string(le.Data))
+		if err == datastore.ErrKeyNotFound {
+			// The job was already deleted
+			return nil
+		}
+		return err
+
+	case wal.BatchLog:
+		entries, err := le.GetBatchEntries()
+		if err != nil {
+			return err
+		}
+		writes, err := getBatchWrites(entries)
+		if err != nil {
+			return err
+		}
+		return ds.store.ApplyBatch(le.Offset, writes)
+
+	case wal.CheckpointLog:
+		// The entries till the checkpoint are already a part of the datastore
+		return ds.store.SetAppliedOffset(le.Offset)
+	}
+
+	return ErrUnknownLogOperation
 }
 
 func (ds *DataShard) GetJob(collection, jobID string) (*jm.Job, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
 	return ds.store.GetJob(collection, jobID)
 }
 
 func (ds *DataShard) SetJob(collection string, job *jm.Job) (offset int64, err error) {
+	return ds.SetJobIfVersion(collection, job, nil)
+}
+
+// SetJobIfVersion sets the job if the current version of the stored job matches ifVersion, else it returns
+// jm.ErrVersionConflict. The version of the job is incremented from the stored job. nil skips the check.
+func (ds *DataShard) SetJobIfVersion(collection string, job *jm.Job, ifVersion *int) (offset int64, err error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	return ds.setJobIfVersion(collection, job, ifVersion)
+}
+
+// SetJobOnce sets the job as in SetJobIfVersion, unless a job was set with the same idempotency key in the
+// collection after sinceMS. In that case nothing is written and the record of the earlier set is returned.
+// It returns jm.ErrIdempotencyKeyReused if the earlier set was of a different job.
+func (ds *DataShard) SetJobOnce(collection string, job *jm.Job, ifVersion *int, sinceMS int) (offset int64, record *jm.IdempotencyRecord, err error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if job.IdempotencyKey != "" {
+		record, err = ds.store.GetIdempotencyRecord(collection, job.IdempotencyKey)
+		switch {
+		case err == datastore.ErrKeyNotFound:
+		case err != nil:
+			return 0, nil, err
+		case record.CreatedMS < sinceMS:
+			// The record has expired and is yet to be removed
+		case record.JobID != job.ID:
+			return 0, nil, jm.ErrIdempotencyKeyReused
+		default:
+			return record.Offset, record, nil
+		}
+	}
+
+	offset, err = ds.setJobIfVersion(collection, job, ifVersion)
+	return offset, nil, err
+}
+
+// setJobIfVersion checks the version of the stored job and sets the job with the next version. It must be called with mu held
+func (ds *DataShard) setJobIfVersion(collection string, job *jm.Job, ifVersion *int) (offset int64, err error) {
+	version, err := ds.checkVersion(collection, job.ID, ifVersion)
+	if err != nil {
+		return 0, err
+	}
+	job.Version = version + 1
+
+	return ds.setJob(collection, job)
+}
+
+// RemoveIdempotencyRecords removes the idempotency records created before the time
+func (ds *DataShard) RemoveIdempotencyRecords(beforeMS int) (int, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return ds.store.RemoveIdempotencyRecords(beforeMS)
+}
+
+// checkVersion returns the version of the stored job, 0 if it does not exist. It returns jm.ErrVersionConflict
+// if ifVersion is set and does not match. It must be called with mu held
+func (ds *DataShard) checkVersion(collection, jobID string, ifVersion *int) (int, error) {
+	var version int
+	existing, err := ds.store.GetJob(collection, jobID)
+	switch err {
+	case nil:
+		version = existing.Version
+	case datastore.ErrKeyNotFound, datastore.ErrBucketNotFound:
+		// The job does not exist
+	default:
+		return 0, err
+	}
+
+	return version, jm.CheckVersion(version, ifVersion)
+}
+
+// SetExecutionState moves the stored job to the execution state of the update and returns the updated job.
+// The trigger time is compared with the stored job, so that a job updated after it was
+// queued for execution is not overwritten. It returns ErrInvalidExecutionTransition if
+// the job cannot move to the state, for example if it is already delivered.
+func (ds *DataShard) SetExecutionState(collection, jobID string, triggerMS int, update jm.ExecutionUpdate) (offset int64, job *jm.Job, err error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	job, err = ds.store.GetJob(collection, jobID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if job.TriggerMS != triggerMS {
+		return 0, nil, jm.ErrJobChanged
+	}
+
+	if err = job.ApplyExecution(update, timeutil.GetCurrentMillis()); err != nil {
+		return 0, nil, err
+	}
+
+	offset, err = ds.setJob(collection, job)
+	if err != nil {
+		return offset, nil, err
+	}
+
+	return offset, job, nil
+}
+
+// setJob adds the job to the wal and applies it as is, without changing its version. It must be called with mu held
+func (ds *DataShard) setJob(collection string, job *jm.Job) (offset int64, err error) {
 	by, err := job.ToBytes()
 	if err != nil {
-		errors.Wrap(err, "wal set job")
+		return 0, errors.Wrap(err, "wal set job")
 	}
 
 	le := wal.LogEntry{
@@ -68,12 +325,12 @@ func (ds *DataShard) SetJob(collection string, job *jm.Job) (offset int64, err e
 		Data:       by,
 	}
 
-	offset, err = ds.wal.AddEntry(le)
+	offset, err = ds.addEntry(le)
 	if err != nil {
 		return 0, err
 	}
 
-	_, err = ds.store.SetJob(collection, job)
+	err = ds.store.ApplySetJob(offset, collection, job)
 	if err != nil {
 		return offset, err
 	}
@@ -82,18 +339,33 @@ func (ds *DataShard) SetJob(collection string, job *jm.Job) (offset int64, err e
 }
 
 func (ds *DataShard) DeleteJob(collection, jobID string) (offset int64, err error) {
+	return ds.DeleteJobIfVersion(collection, jobID, nil)
+}
+
+// DeleteJobIfVersion deletes the job if the current version of the stored job matches ifVersion,
+// else it returns jm.ErrVersionConflict. nil skips the check.
+func (ds *DataShard) DeleteJobIfVersion(collection, jobID string, ifVersion *int) (offset int64, err error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ifVersion != nil {
+		if _, err = ds.checkVersion(collection, jobID, ifVersion); err != nil {
+			return 0, err
+		}
+	}
+
 	le := wal.LogEntry{
 		Operation:  wal.DeleteLog,
 		Collection: collection,
 		Data:       []byte(jobID),
 	}
 
-	offset, err = ds.wal.AddEntry(le)
+	offset, err = ds.addEntry(le)
 	if err != nil {
 		return 0, err
 	}
 
-	_, err = ds.store.DeleteJob(collection, jobID)
+	err = ds.store.ApplyDeleteJob(offset, collection, jobID)
 	if err != nil {
 		return offset, err
 	}
@@ -101,11 +373,238 @@ func (ds *DataShard) DeleteJob(collection, jobID string) (offset int64, err erro
 	return offset, nil
 }
 
+// SetJobs adds all the jobs to the wal as a single batch entry and applies them in a single transaction.
+// Either all the jobs are set or none of them are. It returns the batch entry along with its offset,
+// so that it can be replicated to the followers. The versions of the jobs are incremented as in SetJob.
+func (ds *DataShard) SetJobs(collection string, jobs []*jm.Job) (wal.LogEntry, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	// The same job may be set more than once in the batch
+	versions := make(map[string]int)
+	entries := make([]wal.LogEntry, 0, len(jobs))
+	for _, job := range jobs {
+		version, ok := versions[job.ID]
+		if !ok {
+			var err error
+			if version, err = ds.checkVersion(collection, job.ID, nil); err != nil {
+				return wal.LogEntry{}, err
+			}
+		}
+		job.Version = version + 1
+		versions[job.ID] = job.Version
+
+		by, err := job.ToBytes()
+		if err != nil {
+			return wal.LogEntry{}, errors.Wrap(err, "wal set jobs")
+		}
+
+		entries = append(entries, wal.LogEntry{
+			Operation:  wal.SetLog,
+			Collection: collection,
+			Data:       by,
+		})
+	}
+
+	return ds.addBatch(entries)
+}
+
+// DeleteJobs deletes all the jobs that exist in the collection as a single batch entry. It returns the batch
+// entry, and the error of every job that does not exist in errs. If none of the jobs exist, nothing is added
+// to the wal and the entry is nil.
+func (ds *DataShard) DeleteJobs(collection string, jobIDs []string) (entry *wal.LogEntry, errs []error, err error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	errs = make([]error, len(jobIDs))
+	entries := make([]wal.LogEntry, 0, len(jobIDs))
+	for i, jobID := range jobIDs {
+		if _, err := ds.store.GetJob(collection, jobID); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		entries = append(entries, wal.LogEntry{
+			Operation:  wal.DeleteLog,
+			Collection: collection,
+			Data:       []byte(jobID),
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil, errs, nil
+	}
+
+	le, err := ds.addBatch(entries)
+	if err != nil {
+		return nil, errs, err
+	}
+
+	return &le, errs, nil
+}
+
+// addBatch adds the entries to the wal as a single batch entry and applies it.
+// It returns the batch entry with its offset. It must be called with mu held
+func (ds *DataShard) addBatch(entries []wal.LogEntry) (wal.LogEntry, error) {
+	writes, err := getBatchWrites(entries)
+	if err != nil {
+		return wal.LogEntry{}, err
+	}
+
+	le, err := wal.NewBatchEntry(entries)
+	if err != nil {
+		return wal.LogEntry{}, errors.Wrap(err, "wal batch")
+	}
+
+	if le.Offset, err = ds.addEntry(le); err != nil {
+		return wal.LogEntry{}, err
+	}
+
+	if err = ds.store.ApplyBatch(le.Offset, writes); err != nil {
+		return le, err
+	}
+
+	return le, nil
+}
+
+// getBatchWrites converts the set and delete entries of a batch to the datastore writes
+func getBatchWrites(entries []wal.LogEntry) ([]datastore.BatchWrite, error) {
+	writes := make([]datastore.BatchWrite, 0, len(entries))
+	for _, le := range entries {
+		switch le.Operation {
+		case wal.SetLog:
+			job, err := jm.GetJobFromBytes(le.Data)
+			if err != nil {
+				return nil, err
+			}
+			writes = append(writes, datastore.BatchWrite{Collection: le.Collection, Job: job})
+
+		case wal.DeleteLog:
+			writes = append(writes, datastore.BatchWrite{Collection: le.Collection, JobID: string(le.Data)})
+
+		default:
+			return nil, ErrUnknownLogOperation
+		}
+	}
+
+	return writes, nil
+}
+
+// Replicate appends the entry replicated from the leader shard to the wal and applies it.
+// Entries that are already present on this shard are ignored. It returns ErrReplicationGap
+// if the entries before this entry are missing.
+func (ds *DataShard) Replicate(le wal.LogEntry) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	latestOffset := ds.wal.GetLatestOffset()
+	if le.Offset <= latestOffset {
+		// Already replicated
+		return nil
+	}
+
+	if le.Offset > latestOffset+1 {
+		return ErrReplicationGap
+	}
+
+	if err := ds.appendEntry(le); err != nil {
+		return err
+	}
+
+	return ds.apply(le)
+}
+
+// RecordHighWaterMark records the offset of an entry replicated by the leader shard
+func (ds *DataShard) RecordHighWaterMark(offset int64) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if offset > ds.highWaterMark {
+		ds.highWaterMark = offset
+	}
+}
+
+// GetHighWaterMark returns the highest wal offset of the leader shard known to this shard.
+// It is at least the latest offset of this shard.
+func (ds *DataShard) GetHighWaterMark() int64 {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	if latest := ds.wal.GetLatestOffset(); latest > ds.highWaterMark {
+		return latest
+	}
+	return ds.highWaterMark
+}
+
+// GetLatestOffset returns the offset of the latest wal entry of this shard
+func (ds *DataShard) GetLatestOffset() int64 {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return ds.wal.GetLatestOffset()
+}
+
+// StreamLogEntries calls f on all the wal entries after the offset.
+// It returns ErrOffsetNotAvailable if the entries right after the offset have been removed from the wal,
+// or if they are a part of the snapshot the wal was recreated from.
+func (ds *DataShard) StreamLogEntries(offset int64, f func(wal.LogEntry) error) error {
+	// The lock is not held while streaming so that the writes are not blocked
+	ds.mu.RLock()
+	w := ds.wal
+	ds.mu.RUnlock()
+
+	first := true
+	return w.Replay(offset, func(le wal.LogEntry) error {
+		if first && (le.Offset > offset+1 || le.Operation == wal.CheckpointLog) {
+			return ErrOffsetNotAvailable
+		}
+		first = false
+
+		return f(le)
+	})
+}
+
 func (ds *DataShard) FetchJobForBucket(minute int) ([]*jm.Job, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
 	return ds.store.FetchJobForBucket(minute)
 }
 
+// FetchJobsForBuckets returns the scheduled jobs in the minute buckets between from and to, both inclusive
+func (ds *DataShard) FetchJobsForBuckets(from, to int) ([]*jm.Job, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return ds.store.FetchJobsForBuckets(from, to)
+}
+
+// FetchDeadLetterJobs returns the dead lettered jobs of the collection in this shard
+func (ds *DataShard) FetchDeadLetterJobs(collection string) ([]*jm.Job, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return ds.store.FetchDeadLetterJobs(collection)
+}
+
+// ListJobs returns the jobs of the collection in this shard that match the query
+func (ds *DataShard) ListJobs(collection string, query jm.JobQuery) ([]*jm.Job, error) {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	return ds.store.ListJobs(collection, query)
+}
+
 func (ds *DataShard) Close() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	// The offset of a shard that is no longer owned by this node is not reported
+	metrics.WALOffset.DeleteLabelValues(strconv.Itoa(int(ds.slot)))
+	return ds.close()
+}
+
+func (ds *DataShard) close() error {
 	if err := ds.wal.Close(); err != nil {
 		return err
 	}
@@ -0,0 +1,517 @@
+package datashard
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	"go.uber.org/zap"
+)
+
+func TestReplayOnInitialise(t *testing.T) {
+	dir := t.TempDir()
+	log := zap.NewNop()
+
+	ds, err := InitialiseDataShard(1, dir, log)
+	if err != nil {
+		t.Fatalf("Failed to initialise data shard: %v", err)
+	}
+
+	applied := &jm.Job{
+		ID:        "job1",
+		TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()),
+		Route:     "route1",
+	}
+	if _, err = ds.SetJob("collection1", applied); err != nil {
+		t.Fatalf("Failed to set job: %v", err)
+	}
+
+	// Simulate a crash after the entry was written to the wal but before it was applied to the datastore
+	notApplied := &jm.Job{
+		ID:        "job2",
+		TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()),
+		Route:     "route1",
+	}
+	by, err := notApplied.ToBytes()
+	if err != nil {
+		t.Fatalf("Failed to convert job to bytes: %v", err)
+	}
+	for _, le := range []wal.LogEntry{
+		{Operation: wal.SetLog, Collection: "collection1", Data: by},
+		{Operation: wal.DeleteLog, Collection: "collection1", Data: []byte(applied.ID)},
+	} {
+		if _, err = ds.wal.AddEntry(le); err != nil {
+			t.Fatalf("Failed to add wal entry: %v", err)
+		}
+	}
+
+	if err = ds.Close(); err != nil {
+		t.Fatalf("Failed to close data shard: %v", err)
+	}
+
+	ds, err = InitialiseDataShard(1, dir, log)
+	if err != nil {
+		t.Fatalf("Failed to reopen data shard: %v", err)
+	}
+	defer ds.Close()
+
+	if _, err = ds.GetJob("collection1", notApplied.ID); err != nil {
+		t.Errorf("Expected %s to be replayed, got error: %v", notApplied.ID, err)
+	}
+
+	if _, err = ds.GetJob("collection1", applied.ID); err == nil {
+		t.Errorf("Expected %s to be deleted during replay", applied.ID)
+	}
+
+	appliedOffset, err := ds.store.GetAppliedOffset()
+	if err != nil {
+		t.Fatalf("Failed to get applied offset: %v", err)
+	}
+	if latest := ds.wal.GetLatestOffset(); appliedOffset != latest {
+		t.Errorf("Unexpected applied offset: got %d, want %d", appliedOffset, latest)
+	}
+}
+
+func TestReplicateFromLeader(t *testing.T) {
+	log := zap.NewNop()
+
+	leader, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise leader shard: %v", err)
+	}
+	defer leader.Close()
+
+	follower, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise follower shard: %v", err)
+	}
+	defer follower.Close()
+
+	for _, id := range []string{"job1", "job2", "job3"} {
+		j := &jm.Job{
+			ID:        id,
+			TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()),
+			Route:     "route1",
+		}
+		if _, err = leader.SetJob("collection1", j); err != nil {
+			t.Fatalf("Failed to set job: %v", err)
+		}
+	}
+
+	// The follower has missed the first two entries
+	var entries []wal.LogEntry
+	if err = leader.StreamLogEntries(-1, func(le wal.LogEntry) error {
+		entries = append(entries, le)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to stream log entries: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Unexpected number of entries: got %d, want %d", len(entries), 3)
+	}
+
+	follower.RecordHighWaterMark(entries[2].Offset)
+	if err = follower.Replicate(entries[2]); err != ErrReplicationGap {
+		t.Errorf("Expected %v, got %v", ErrReplicationGap, err)
+	}
+	if got, want := follower.GetHighWaterMark(), entries[2].Offset; got != want {
+		t.Errorf("Unexpected high water mark: got %d, want %d", got, want)
+	}
+
+	// Catch up with the leader. The duplicate entry must be ignored
+	if err = leader.StreamLogEntries(follower.GetLatestOffset(), follower.Replicate); err != nil {
+		t.Fatalf("Failed to catch up: %v", err)
+	}
+	if err = follower.Replicate(entries[1]); err != nil {
+		t.Errorf("Expected duplicate entry to be ignored, got %v", err)
+	}
+
+	if got, want := follower.GetLatestOffset(), leader.GetLatestOffset(); got != want {
+		t.Errorf("Unexpected follower offset: got %d, want %d", got, want)
+	}
+
+	for _, id := range []string{"job1", "job2", "job3"} {
+		if _, err = follower.GetJob("collection1", id); err != nil {
+			t.Errorf("Expected %s to be replicated, got error: %v", id, err)
+		}
+	}
+}
+
+func TestSnapshotTransfer(t *testing.T) {
+	log := zap.NewNop()
+
+	// Stream the snapshot in small chunks so that the transfer can be interrupted
+	defer func(size int) { snapshotChunkSize = size }(snapshotChunkSize)
+	snapshotChunkSize = 4096
+
+	leader, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise leader shard: %v", err)
+	}
+	defer leader.Close()
+
+	follower, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise follower shard: %v", err)
+	}
+	defer follower.Close()
+
+	setJobs := func(ids ...string) {
+		for _, id := range ids {
+			j := &jm.Job{
+				ID:        id,
+				TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()),
+				Route:     "route1",
+			}
+			if _, err := leader.SetJob("collection1", j); err != nil {
+				t.Fatalf("Failed to set job: %v", err)
+			}
+		}
+	}
+	setJobs("job1", "job2")
+
+	// Interrupt the transfer after the first chunk
+	errInterrupted := errors.New("interrupted")
+	err = leader.StreamSnapshot(-1, 0, func(chunk jobstore.SnapshotChunk) error {
+		if chunk.ByteOffset > 0 {
+			return errInterrupted
+		}
+		return follower.WriteSnapshotChunk(chunk)
+	})
+	if err != errInterrupted {
+		t.Fatalf("Expected %v, got %v", errInterrupted, err)
+	}
+
+	// Writes after the snapshot are streamed from the wal
+	setJobs("job3")
+
+	snapshotOffset, byteOffset := follower.GetTransferState()
+	if byteOffset != int64(snapshotChunkSize) {
+		t.Fatalf("Unexpected bytes received: got %d, want %d", byteOffset, snapshotChunkSize)
+	}
+
+	// Resume the transfer
+	err = leader.StreamSnapshot(snapshotOffset, byteOffset, func(chunk jobstore.SnapshotChunk) error {
+		if chunk.ByteOffset < byteOffset {
+			t.Errorf("Expected the transfer to resume from %d, got chunk at %d", byteOffset, chunk.ByteOffset)
+		}
+		return follower.WriteSnapshotChunk(chunk)
+	})
+	if err != nil {
+		t.Fatalf("Failed to resume snapshot transfer: %v", err)
+	}
+
+	installed, err := follower.InstallTransfer()
+	if err != nil {
+		t.Fatalf("Failed to install snapshot: %v", err)
+	}
+	if installed != snapshotOffset {
+		t.Errorf("Unexpected snapshot offset: got %d, want %d", installed, snapshotOffset)
+	}
+
+	// The entries before the snapshot are not available in the wal of the follower
+	if err = follower.StreamLogEntries(-1, func(wal.LogEntry) error { return nil }); err != ErrOffsetNotAvailable {
+		t.Errorf("Expected %v, got %v", ErrOffsetNotAvailable, err)
+	}
+
+	if err = leader.StreamLogEntries(follower.GetLatestOffset(), follower.Replicate); err != nil {
+		t.Fatalf("Failed to stream the wal tail: %v", err)
+	}
+
+	if got, want := follower.GetLatestOffset(), leader.GetLatestOffset(); got != want {
+		t.Errorf("Unexpected follower offset: got %d, want %d", got, want)
+	}
+
+	for _, id := range []string{"job1", "job2", "job3"} {
+		if _, err = follower.GetJob("collection1", id); err != nil {
+			t.Errorf("Expected %s to be transferred, got error: %v", id, err)
+		}
+	}
+}
+
+func TestSnapshotChecksumMismatch(t *testing.T) {
+	log := zap.NewNop()
+
+	leader, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise leader shard: %v", err)
+	}
+	defer leader.Close()
+
+	follower, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise follower shard: %v", err)
+	}
+	defer follower.Close()
+
+	err = leader.StreamSnapshot(-1, 0, func(chunk jobstore.SnapshotChunk) error {
+		chunk.Checksum = "corrupted"
+		return follower.WriteSnapshotChunk(chunk)
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream snapshot: %v", err)
+	}
+
+	if _, err = follower.InstallTransfer(); err != ErrSnapshotChecksumMismatch {
+		t.Errorf("Expected %v, got %v", ErrSnapshotChecksumMismatch, err)
+	}
+
+	if _, byteOffset := follower.GetTransferState(); byteOffset != 0 {
+		t.Errorf("Expected the corrupted transfer to be removed, got %d bytes", byteOffset)
+	}
+}
+
+func TestSetExecutionState(t *testing.T) {
+	ds, err := InitialiseDataShard(1, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to initialise data shard: %v", err)
+	}
+	defer ds.Close()
+
+	job := &jm.Job{
+		ID:        "job1",
+		TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()),
+		Route:     "route1",
+	}
+	if _, err = ds.SetJob("collection1", job); err != nil {
+		t.Fatalf("Failed to set job: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		triggerMS int
+		state     jm.ExecutionState
+		claim     string
+		wantErr   error
+	}{
+		{name: "updated job", triggerMS: job.TriggerMS + 1, state: jm.ExecutionDispatched, claim: "node1/1/1", wantErr: jm.ErrJobChanged},
+		{name: "deliver before dispatch", triggerMS: job.TriggerMS, state: jm.ExecutionDelivered, wantErr: jm.ErrInvalidExecutionTransition},
+		{name: "dispatch", triggerMS: job.TriggerMS, state: jm.ExecutionDispatched, claim: "node1/1/1"},
+		{name: "dispatch again with the same claim", triggerMS: job.TriggerMS, state: jm.ExecutionDispatched, claim: "node1/1/1", wantErr: jm.ErrAlreadyDispatched},
+		{name: "dispatch by a new leader", triggerMS: job.TriggerMS, state: jm.ExecutionDispatched, claim: "node2/2/1"},
+		{name: "deliver", triggerMS: job.TriggerMS, state: jm.ExecutionDelivered},
+		{name: "dispatch after delivery", triggerMS: job.TriggerMS, state: jm.ExecutionDispatched, wantErr: jm.ErrInvalidExecutionTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := ds.GetLatestOffset()
+			_, _, err := ds.SetExecutionState("collection1", job.ID, tt.triggerMS, jm.ExecutionUpdate{State: tt.state, ClaimedBy: tt.claim})
+			if err != tt.wantErr {
+				t.Fatalf("SetExecutionState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if latest := ds.GetLatestOffset(); latest != before {
+					t.Errorf("Unexpected wal entry on error: got offset %d, want %d", latest, before)
+				}
+				return
+			}
+
+			stored, err := ds.GetJob("collection1", job.ID)
+			if err != nil {
+				t.Fatalf("Failed to get job: %v", err)
+			}
+			if stored.State != tt.state {
+				t.Errorf("Unexpected state: got %s, want %s", stored.State, tt.state)
+			}
+			if stored.DispatchedMS == 0 {
+				t.Errorf("Expected the dispatch time to be set")
+			}
+		})
+	}
+}
+
+func TestBatchWrites(t *testing.T) {
+	log := zap.NewNop()
+
+	leader, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise leader shard: %v", err)
+	}
+	defer leader.Close()
+
+	follower, err := InitialiseDataShard(1, t.TempDir(), log)
+	if err != nil {
+		t.Fatalf("Failed to initialise follower shard: %v", err)
+	}
+	defer follower.Close()
+
+	triggerMS := int(time.Now().Add(time.Hour).UnixMilli())
+	jobs := []*jm.Job{
+		{ID: "job1", TriggerMS: triggerMS, Route: "route1"},
+		{ID: "job2", TriggerMS: triggerMS, Route: "route1"},
+		{ID: "job3", TriggerMS: triggerMS, Route: "route1"},
+	}
+
+	set, err := leader.SetJobs("collection1", jobs)
+	if err != nil {
+		t.Fatalf("Failed to set jobs: %v", err)
+	}
+	if set.Offset != 0 || leader.GetLatestOffset() != 0 {
+		t.Errorf("Expected the batch to be a single wal entry, got offset %d", set.Offset)
+	}
+
+	deleted, errs, err := leader.DeleteJobs("collection1", []string{"job2", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to delete jobs: %v", err)
+	}
+	if errs[0] != nil || errs[1] == nil {
+		t.Errorf("Unexpected delete errors: %v", errs)
+	}
+
+	if _, errs, _ = leader.DeleteJobs("collection1", []string{"missing"}); errs[0] == nil {
+		t.Errorf("Expected an error for the missing job")
+	}
+
+	// The batch entries are replicated like any other entry
+	for _, le := range []wal.LogEntry{set, *deleted} {
+		if err = follower.Replicate(le); err != nil {
+			t.Fatalf("Failed to replicate batch: %v", err)
+		}
+	}
+
+	for _, shard := range []*DataShard{leader, follower} {
+		for _, id := range []string{"job1", "job3"} {
+			if _, err = shard.GetJob("collection1", id); err != nil {
+				t.Errorf("Expected %s to be set, got error: %v", id, err)
+			}
+		}
+		if _, err = shard.GetJob("collection1", "job2"); err == nil {
+			t.Errorf("Expected job2 to be deleted")
+		}
+		if offset := shard.GetLatestOffset(); offset != 1 {
+			t.Errorf("Unexpected latest offset: got %d, want 1", offset)
+		}
+	}
+}
+
+func TestJobVersions(t *testing.T) {
+	ds, err := InitialiseDataShard(1, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to initialise data shard: %v", err)
+	}
+	defer ds.Close()
+
+	version := func(v int) *int { return &v }
+	tests := []struct {
+		name        string
+		delete      bool
+		ifVersion   *int
+		wantErr     error
+		wantVersion int
+	}{
+		{name: "create if missing", ifVersion: version(0), wantVersion: 1},
+		{name: "create if missing again", ifVersion: version(0), wantErr: jm.ErrVersionConflict},
+		{name: "set without precondition", wantVersion: 2},
+		{name: "set stale version", ifVersion: version(1), wantErr: jm.ErrVersionConflict},
+		{name: "set current version", ifVersion: version(2), wantVersion: 3},
+		{name: "delete stale version", delete: true, ifVersion: version(2), wantErr: jm.ErrVersionConflict},
+		{name: "delete current version", delete: true, ifVersion: version(3)},
+		{name: "create after delete", ifVersion: version(0), wantVersion: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.delete {
+				if _, err := ds.DeleteJobIfVersion("collection1", "job1", tt.ifVersion); err != tt.wantErr {
+					t.Errorf("DeleteJobIfVersion() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			job := &jm.Job{ID: "job1", TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()), Route: "route1"}
+			if _, err := ds.SetJobIfVersion("collection1", job, tt.ifVersion); err != tt.wantErr {
+				t.Errorf("SetJobIfVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			stored, err := ds.GetJob("collection1", "job1")
+			if err != nil {
+				t.Fatalf("Failed to get job: %v", err)
+			}
+			if stored.Version != tt.wantVersion || job.Version != tt.wantVersion {
+				t.Errorf("version = %d, stored %d, want %d", job.Version, stored.Version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestSetJobOnce(t *testing.T) {
+	leader, err := InitialiseDataShard(1, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to initialise leader shard: %v", err)
+	}
+	defer leader.Close()
+
+	follower, err := InitialiseDataShard(1, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("Failed to initialise follower shard: %v", err)
+	}
+	defer follower.Close()
+
+	firstOffset := leader.GetLatestOffset() + 1
+	tests := []struct {
+		name         string
+		jobID        string
+		sinceMS      int
+		wantErr      error
+		wantReplayed bool
+		wantVersion  int
+	}{
+		{name: "first set", jobID: "job1", wantVersion: 1},
+		{name: "retry", jobID: "job1", wantReplayed: true, wantVersion: 1},
+		{name: "key of another job", jobID: "job2", wantErr: jm.ErrIdempotencyKeyReused},
+		{name: "retry after the window", jobID: "job1", sinceMS: int(time.Now().Add(time.Hour).UnixMilli()), wantVersion: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &jm.Job{
+				ID:             tt.jobID,
+				TriggerMS:      int(time.Now().Add(time.Hour).UnixMilli()),
+				Route:          "route1",
+				IdempotencyKey: "key1",
+			}
+			before := leader.GetLatestOffset()
+
+			offset, record, err := leader.SetJobOnce("collection1", job, nil, tt.sinceMS)
+			if err != tt.wantErr {
+				t.Fatalf("SetJobOnce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if replayed := record != nil; replayed != tt.wantReplayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplayed)
+			}
+			if tt.wantReplayed && (offset != firstOffset || record.Version != tt.wantVersion || leader.GetLatestOffset() != before) {
+				t.Errorf("Expected the first set at offset %d to be returned without writing, got offset %d, record %+v", firstOffset, offset, record)
+			}
+			if !tt.wantReplayed && job.Version != tt.wantVersion {
+				t.Errorf("version = %d, want %d", job.Version, tt.wantVersion)
+			}
+		})
+	}
+
+	// The idempotency records are replicated along with the jobs, so that they survive a failover
+	if err = leader.StreamLogEntries(follower.GetLatestOffset(), follower.Replicate); err != nil {
+		t.Fatalf("Failed to replicate: %v", err)
+	}
+	job := &jm.Job{ID: "job1", TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()), Route: "route1", IdempotencyKey: "key1"}
+	_, record, err := follower.SetJobOnce("collection1", job, nil, 0)
+	if err != nil || record == nil || record.Version != 2 {
+		t.Errorf("Expected the follower to replay the set of version 2, got record %+v, error %v", record, err)
+	}
+
+	if removed, err := follower.RemoveIdempotencyRecords(int(time.Now().Add(time.Minute).UnixMilli())); err != nil || removed != 1 {
+		t.Errorf("RemoveIdempotencyRecords() = %d, %v, want 1 record removed", removed, err)
+	}
+}
@@ -2,24 +2,98 @@ package datastore
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"log"
+	"sort"
 	"strconv"
+	"time"
 
 	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	timeutil "github.com/aarthikrao/timeMachine/utils/time"
 	bolt "go.etcd.io/bbolt"
 
 	"github.com/aarthikrao/timeMachine/components/jobstore"
 )
 
 // Compile time validation for jobstore interface
-var _ jobstore.JobFetcher = (*boltDataStore)(nil)
+var _ DataStore = (*boltDataStore)(nil)
 
 // scheduleCollection will contain all the schedules and will be used to fetch the minute wise jobs
 var scheduleCollection []byte = []byte("scheduleCollection")
 
+// deadLetterCollection contains the keys of the dead lettered jobs of all the collections
+var deadLetterCollection []byte = []byte("deadLetterCollection")
+
+// idempotencyCollection contains the outcome of the first set of the jobs with an idempotency key
+var idempotencyCollection []byte = []byte("idempotencyCollection")
+
+// metaCollection contains the internal state of the datastore like the last applied wal offset
+var metaCollection []byte = []byte("metaCollection")
+
+var appliedOffsetKey []byte = []byte("appliedOffset")
+
+// DataStore is a JobFetcher that stores the jobs on disk.
+// It keeps track of the last wal offset applied to it so that
+// the wal entries can be replayed after a crash.
+type DataStore interface {
+	jobstore.JobFetcher
+
+	// ApplySetJob sets the job and records the wal offset in the same transaction
+	ApplySetJob(offset int64, collection string, job *jm.Job) error
+
+	// ApplyDeleteJob deletes the job and records the wal offset in the same transaction
+	ApplyDeleteJob(offset int64, collection, jobID string) error
+
+	// ApplyBatch applies all the writes and records the wal offset in the same transaction
+	ApplyBatch(offset int64, writes []BatchWrite) error
+
+	// GetAppliedOffset returns the last wal offset applied to the datastore.
+	// It returns -1 if no offset has been applied yet.
+	GetAppliedOffset() (int64, error)
+
+	// SetAppliedOffset overwrites the last applied wal offset
+	SetAppliedOffset(offset int64) error
+
+	// FetchDeadLetterJobs returns the dead lettered jobs of the collection
+	FetchDeadLetterJobs(collection string) ([]*jm.Job, error)
+
+	// FetchJobsForBuckets returns the jobs in all the minute buckets between from and to, both inclusive
+	FetchJobsForBuckets(from, to int) ([]*jm.Job, error)
+
+	// ListJobs returns up to query.Limit jobs of the collection that match the query, in the order of the query
+	ListJobs(collection string, query jm.JobQuery) ([]*jm.Job, error)
+
+	// GetIdempotencyRecord returns the outcome of the first set of a job with the idempotency key in the collection.
+	// It returns ErrKeyNotFound if no job was set with the key.
+	GetIdempotencyRecord(collection, key string) (*jm.IdempotencyRecord, error)
+
+	// RemoveIdempotencyRecords removes the records created before the time and returns the number of records removed
+	RemoveIdempotencyRecords(beforeMS int) (int, error)
+
+	// Snapshot writes a consistent copy of the datastore to w.
+	// It returns the last wal offset applied in the copy.
+	Snapshot(w io.Writer) (appliedOffset int64, err error)
+}
+
+// BatchWrite is a set or a delete of a job that is applied as a part of a batch
+type BatchWrite struct {
+	Collection string
+
+	// Job is set for a set, and JobID for a delete
+	Job   *jm.Job
+	JobID string
+}
+
 // It uses BoltDB which uses B+tree implementation.
 // The data is stored in the below format
 //   ∟ routeCollection (contains routes for this DB)
+//   ∟ metaCollection (contains the last applied wal offset)
+//   ∟ deadLetterCollection (contains the dead lettered jobs)
+//       ∟ uniqueJobID : timestamp
+//   ∟ idempotencyCollection (contains the outcome of the first set of the jobs with an idempotency key)
+//       ∟ length of collection:collection + idempotencyKey : record
 //   ∟ scheduleCollection (contains minute wise buckets for all the collections)
 //       ∟ minutewise buckets
 //          ∟ timestamp : uniqueJobID
@@ -33,10 +107,13 @@ type boltDataStore struct {
 	dbFilePath string
 }
 
-func CreateBoltDataStore(path string) (jobstore.JobFetcher, error) {
-	db, err := bolt.Open(path, 0666, &bolt.Options{
-		NoSync: true,
-	})
+// noOffset is used when the write is not a part of the wal
+const noOffset int64 = -1
+
+func CreateBoltDataStore(path string) (DataStore, error) {
+	// Every commit is synced to the disk. The writes are acknowledged only
+	// after they are committed, hence they should survive a crash.
+	db, err := bolt.Open(path, 0666, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -78,15 +155,15 @@ func (bds *boltDataStore) GetJob(collection, jobID string) (*jm.Job, error) {
 // (offset int64, err error)
 func (bds *boltDataStore) SetJob(collection string, job *jm.Job) (offset int64, err error) {
 	// To satisfy interface check. We are not maintaining any offset at boltdb
-	return 0, bds.setJob(collection, job)
+	return 0, bds.setJob(collection, job, noOffset)
 }
 
-func (bds *boltDataStore) setJob(collection string, job *jm.Job) error {
-	by, err := job.ToBytes()
-	if err != nil {
-		return err
-	}
+// ApplySetJob sets the job and records the wal offset in the same transaction
+func (bds *boltDataStore) ApplySetJob(offset int64, collection string, job *jm.Job) error {
+	return bds.setJob(collection, job, offset)
+}
 
+func (bds *boltDataStore) setJob(collection string, job *jm.Job, offset int64) error {
 	// Start the transaction.
 	tx, err := bds.db.Begin(true)
 	if err != nil {
@@ -94,6 +171,31 @@ func (bds *boltDataStore) setJob(collection string, job *jm.Job) error {
 	}
 	defer tx.Rollback()
 
+	if err = putJob(tx, collection, job); err != nil {
+		return err
+	}
+
+	if err = putIdempotencyRecord(tx, collection, job, offset); err != nil {
+		return err
+	}
+
+	if offset != noOffset {
+		if err = putAppliedOffset(tx, offset); err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction and check for error.
+	return commit(tx)
+}
+
+// putJob adds the job to its collection bucket and the schedule and dead letter buckets in the transaction
+func putJob(tx *bolt.Tx, collection string, job *jm.Job) error {
+	by, err := job.ToBytes()
+	if err != nil {
+		return err
+	}
+
 	// Add the job in collection bucket
 	{
 		// Fetch the collection bucket
@@ -102,6 +204,14 @@ func (bds *boltDataStore) setJob(collection string, job *jm.Job) error {
 			return err
 		}
 
+		// Remove the schedule of the job that is being overwritten.
+		// Otherwise the job will also be fetched in its previous minute bucket
+		if existing := bkt.Get([]byte(job.ID)); existing != nil {
+			if err = removeSchedule(tx, collection, existing); err != nil {
+				return err
+			}
+		}
+
 		// Insert the job in collection bucket
 		err = bkt.Put([]byte(job.ID), by)
 		if err != nil {
@@ -109,8 +219,9 @@ func (bds *boltDataStore) setJob(collection string, job *jm.Job) error {
 		}
 	}
 
-	// Add the job in schedule bucket
-	{
+	// Add the job in schedule bucket. The jobs that are delivered, skipped or dead lettered
+	// are no longer scheduled, so that they are not fetched again with their minute bucket
+	if job.State.IsPending() {
 		// Fetch the schedule collection bucket
 		scheduleBkt, err := tx.CreateBucketIfNotExists(
 			scheduleCollection)
@@ -134,16 +245,37 @@ func (bds *boltDataStore) setJob(collection string, job *jm.Job) error {
 		}
 	}
 
-	// Commit the transaction and check for error.
-	return tx.Commit()
+	// Add the job in dead letter bucket, or remove it if the job is set again
+	{
+		deadLetterBkt, err := tx.CreateBucketIfNotExists(deadLetterCollection)
+		if err != nil {
+			return err
+		}
+
+		if job.State == jm.ExecutionDeadLettered {
+			err = deadLetterBkt.Put(job.GetUniqueKey(collection), job.StringifyTriggerTime())
+		} else {
+			err = deadLetterBkt.Delete(job.GetUniqueKey(collection))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (bds *boltDataStore) DeleteJob(collection, jobID string) (offset int64, err error) {
 	// To satisfy interface check. We are not maintaining any offset at boltdb
-	return 0, bds.deleteJob(collection, jobID)
+	return 0, bds.deleteJob(collection, jobID, noOffset)
+}
+
+// ApplyDeleteJob deletes the job and records the wal offset in the same transaction
+func (bds *boltDataStore) ApplyDeleteJob(offset int64, collection, jobID string) error {
+	return bds.deleteJob(collection, jobID, offset)
 }
 
-func (bds *boltDataStore) deleteJob(collection, jobID string) error {
+func (bds *boltDataStore) deleteJob(collection, jobID string, offset int64) error {
 	// Start the transaction.
 	tx, err := bds.db.Begin(true)
 	if err != nil {
@@ -151,6 +283,23 @@ func (bds *boltDataStore) deleteJob(collection, jobID string) error {
 	}
 	defer tx.Rollback()
 
+	if err = removeJob(tx, collection, jobID); err != nil {
+		return err
+	}
+
+	if offset != noOffset {
+		if err = putAppliedOffset(tx, offset); err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction and check for error.
+	return commit(tx)
+}
+
+// removeJob deletes the job from its collection bucket and the schedule and dead letter buckets in the transaction.
+// It returns ErrKeyNotFound if the job does not exist
+func removeJob(tx *bolt.Tx, collection, jobID string) error {
 	// Fetch the job from collection bucket, get the job value
 	// and delete entry from collection bucket
 	var jobByteValue []byte
@@ -170,37 +319,250 @@ func (bds *boltDataStore) deleteJob(collection, jobID string) error {
 		}
 	}
 
-	// Parse the job from bytes
-	job, err := jm.GetJobFromBytes(jobByteValue)
+	// Delete the job from schedule bucket.
+	if err := removeSchedule(tx, collection, jobByteValue); err != nil {
+		return err
+	}
+
+	// Delete the job from dead letter bucket
+	if deadLetterBkt := tx.Bucket(deadLetterCollection); deadLetterBkt != nil {
+		if err := deadLetterBkt.Delete((&jm.Job{ID: jobID}).GetUniqueKey(collection)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyBatch applies all the writes and records the wal offset in a single transaction.
+// The deletes of the jobs that do not exist are ignored
+func (bds *boltDataStore) ApplyBatch(offset int64, writes []BatchWrite) error {
+	tx, err := bds.db.Begin(true)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Delete the job from schedule bucket.
-	{
-		// Fetch the schedule collection bucket
-		scheduleBkt, err := tx.CreateBucketIfNotExists(
-			scheduleCollection)
-		if err != nil {
+	for _, w := range writes {
+		if w.Job != nil {
+			if err = putJob(tx, w.Collection, w.Job); err == nil {
+				err = putIdempotencyRecord(tx, w.Collection, w.Job, offset)
+			}
+		} else {
+			err = removeJob(tx, w.Collection, w.JobID)
+		}
+		if err != nil && err != ErrKeyNotFound {
 			return err
 		}
+	}
 
-		// Fetch the minutewise bucket in scheduleBkt
-		minuteBkt, err := scheduleBkt.CreateBucketIfNotExists(
-			job.GetMinuteBucketName())
+	if offset != noOffset {
+		if err = putAppliedOffset(tx, offset); err != nil {
+			return err
+		}
+	}
+
+	return commit(tx)
+}
+
+// commit commits the write transaction and records the time taken to sync it to the disk
+func commit(tx *bolt.Tx) error {
+	start := time.Now()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	metrics.DatastoreCommitDuration.Observe(time.Since(start).Seconds())
+	return nil
+}
+
+// putIdempotencyRecord records the outcome of the set of the job if it has an idempotency key.
+// The changes in the execution state of the job do not change its version, hence the record
+// of the first set of a version is retained until it is removed after the idempotency window.
+func putIdempotencyRecord(tx *bolt.Tx, collection string, job *jm.Job, offset int64) error {
+	if job.IdempotencyKey == "" {
+		return nil
+	}
+
+	bkt, err := tx.CreateBucketIfNotExists(idempotencyCollection)
+	if err != nil {
+		return err
+	}
+
+	key := getIdempotencyKey(collection, job.IdempotencyKey)
+	if val := bkt.Get(key); val != nil {
+		var existing jm.IdempotencyRecord
+		if err = json.Unmarshal(val, &existing); err == nil && existing.JobID == job.ID && existing.Version == job.Version {
+			return nil
+		}
+	}
+
+	by, err := json.Marshal(jm.IdempotencyRecord{
+		JobID:     job.ID,
+		Offset:    offset,
+		Version:   job.Version,
+		CreatedMS: timeutil.GetCurrentMillis(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return bkt.Put(key, by)
+}
+
+// GetIdempotencyRecord returns the outcome of the first set of a job with the idempotency key in the collection
+func (bds *boltDataStore) GetIdempotencyRecord(collection, key string) (*jm.IdempotencyRecord, error) {
+	tx, err := bds.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	bkt := tx.Bucket(idempotencyCollection)
+	if bkt == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	val := bkt.Get(getIdempotencyKey(collection, key))
+	if val == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	var record jm.IdempotencyRecord
+	if err = json.Unmarshal(val, &record); err != nil {
+		return nil, ErrInvalidDataformat
+	}
+
+	return &record, nil
+}
+
+// RemoveIdempotencyRecords scans all the records and removes the ones created before the time.
+// The records are not a part of the wal, every replica removes them on its own.
+func (bds *boltDataStore) RemoveIdempotencyRecords(beforeMS int) (removed int, err error) {
+	err = bds.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(idempotencyCollection)
+		if bkt == nil {
+			return nil
+		}
+
+		// The keys are deleted after the scan, as deleting them while iterating skips the next key
+		var expired [][]byte
+		err := bkt.ForEach(func(k, v []byte) error {
+			var record jm.IdempotencyRecord
+			if err := json.Unmarshal(v, &record); err != nil || record.CreatedMS < beforeMS {
+				expired = append(expired, k)
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
 
-		// Delete the schedule in the minute wise bucket
-		uniqueKey := job.GetUniqueKey(collection)
-		if err = minuteBkt.Delete(uniqueKey); err != nil {
+		for _, k := range expired {
+			if err := bkt.Delete(k); err != nil {
+				return err
+			}
+		}
+		removed = len(expired)
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// getIdempotencyKey returns the key of the record, prefixed with the length of the collection
+// so that the keys of different collections do not collide, like ("a_b", "c") and ("a", "b_c")
+func getIdempotencyKey(collection, key string) []byte {
+	return []byte(strconv.Itoa(len(collection)) + ":" + collection + key)
+}
+
+// GetAppliedOffset returns the last wal offset applied to the datastore.
+// It returns -1 if no offset has been applied yet.
+func (bds *boltDataStore) GetAppliedOffset() (int64, error) {
+	tx, err := bds.db.Begin(false)
+	if err != nil {
+		return noOffset, err
+	}
+	defer tx.Rollback()
+
+	return getAppliedOffset(tx)
+}
+
+func getAppliedOffset(tx *bolt.Tx) (int64, error) {
+	metaBkt := tx.Bucket(metaCollection)
+	if metaBkt == nil {
+		return noOffset, nil
+	}
+
+	val := metaBkt.Get(appliedOffsetKey)
+	if val == nil {
+		return noOffset, nil
+	}
+
+	offset, err := strconv.ParseInt(string(val), 10, 64)
+	if err != nil {
+		return noOffset, ErrInvalidDataformat
+	}
+
+	return offset, nil
+}
+
+// SetAppliedOffset overwrites the last applied wal offset
+func (bds *boltDataStore) SetAppliedOffset(offset int64) error {
+	return bds.db.Update(func(tx *bolt.Tx) error {
+		return putAppliedOffset(tx, offset)
+	})
+}
+
+// Snapshot writes the whole database to w in a read transaction, hence the writes are not blocked
+func (bds *boltDataStore) Snapshot(w io.Writer) (appliedOffset int64, err error) {
+	err = bds.db.View(func(tx *bolt.Tx) error {
+		appliedOffset, err = getAppliedOffset(tx)
+		if err != nil {
 			return err
 		}
+
+		_, err = tx.WriteTo(w)
+		return err
+	})
+
+	return appliedOffset, err
+}
+
+// putAppliedOffset records the wal offset in the transaction
+func putAppliedOffset(tx *bolt.Tx, offset int64) error {
+	metaBkt, err := tx.CreateBucketIfNotExists(metaCollection)
+	if err != nil {
+		return err
 	}
 
-	// Commit the transaction and check for error.
-	return tx.Commit()
+	return metaBkt.Put(appliedOffsetKey, []byte(strconv.FormatInt(offset, 10)))
+}
+
+// removeSchedule deletes the entry of the job from its minute wise bucket
+func removeSchedule(tx *bolt.Tx, collection string, jobByteValue []byte) error {
+	// Parse the job from bytes
+	job, err := jm.GetJobFromBytes(jobByteValue)
+	if err != nil {
+		return err
+	}
+
+	// Fetch the schedule collection bucket
+	scheduleBkt, err := tx.CreateBucketIfNotExists(
+		scheduleCollection)
+	if err != nil {
+		return err
+	}
+
+	// Fetch the minutewise bucket in scheduleBkt
+	minuteBkt := scheduleBkt.Bucket(job.GetMinuteBucketName())
+	if minuteBkt == nil {
+		return nil
+	}
+
+	// Delete the schedule in the minute wise bucket
+	return minuteBkt.Delete(job.GetUniqueKey(collection))
 }
 
 func (bds *boltDataStore) Type() jobstore.JobStoreType {
@@ -230,36 +592,211 @@ func (bds *boltDataStore) FetchJobForBucket(minute int) ([]*jm.Job, error) {
 		return nil, nil
 	}
 
+	return fetchJobs(tx, minuteBucket)
+}
+
+// FetchJobsForBuckets seeks to the first minute bucket and iterates over the buckets till the last one,
+// hence the minutes without any jobs are not looked up. The minute bucket names are compared as strings,
+// which matches their numeric order as all the minutes since epoch have the same number of digits.
+func (bds *boltDataStore) FetchJobsForBuckets(from, to int) ([]*jm.Job, error) {
+	tx, err := bds.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	scheduleBkt := tx.Bucket(scheduleCollection)
+	if scheduleBkt == nil {
+		return nil, nil
+	}
+
 	var jobs []*jm.Job
+	last := []byte(strconv.Itoa(to))
 
-	// Fetch all the jobs in the bucket
-	c := minuteBucket.Cursor()
-	for k, _ := c.First(); k != nil; k, _ = c.Next() {
-		// TODO: Read the byte values to job struct
-		jobDetails := bytes.Split(k, []byte("_"))
-		if len(jobDetails) != 2 {
-			return nil, ErrInvalidDataformat
+	c := scheduleBkt.Cursor()
+	for k, v := c.Seek([]byte(strconv.Itoa(from))); k != nil && bytes.Compare(k, last) <= 0; k, v = c.Next() {
+		if v != nil {
+			// Not a minute bucket
+			continue
 		}
-		collection := jobDetails[0]
-		jobID := jobDetails[1]
 
-		// Fetch the collection
-		collectionBkt := tx.Bucket(collection)
-		if collectionBkt == nil {
-			continue
+		bucketJobs, err := fetchJobs(tx, scheduleBkt.Bucket(k))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, bucketJobs...)
+	}
+
+	return jobs, nil
+}
+
+// FetchDeadLetterJobs iterates over the dead lettered jobs with the collection as the key prefix
+func (bds *boltDataStore) FetchDeadLetterJobs(collection string) ([]*jm.Job, error) {
+	tx, err := bds.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	deadLetterBkt := tx.Bucket(deadLetterCollection)
+	if deadLetterBkt == nil {
+		return nil, nil
+	}
+
+	// Jobs with the collection as the prefix of their key
+	prefix := []byte(collection + "_")
+
+	var jobs []*jm.Job
+	c := deadLetterBkt.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		j, err := getJobFromKey(tx, k)
+		if err != nil {
+			return nil, err
 		}
+		if j != nil {
+			jobs = append(jobs, j)
+		}
+	}
+
+	return jobs, nil
+}
+
+// ListJobs scans the minute buckets in the time range of the query, or the collection bucket if the query has
+// no time range. The scan starts from the cursor of the query and stops once the limit is reached.
+func (bds *boltDataStore) ListJobs(collection string, query jm.JobQuery) ([]*jm.Job, error) {
+	tx, err := bds.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-		// Fetch the job
-		val := collectionBkt.Get(jobID)
-		j, err := jm.GetJobFromBytes(val)
+	if query.IsTimeRange() {
+		return listScheduledJobs(tx, collection, query)
+	}
+
+	bkt := tx.Bucket([]byte(collection))
+	if bkt == nil {
+		return nil, nil
+	}
+
+	var jobs []*jm.Job
+	c := bkt.Cursor()
+	for k, v := c.Seek([]byte(query.AfterID)); k != nil && len(jobs) < query.Limit; k, v = c.Next() {
+		j, err := jm.GetJobFromBytes(v)
 		if err != nil {
-			// TODO : Check return
 			return nil, err
 		}
+		j.Collection = collection
+
+		if query.Matches(j) {
+			jobs = append(jobs, j)
+		}
+	}
+
+	return jobs, nil
+}
+
+// listScheduledJobs iterates over the minute buckets from the start of the time range or the cursor, whichever
+// is later. The jobs of a minute bucket are sorted by their scheduled time, as the bucket is keyed by the job.
+func listScheduledJobs(tx *bolt.Tx, collection string, query jm.JobQuery) ([]*jm.Job, error) {
+	scheduleBkt := tx.Bucket(scheduleCollection)
+	if scheduleBkt == nil {
+		return nil, nil
+	}
+
+	fromMS := query.FromMS
+	if query.AfterMS > fromMS {
+		fromMS = query.AfterMS
+	}
+
+	var last []byte
+	if query.ToMS > 0 {
+		last = []byte(strconv.Itoa(query.ToMS / 60000))
+	}
+
+	// Jobs of the collection have it as the prefix of their key
+	prefix := []byte(collection + "_")
+
+	var jobs []*jm.Job
+	c := scheduleBkt.Cursor()
+	for k, v := c.Seek([]byte(strconv.Itoa(fromMS / 60000))); k != nil && len(jobs) < query.Limit; k, v = c.Next() {
+		if last != nil && bytes.Compare(k, last) > 0 {
+			break
+		}
+		if v != nil {
+			// Not a minute bucket
+			continue
+		}
+
+		var bucketJobs []*jm.Job
+		minuteCursor := scheduleBkt.Bucket(k).Cursor()
+		for key, _ := minuteCursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = minuteCursor.Next() {
+			j, err := getJobFromKey(tx, key)
+			if err != nil {
+				return nil, err
+			}
+			if j != nil && query.Matches(j) {
+				bucketJobs = append(bucketJobs, j)
+			}
+		}
+
+		sort.Slice(bucketJobs, func(a, b int) bool { return query.Less(bucketJobs[a], bucketJobs[b]) })
+		jobs = append(jobs, bucketJobs...)
+	}
+
+	if len(jobs) > query.Limit {
+		jobs = jobs[:query.Limit]
+	}
+
+	return jobs, nil
+}
+
+// fetchJobs returns all the jobs scheduled in the minute bucket
+func fetchJobs(tx *bolt.Tx, minuteBucket *bolt.Bucket) ([]*jm.Job, error) {
+	var jobs []*jm.Job
 
-		// Add all the jobs to list
-		jobs = append(jobs, j)
+	// Fetch all the jobs in the bucket
+	c := minuteBucket.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		j, err := getJobFromKey(tx, k)
+		if err != nil {
+			return nil, err
+		}
+		if j != nil {
+			jobs = append(jobs, j)
+		}
 	}
 
 	return jobs, nil
 }
+
+// getJobFromKey returns the job for the unique key of the job, i.e. collection + "_" + job.ID.
+// It returns nil if the collection does not exist
+func getJobFromKey(tx *bolt.Tx, key []byte) (*jm.Job, error) {
+	// TODO: Read the byte values to job struct
+	jobDetails := bytes.Split(key, []byte("_"))
+	if len(jobDetails) != 2 {
+		return nil, ErrInvalidDataformat
+	}
+	collection := jobDetails[0]
+	jobID := jobDetails[1]
+
+	// Fetch the collection
+	collectionBkt := tx.Bucket(collection)
+	if collectionBkt == nil {
+		return nil, nil
+	}
+
+	// Fetch the job
+	val := collectionBkt.Get(jobID)
+	j, err := jm.GetJobFromBytes(val)
+	if err != nil {
+		// TODO : Check return
+		return nil, err
+	}
+
+	// Older jobs were stored without the collection
+	j.Collection = string(collection)
+
+	return j, nil
+}
@@ -2,7 +2,11 @@ package datastore
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
 )
 
 func TestCreateBoltDataStore(t *testing.T) {
@@ -31,3 +35,188 @@ func TestCreateBoltDataStore(t *testing.T) {
 	defer dbStore.Close()
 
 }
+
+func TestFetchJobsForBuckets(t *testing.T) {
+	dbStore, err := CreateBoltDataStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("error while opening the db %v", err)
+	}
+	defer dbStore.Close()
+
+	minute := 60000
+	start := 28000000 * minute
+	jobs := []*jm.Job{
+		{ID: "job1", TriggerMS: start, Route: "route1"},
+		{ID: "job2", TriggerMS: start + 2*minute, Route: "route1"},
+		{ID: "job3", TriggerMS: start + 5*minute, Route: "route1"},
+		{ID: "delivered", TriggerMS: start + 2*minute, Route: "route1", State: jm.ExecutionDelivered},
+	}
+	for _, j := range jobs {
+		if _, err = dbStore.SetJob("collection1", j); err != nil {
+			t.Fatalf("error while setting the job %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to int
+		want     []string
+	}{
+		{name: "all buckets", from: start / minute, to: start/minute + 5, want: []string{"job1", "job2", "job3"}},
+		{name: "middle bucket", from: start/minute + 1, to: start/minute + 4, want: []string{"job2"}},
+		{name: "no buckets", from: start/minute + 6, to: start/minute + 10, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dbStore.FetchJobsForBuckets(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("FetchJobsForBuckets() error = %v", err)
+			}
+
+			var ids []string
+			for _, j := range got {
+				ids = append(ids, j.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("FetchJobsForBuckets() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchDeadLetterJobs(t *testing.T) {
+	dbStore, err := CreateBoltDataStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("error while opening the db %v", err)
+	}
+	defer dbStore.Close()
+
+	set := func(collection string, j jm.Job) {
+		if _, err := dbStore.SetJob(collection, &j); err != nil {
+			t.Fatalf("error while setting the job %v", err)
+		}
+	}
+	deadLetters := func(collection string) []string {
+		jobs, err := dbStore.FetchDeadLetterJobs(collection)
+		if err != nil {
+			t.Fatalf("FetchDeadLetterJobs() error = %v", err)
+		}
+
+		var ids []string
+		for _, j := range jobs {
+			ids = append(ids, j.ID)
+		}
+		return ids
+	}
+
+	set("orders", jm.Job{ID: "job1", TriggerMS: 1000, State: jm.ExecutionDeadLettered, LastError: "HTTP response code is not 200"})
+	set("orders", jm.Job{ID: "job2", TriggerMS: 1000, State: jm.ExecutionDeadLettered})
+	set("orders", jm.Job{ID: "job3", TriggerMS: 1000, State: jm.ExecutionScheduled})
+	set("ordersArchive", jm.Job{ID: "job4", TriggerMS: 1000, State: jm.ExecutionDeadLettered})
+
+	if got, want := deadLetters("orders"), []string{"job1", "job2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FetchDeadLetterJobs() = %v, want %v", got, want)
+	}
+
+	// Setting the job again removes it from the dead letter collection
+	set("orders", jm.Job{ID: "job1", TriggerMS: 2000, State: jm.ExecutionScheduled})
+	if _, err = dbStore.DeleteJob("orders", "job2"); err != nil {
+		t.Fatalf("error while deleting the job %v", err)
+	}
+
+	if got := deadLetters("orders"); got != nil {
+		t.Errorf("FetchDeadLetterJobs() = %v, want none", got)
+	}
+	if got, want := deadLetters("ordersArchive"), []string{"job4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FetchDeadLetterJobs() = %v, want %v", got, want)
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	dbStore, err := CreateBoltDataStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("error while opening the db %v", err)
+	}
+	defer dbStore.Close()
+
+	minute := 28000000 * 60000
+	jobs := []jm.Job{
+		{ID: "job1", TriggerMS: minute + 30000, Route: "r1"},
+		{ID: "job2", TriggerMS: minute + 10000, Route: "r2"},
+		{ID: "job3", TriggerMS: minute + 60000, Route: "r1"},
+		{ID: "job4", TriggerMS: minute + 120000, Route: "r1"},
+		{ID: "job5", TriggerMS: minute + 20000, Route: "r1", State: jm.ExecutionDelivered},
+		{ID: "job6", TriggerMS: minute - 60000, Route: "r1", State: jm.ExecutionRetrying, NextAttemptMS: minute + 61000},
+	}
+	for _, j := range jobs {
+		j := j
+		if _, err := dbStore.SetJob("orders", &j); err != nil {
+			t.Fatalf("error while setting the job %v", err)
+		}
+	}
+	if _, err := dbStore.SetJob("ordersArchive", &jm.Job{ID: "job7", TriggerMS: minute + 30000}); err != nil {
+		t.Fatalf("error while setting the job %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query jm.JobQuery
+		want  []string
+	}{
+		{name: "all jobs", query: jm.JobQuery{Limit: 10}, want: []string{"job1", "job2", "job3", "job4", "job5", "job6"}},
+		{name: "all jobs after cursor", query: jm.JobQuery{Limit: 2, AfterID: "job2"}, want: []string{"job3", "job4"}},
+		{name: "time range", query: jm.JobQuery{FromMS: minute, ToMS: minute + 61000, Limit: 10}, want: []string{"job2", "job1", "job3", "job6"}},
+		{name: "open time range", query: jm.JobQuery{FromMS: minute + 60000, Limit: 10}, want: []string{"job3", "job6", "job4"}},
+		{name: "time range with limit", query: jm.JobQuery{FromMS: minute, Limit: 2}, want: []string{"job2", "job1"}},
+		{name: "time range after cursor", query: jm.JobQuery{FromMS: minute, Limit: 2, AfterMS: minute + 30000, AfterID: "job1"}, want: []string{"job3", "job6"}},
+		{name: "route", query: jm.JobQuery{FromMS: minute, Route: "r2", Limit: 10}, want: []string{"job2"}},
+	}
+
+	for _, tt := range tests {
+		got, err := dbStore.ListJobs("orders", tt.query)
+		if err != nil {
+			t.Fatalf("%s: ListJobs() error = %v", tt.name, err)
+		}
+
+		var ids []string
+		for _, j := range got {
+			ids = append(ids, j.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: ListJobs() = %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
+
+func TestIdempotencyKeyCollision(t *testing.T) {
+	dbStore, err := CreateBoltDataStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("error while opening the db %v", err)
+	}
+	defer dbStore.Close()
+
+	// The collections and keys join to the same string with an underscore
+	jobs := []struct {
+		collection string
+		job        *jm.Job
+	}{
+		{collection: "a_b", job: &jm.Job{ID: "job1", TriggerMS: 28000000 * 60000, Route: "route1", IdempotencyKey: "c"}},
+		{collection: "a", job: &jm.Job{ID: "job2", TriggerMS: 28000000 * 60000, Route: "route1", IdempotencyKey: "b_c"}},
+	}
+	for _, j := range jobs {
+		if _, err = dbStore.SetJob(j.collection, j.job); err != nil {
+			t.Fatalf("error while setting the job %v", err)
+		}
+	}
+
+	for _, j := range jobs {
+		record, err := dbStore.GetIdempotencyRecord(j.collection, j.job.IdempotencyKey)
+		if err != nil {
+			t.Fatalf("GetIdempotencyRecord() error = %v", err)
+		}
+		if record == nil || record.JobID != j.job.ID {
+			t.Errorf("GetIdempotencyRecord(%q, %q) = %+v, want the record of %s", j.collection, j.job.IdempotencyKey, record, j.job.ID)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package datashard
+
+import "errors"
+
+var (
+	ErrUnknownLogOperation = errors.New("unknown wal log operation")
+
+	// The entries before the replicated entry are missing on this shard
+	ErrReplicationGap = errors.New("replication gap, entries before the offset are missing")
+
+	// The wal entries after the offset have been removed from the wal
+	ErrOffsetNotAvailable = errors.New("offset is no longer available in the wal")
+
+	// The chunk does not continue the snapshot being received
+	ErrInvalidSnapshotChunk = errors.New("snapshot chunk does not match the transfer in progress")
+
+	// The snapshot received does not match the checksum of the snapshot sent
+	ErrSnapshotChecksumMismatch = errors.New("snapshot checksum mismatch")
+
+	// No snapshot is being received
+	ErrNoTransferInProgress = errors.New("no snapshot transfer in progress")
+)
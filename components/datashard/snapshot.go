@@ -0,0 +1,306 @@
+package datashard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	"go.uber.org/zap"
+)
+
+// snapshotChunkSize is the maximum size of a chunk streamed to the other nodes
+var snapshotChunkSize = 1 << 20 // 1MB
+
+// snapshotInfo identifies a snapshot of the shard
+type snapshotInfo struct {
+	// Last wal offset applied in the snapshot
+	Offset int64 `json:"offset"`
+
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// snapshotPath contains the latest snapshot of this shard that is streamed to the other nodes
+func (ds *DataShard) snapshotPath() string {
+	return fmt.Sprintf("%s/%d.snapshot", ds.parentDirectory, ds.slot)
+}
+
+// transferPath contains the snapshot being received from the leader shard
+func (ds *DataShard) transferPath() string {
+	return fmt.Sprintf("%s/%d.transfer", ds.parentDirectory, ds.slot)
+}
+
+// transferInfoPath contains the details of the snapshot being received
+func (ds *DataShard) transferInfoPath() string {
+	return fmt.Sprintf("%s/%d.transfer.json", ds.parentDirectory, ds.slot)
+}
+
+// StreamSnapshot calls f on the chunks of a snapshot of this shard starting from the byte offset.
+// The last snapshot is reused if its offset matches snapshotOffset so that an interrupted transfer
+// can be resumed. Otherwise, a new snapshot is created and streamed from the beginning.
+func (ds *DataShard) StreamSnapshot(snapshotOffset, byteOffset int64, f func(jobstore.SnapshotChunk) error) error {
+	ds.snapshotMu.Lock()
+	info := ds.snapshot
+	if info == nil || byteOffset <= 0 || byteOffset > info.Size || info.Offset != snapshotOffset {
+		var err error
+		if info, err = ds.createSnapshot(); err != nil {
+			ds.snapshotMu.Unlock()
+			return err
+		}
+		byteOffset = 0
+	}
+
+	// The file is opened with the lock held so that it is not replaced by another snapshot in between
+	file, err := os.Open(ds.snapshotPath())
+	ds.snapshotMu.Unlock()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err = file.Seek(byteOffset, io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, snapshotChunkSize)
+	for byteOffset < info.Size {
+		n, err := io.ReadFull(file, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		err = f(jobstore.SnapshotChunk{
+			SnapshotOffset: info.Offset,
+			Size:           info.Size,
+			Checksum:       info.Checksum,
+			ByteOffset:     byteOffset,
+			Data:           buf[:n],
+		})
+		if err != nil {
+			return err
+		}
+
+		byteOffset += int64(n)
+	}
+
+	return nil
+}
+
+// createSnapshot writes a copy of the datastore to the snapshot file. It must be called with snapshotMu held
+func (ds *DataShard) createSnapshot() (*snapshotInfo, error) {
+	tmpPath := ds.snapshotPath() + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpPath)
+
+	ds.mu.RLock()
+	store := ds.store
+	ds.mu.RUnlock()
+
+	h := sha256.New()
+	offset, err := store.Snapshot(io.MultiWriter(file, h))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+
+	if err = os.Rename(tmpPath, ds.snapshotPath()); err != nil {
+		return nil, err
+	}
+
+	ds.snapshot = &snapshotInfo{
+		Offset:   offset,
+		Size:     stat.Size(),
+		Checksum: hex.EncodeToString(h.Sum(nil)),
+	}
+
+	ds.log.Info("created snapshot",
+		zap.Int64("offset", offset),
+		zap.Int64("size", ds.snapshot.Size),
+	)
+	return ds.snapshot, nil
+}
+
+// GetTransferState returns the offset of the snapshot being received and the number of bytes received so far.
+// The byte offset is 0 if no snapshot is being received.
+func (ds *DataShard) GetTransferState() (snapshotOffset, byteOffset int64) {
+	info, err := ds.readTransferInfo()
+	if err != nil {
+		return -1, 0
+	}
+
+	stat, err := os.Stat(ds.transferPath())
+	if err != nil || stat.Size() > info.Size {
+		return -1, 0
+	}
+
+	return info.Offset, stat.Size()
+}
+
+// WriteSnapshotChunk writes the chunk of the snapshot streamed from the leader shard to the transfer file.
+// A chunk at the byte offset 0 starts a new transfer. The transfer file is retained if the stream
+// breaks, so that the transfer can be resumed from the bytes received so far.
+func (ds *DataShard) WriteSnapshotChunk(chunk jobstore.SnapshotChunk) error {
+	info := snapshotInfo{
+		Offset:   chunk.SnapshotOffset,
+		Size:     chunk.Size,
+		Checksum: chunk.Checksum,
+	}
+
+	flag := os.O_WRONLY | os.O_APPEND
+	if chunk.ByteOffset == 0 {
+		// Start a new transfer
+		by, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(ds.transferInfoPath(), by, 0666); err != nil {
+			return err
+		}
+
+		flag |= os.O_CREATE | os.O_TRUNC
+	} else {
+		current, err := ds.readTransferInfo()
+		if err != nil || *current != info {
+			return ErrInvalidSnapshotChunk
+		}
+		if _, received := ds.GetTransferState(); received != chunk.ByteOffset {
+			return ErrInvalidSnapshotChunk
+		}
+	}
+
+	file, err := os.OpenFile(ds.transferPath(), flag, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(chunk.Data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// InstallTransfer verifies the snapshot received from the leader shard and replaces the data of this shard with it.
+// The wal is recreated from the offset of the snapshot. It returns the offset of the snapshot.
+func (ds *DataShard) InstallTransfer() (int64, error) {
+	info, err := ds.readTransferInfo()
+	if err != nil {
+		return 0, ErrNoTransferInProgress
+	}
+
+	if err = ds.verifyTransfer(info); err != nil {
+		ds.removeTransfer()
+		return 0, err
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if err = ds.close(); err != nil {
+		return 0, err
+	}
+
+	// The entries in the existing wal are either a part of the snapshot or were never applied on the leader
+	if err = os.RemoveAll(ds.walPath()); err != nil {
+		return 0, err
+	}
+
+	if err = os.Rename(ds.transferPath(), ds.dbPath()); err != nil {
+		return 0, err
+	}
+	ds.removeTransfer()
+
+	if err = ds.open(); err != nil {
+		return 0, err
+	}
+
+	if info.Offset >= 0 {
+		// Mark the start of the wal so that the entries after the snapshot can be appended.
+		// The wal replay on open has reset the applied offset as the wal was empty, hence it is applied again
+		checkpoint := wal.LogEntry{
+			Operation: wal.CheckpointLog,
+			Offset:    info.Offset,
+		}
+		if err = ds.appendEntry(checkpoint); err != nil {
+			return 0, err
+		}
+		if err = ds.apply(checkpoint); err != nil {
+			return 0, err
+		}
+	}
+
+	ds.log.Info("installed snapshot",
+		zap.Int64("offset", info.Offset),
+		zap.Int64("size", info.Size),
+	)
+	return info.Offset, nil
+}
+
+// verifyTransfer checks the size and the checksum of the snapshot received
+func (ds *DataShard) verifyTransfer(info *snapshotInfo) error {
+	file, err := os.Open(ds.transferPath())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, file)
+	if err != nil {
+		return err
+	}
+
+	if n != info.Size || hex.EncodeToString(h.Sum(nil)) != info.Checksum {
+		return ErrSnapshotChecksumMismatch
+	}
+
+	return nil
+}
+
+func (ds *DataShard) readTransferInfo() (*snapshotInfo, error) {
+	by, err := os.ReadFile(ds.transferInfoPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var info snapshotInfo
+	if err = json.Unmarshal(by, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func (ds *DataShard) removeTransfer() {
+	os.Remove(ds.transferPath())
+	os.Remove(ds.transferInfoPath())
+}
@@ -0,0 +1,31 @@
+package wal
+
+import "encoding/json"
+
+// NewBatchEntry returns a single entry containing all the set and delete entries,
+// so that they are added to the wal with a single offset and applied together
+func NewBatchEntry(entries []LogEntry) (LogEntry, error) {
+	by, err := json.Marshal(entries)
+	if err != nil {
+		return LogEntry{}, err
+	}
+
+	return LogEntry{
+		Operation: BatchLog,
+		Data:      by,
+	}, nil
+}
+
+// GetBatchEntries returns the entries contained in a batch entry
+func (le LogEntry) GetBatchEntries() ([]LogEntry, error) {
+	if le.Operation != BatchLog {
+		return nil, ErrNotBatchEntry
+	}
+
+	var entries []LogEntry
+	if err := json.Unmarshal(le.Data, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
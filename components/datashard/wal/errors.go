@@ -0,0 +1,17 @@
+package wal
+
+import "errors"
+
+var (
+	// The offset of the entry is not after the latest offset of the wal
+	ErrInvalidOffset = errors.New("entry offset is not after the latest offset")
+
+	// The entry does not contain a batch of entries
+	ErrNotBatchEntry = errors.New("not a batch entry")
+
+	// The name of a segment file does not contain its segment ID
+	ErrInvalidSegment = errors.New("invalid segment file name")
+
+	// The checksum of an entry in a segment does not match its data
+	ErrChecksumMismatch = errors.New("entry checksum mismatch")
+)
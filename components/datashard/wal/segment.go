@@ -0,0 +1,285 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// segmentPrefix is the prefix of the segment files.
+	// The segments are named wal.{segmentID}.{startOffset}
+	segmentPrefix = "wal."
+
+	// headerSize is the size of the length and the crc32 checksum stored before every entry
+	headerSize = 8
+
+	// syncInterval is the interval at which the buffered entries are written to the disk
+	syncInterval = 1 * time.Second
+)
+
+type segment struct {
+	id   int
+	path string
+}
+
+// getSegments returns the segments in the directory in the order of their segment IDs.
+// Sorting the segments by their file names would put wal.10 before wal.2.
+func getSegments(walDir string) ([]segment, error) {
+	files, err := filepath.Glob(filepath.Join(walDir, segmentPrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]segment, 0, len(files))
+	for _, file := range files {
+		parts := strings.Split(strings.TrimPrefix(filepath.Base(file), segmentPrefix), ".")
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, ErrInvalidSegment
+		}
+
+		segments = append(segments, segment{id: id, path: file})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].id < segments[j].id })
+
+	return segments, nil
+}
+
+// replaySegments calls the function f on the entries of all the segments in order
+func replaySegments(walDir string, f func([]byte) error) error {
+	segments, err := getSegments(walDir)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range segments {
+		if err := replaySegment(s.path, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaySegment reads the entries of the segment. Every entry is stored as its
+// length and crc32 checksum followed by the data. An entry that is cut short at
+// the end of the segment was not synced completely, and is ignored.
+func replaySegment(path string, f func([]byte) error) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		// The oldest segment was removed after the segments were listed
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	header := make([]byte, headerSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		data := make([]byte, binary.LittleEndian.Uint32(header[:4]))
+		if _, err := io.ReadFull(r, data); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(header[4:]) {
+			return ErrChecksumMismatch
+		}
+
+		if err := f(data); err != nil {
+			return err
+		}
+	}
+}
+
+// segmentWriter appends the entries to the segments in the wal directory. A new segment is started
+// when the current one is full, and after every restart so that the entries are never written after
+// an entry that was cut short by the restart. Only the latest maxSegments segments are kept.
+type segmentWriter struct {
+	dir         string
+	maxSize     int64
+	maxSegments int
+	log         *zap.Logger
+
+	mu       sync.Mutex
+	segments []segment
+	file     *os.File
+	buf      *bufio.Writer
+	size     int64
+	header   [headerSize]byte
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newSegmentWriter returns a segment writer for the wal directory. The entries are written to the
+// disk once in every syncInterval, and when the writer is closed.
+func newSegmentWriter(walDir string, maxSize int64, maxSegments int, log *zap.Logger) (*segmentWriter, error) {
+	if err := os.MkdirAll(walDir, 0777); err != nil {
+		return nil, err
+	}
+
+	segments, err := getSegments(walDir)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &segmentWriter{
+		dir:         walDir,
+		maxSize:     maxSize,
+		maxSegments: maxSegments,
+		log:         log,
+		segments:    segments,
+		stop:        make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.keepSyncing()
+
+	return w, nil
+}
+
+// Write appends the data of the entry at the offset to the current segment
+func (w *segmentWriter) Write(offset int64, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	entrySize := int64(headerSize + len(data))
+	if w.file != nil && w.size+entrySize > w.maxSize {
+		if err := w.closeSegment(); err != nil {
+			return err
+		}
+	}
+
+	if w.file == nil {
+		if err := w.openSegment(offset); err != nil {
+			return err
+		}
+	}
+
+	binary.LittleEndian.PutUint32(w.header[:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(w.header[4:], crc32.ChecksumIEEE(data))
+	if _, err := w.buf.Write(w.header[:]); err != nil {
+		return err
+	}
+	if _, err := w.buf.Write(data); err != nil {
+		return err
+	}
+
+	w.size += entrySize
+	return nil
+}
+
+// openSegment starts a new segment with the entry at the offset, and removes the oldest segments.
+// It must be called with the lock held
+func (w *segmentWriter) openSegment(offset int64) error {
+	id := 0
+	if len(w.segments) > 0 {
+		id = w.segments[len(w.segments)-1].id + 1
+	}
+
+	path := filepath.Join(w.dir, fmt.Sprintf("%s%d.%d", segmentPrefix, id, offset))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+
+	w.file = file
+	w.buf = bufio.NewWriter(file)
+	w.size = 0
+	w.segments = append(w.segments, segment{id: id, path: path})
+
+	for len(w.segments) > w.maxSegments {
+		w.log.Info("Removing wal segment", zap.String("segment", w.segments[0].path))
+		if err := os.Remove(w.segments[0].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		w.segments = w.segments[1:]
+	}
+
+	return nil
+}
+
+// closeSegment writes the current segment to the disk and closes it. It must be called with the lock held
+func (w *segmentWriter) closeSegment() error {
+	if err := w.sync(); err != nil {
+		return err
+	}
+
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// sync writes the buffered entries to the disk. It must be called with the lock held
+func (w *segmentWriter) sync() error {
+	if w.file == nil {
+		return nil
+	}
+
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+
+	return w.file.Sync()
+}
+
+func (w *segmentWriter) keepSyncing() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			err := w.sync()
+			w.mu.Unlock()
+
+			if err != nil {
+				w.log.Error("Error while syncing wal segment", zap.Error(err))
+			}
+
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Close writes the buffered entries to the disk and closes the current segment
+func (w *segmentWriter) Close() error {
+	close(w.stop)
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	return w.closeSegment()
+}
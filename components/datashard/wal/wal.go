@@ -5,6 +5,9 @@ type LogEntry struct {
 	Data       []byte     `json:"data,omitempty"`
 	Collection string     `json:"col,omitempty"`
 	Operation  LogCommand `json:"op,omitempty"`
+
+	// Offset of the entry in the wal. It is set while adding the entry
+	Offset int64 `json:"offset,omitempty"`
 }
 
 // LogCommand specifies the type of operation for the wal command
@@ -13,16 +16,29 @@ type LogCommand byte
 var (
 	SetLog    LogCommand = 0x01
 	DeleteLog LogCommand = 0x02
+
+	// CheckpointLog is the first entry of a wal recreated from a snapshot. The entries
+	// till its offset are a part of the snapshot and are not present in the wal
+	CheckpointLog LogCommand = 0x03
+
+	// BatchLog contains multiple set and delete entries that are applied together
+	BatchLog LogCommand = 0x04
 )
 
 // WAL reads all the changes from the disk
 type WAL interface {
+	// AddEntry appends the entry to the wal and returns its offset
 	AddEntry(entry LogEntry) (int64, error)
 
-	// Replay calls the function f on all the records from the offset f
-	Replay(offset int64, f func([]byte) error) error
+	// AppendEntry appends an entry that already has an offset. It is used to replicate the
+	// entries of the leader shard so that the offsets of a shard are the same on all the replicas.
+	// It returns ErrInvalidOffset if the offset is not after the latest offset.
+	AppendEntry(entry LogEntry) error
+
+	// Replay calls the function f on all the entries after the offset
+	Replay(offset int64, f func(LogEntry) error) error
 
-	// GetLatestOffset returns the latest offset
+	// GetLatestOffset returns the latest offset. It returns -1 if the wal is empty
 	GetLatestOffset() int64
 
 	// Close safely closes the WAL. All the data is persisted in WAL before closing
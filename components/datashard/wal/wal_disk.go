@@ -2,15 +2,28 @@ package wal
 
 import (
 	"encoding/json"
-	"time"
+	"sync"
 
-	"github.com/aarthikrao/wal"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
+// tailSize is the number of latest entries that are kept in memory.
+// The entries are written to the disk only once in a second, hence the
+// recent entries are served from memory during replay.
+const tailSize = 1024
+
 type walStore struct {
-	w *wal.WriteAheadLog
+	w   *segmentWriter
+	dir string
+
+	// offset of the last entry added to the wal. The entries carry their own offsets
+	// so that they can be identified during replay irrespective of the segment they are in.
+	mu     sync.Mutex
+	offset int64
+
+	// tail contains the latest entries in the order of their offsets
+	tail []LogEntry
 }
 
 var _ WAL = (*walStore)(nil)
@@ -22,43 +35,129 @@ func InitaliseWriteAheadLog(
 	maxSegments int,
 	log *zap.Logger,
 ) (*walStore, error) {
-	w, err := wal.NewWriteAheadLog(&wal.WALOptions{
-		LogDir:            walDir,
-		MaxLogSize:        maxLogSize,
-		MaxSegments:       maxSegments,
-		Log:               log,
-		MaxWaitBeforeSync: 1 * time.Second, // TODO: change this variable to default
-	})
+	w, err := newSegmentWriter(walDir, maxLogSize, maxSegments, log)
 	if err != nil {
 		return nil, err
 	}
 
-	return &walStore{
-		w: w,
-	}, nil
+	ws := &walStore{
+		w:      w,
+		dir:    walDir,
+		offset: -1,
+	}
+
+	// Find the offset of the last entry in the existing segments
+	err = ws.Replay(-1, func(le LogEntry) error {
+		if le.Offset > ws.offset {
+			ws.offset = le.Offset
+		}
+		return nil
+	})
+	if err != nil {
+		w.Close()
+		return nil, errors.Wrap(err, "wal latest offset")
+	}
+
+	return ws, nil
+}
+
+func (ws *walStore) AddEntry(le LogEntry) (offset int64, err error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	le.Offset = ws.offset + 1
+	if err = ws.write(le); err != nil {
+		return 0, err
+	}
+
+	return le.Offset, nil
+}
+
+// AppendEntry appends an entry that already has an offset
+func (ws *walStore) AppendEntry(le LogEntry) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if le.Offset <= ws.offset {
+		return ErrInvalidOffset
+	}
+
+	return ws.write(le)
 }
 
-func (wm *walStore) AddEntry(le LogEntry) (offset int64, err error) {
+// write writes the entry to the wal. It must be called with the lock held
+func (ws *walStore) write(le LogEntry) error {
 	entry, err := json.Marshal(le)
 	if err != nil {
-		errors.Wrap(err, "wal setjob entry")
+		return errors.Wrap(err, "wal setjob entry")
+	}
+
+	if err = ws.w.Write(le.Offset, entry); err != nil {
+		return err
 	}
 
-	return wm.w.Write(entry)
+	ws.offset = le.Offset
+	ws.tail = append(ws.tail, le)
+	if len(ws.tail) > tailSize {
+		ws.tail = ws.tail[len(ws.tail)-tailSize:]
+	}
+
+	return nil
 }
 
-// Replay calls the function f on all the records from the offset f
-func (ws *walStore) Replay(offset int64, f func([]byte) error) error {
-	return ws.w.Replay(offset, f)
+// Replay calls the function f on all the entries after the offset.
+// The segments are always read from the beginning in the order of their
+// segment IDs and the entries are filtered by the offset they carry. The entries that are in memory are
+// not read from the disk.
+func (ws *walStore) Replay(offset int64, f func(LogEntry) error) error {
+	ws.mu.Lock()
+	tail := make([]LogEntry, len(ws.tail))
+	copy(tail, ws.tail)
+	ws.mu.Unlock()
+
+	last := offset
+	if len(tail) == 0 || tail[0].Offset > offset+1 {
+		err := replaySegments(ws.dir, func(b []byte) error {
+			var le LogEntry
+			if err := json.Unmarshal(b, &le); err != nil {
+				return err
+			}
+
+			if le.Offset <= last {
+				return nil
+			}
+
+			last = le.Offset
+			return f(le)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, le := range tail {
+		if le.Offset <= last {
+			continue
+		}
+
+		last = le.Offset
+		if err := f(le); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // GetLatestOffset returns the latest offset
 func (ws *walStore) GetLatestOffset() int64 {
-	return ws.w.GetOffset()
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.offset
 }
 
 // Close safely closes the WAL. All the data is persisted in WAL before closing
 func (ws *walStore) Close() error {
 	return ws.w.Close()
-
 }
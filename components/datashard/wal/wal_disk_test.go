@@ -0,0 +1,143 @@
+package wal
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestReplaySegmentOrder(t *testing.T) {
+	walDir := t.TempDir() + "/"
+	ws, err := InitaliseWriteAheadLog(walDir, 1024, 100, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const entries = 2000
+	for i := 0; i < entries; i++ {
+		if _, err = ws.AddEntry(LogEntry{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = ws.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := getSegments(walDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) <= 10 {
+		t.Fatalf("got %d segments, want more than 10", len(segments))
+	}
+
+	// The entries are read from the disk, as there are none in memory after the restart
+	ws, err = InitaliseWriteAheadLog(walDir, 1024, 100, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	if offset := ws.GetLatestOffset(); offset != entries-1 {
+		t.Errorf("GetLatestOffset() = %d, want %d", offset, entries-1)
+	}
+
+	next := int64(0)
+	err = ws.Replay(-1, func(le LogEntry) error {
+		if le.Offset != next {
+			t.Errorf("Replay() offset = %d, want %d", le.Offset, next)
+		}
+		next = le.Offset + 1
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != entries {
+		t.Errorf("Replay() read %d entries, want %d", next, entries)
+	}
+}
+
+func TestRestartWithSegments(t *testing.T) {
+	walDir := t.TempDir() + "/"
+
+	// Every restart writes more entries to the wal, after there are more than 10 segments
+	const entries = 1000
+	for restart := 0; restart < 3; restart++ {
+		ws, err := InitaliseWriteAheadLog(walDir, 1024, 100, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < entries; i++ {
+			offset, err := ws.AddEntry(LogEntry{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := int64(restart*entries + i); offset != want {
+				t.Fatalf("AddEntry() offset = %d, want %d", offset, want)
+			}
+		}
+		if err = ws.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ws, err := InitaliseWriteAheadLog(walDir, 1024, 100, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	next := int64(0)
+	err = ws.Replay(-1, func(le LogEntry) error {
+		if le.Offset != next {
+			t.Errorf("Replay() offset = %d, want %d", le.Offset, next)
+		}
+		next = le.Offset + 1
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 3*entries {
+		t.Errorf("Replay() read %d entries, want %d", next, 3*entries)
+	}
+}
+
+func TestRemoveOldestSegments(t *testing.T) {
+	walDir := t.TempDir() + "/"
+	ws, err := InitaliseWriteAheadLog(walDir, 1024, 5, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const entries = 2000
+	for i := 0; i < entries; i++ {
+		if _, err = ws.AddEntry(LogEntry{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = ws.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segments, err := getSegments(walDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 5 {
+		t.Fatalf("got %d segments, want 5", len(segments))
+	}
+
+	// The latest entries are kept
+	ws, err = InitaliseWriteAheadLog(walDir, 1024, 5, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	if offset := ws.GetLatestOffset(); offset != entries-1 {
+		t.Errorf("GetLatestOffset() = %d, want %d", offset, entries-1)
+	}
+}
@@ -0,0 +1,110 @@
+package dht
+
+import "sort"
+
+// BalanceLeaders returns a copy of the shard map with the leadership of at most maxMoves shards handed over from
+// the nodes leading the most shards to their followers leading the least, along with the new leader of every moved shard.
+//
+// The leadership of a shard is moved only if its leader leads at least two shards more than the follower, and canLead
+// returns true for the follower, for example once it has caught up with the leader. The old leader becomes a follower.
+// Only the owners of the shards are balanced. A node that was replaced after a failure owns its shards again once it
+// has rejoined them, see RejoinNode, and nodes without shards get them through redistribution.
+func BalanceLeaders(shards map[ShardID]ShardLocation, maxMoves int, canLead func(ShardID, NodeID) bool) (map[ShardID]ShardLocation, map[ShardID]NodeID) {
+	balanced := make(map[ShardID]ShardLocation, len(shards))
+	leaderCount := make(map[NodeID]int)
+	shardIDs := make([]ShardID, 0, len(shards))
+	for shardID, shard := range shards {
+		balanced[shardID] = shard
+		shardIDs = append(shardIDs, shardID)
+
+		leaderCount[shard.Leader.ID]++
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	type handover struct {
+		shardID  ShardID
+		follower int
+		gain     int
+	}
+
+	moved := make(map[ShardID]NodeID)
+	// canLead is checked once per shard and follower, as it may call the nodes
+	rejected := make(map[ShardID]map[NodeID]bool)
+	for len(moved) < maxMoves {
+		candidates := []handover{}
+		for _, shardID := range shardIDs {
+			if _, ok := moved[shardID]; ok {
+				continue
+			}
+
+			shard := balanced[shardID]
+			for i, follower := range shard.Followers {
+				gain := leaderCount[shard.Leader.ID] - leaderCount[follower.ID]
+				if gain > 1 && !rejected[shardID][follower.ID] {
+					candidates = append(candidates, handover{shardID: shardID, follower: i, gain: gain})
+				}
+			}
+		}
+		// The most loaded leaders are relieved first
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].gain > candidates[j].gain })
+
+		handed := false
+		for _, c := range candidates {
+			shard := balanced[c.shardID]
+			follower := shard.Followers[c.follower]
+			if !canLead(c.shardID, follower.ID) {
+				if rejected[c.shardID] == nil {
+					rejected[c.shardID] = make(map[NodeID]bool)
+				}
+				rejected[c.shardID][follower.ID] = true
+				continue
+			}
+
+			leaderCount[shard.Leader.ID]--
+			leaderCount[follower.ID]++
+
+			balanced[c.shardID] = handOver(shard, c.follower)
+			moved[c.shardID] = follower.ID
+			handed = true
+			break
+		}
+
+		if !handed {
+			break
+		}
+	}
+
+	return balanced, moved
+}
+
+// HandOverLeadership returns a copy of the shard map with the shards led by the followers in leaders.
+// The old leader becomes a follower. The nodes that are not followers of their shard are ignored.
+func HandOverLeadership(shards map[ShardID]ShardLocation, leaders map[ShardID]NodeID) map[ShardID]ShardLocation {
+	m := make(map[ShardID]ShardLocation, len(shards))
+	for shardID, shard := range shards {
+		m[shardID] = shard
+
+		node, ok := leaders[shardID]
+		if !ok {
+			continue
+		}
+		for i, follower := range shard.Followers {
+			if follower.ID == node {
+				m[shardID] = handOver(shard, i)
+				break
+			}
+		}
+	}
+
+	return m
+}
+
+// handOver returns the shard led by its follower at index i, with the old leader in place of the follower
+func handOver(shard ShardLocation, i int) ShardLocation {
+	followers := append([]NodeDetails{}, shard.Followers...)
+	followers[i] = shard.Leader
+
+	shard.Leader = shard.Followers[i]
+	shard.Followers = followers
+	return shard
+}
@@ -0,0 +1,79 @@
+package dht
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBalanceLeaders(t *testing.T) {
+	// node1 leads all the shards, as it took over the shards of node2 when it failed
+	shards := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{{ID: "node2"}}},
+		1: {ID: 1, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{{ID: "node2"}}},
+		2: {ID: 2, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{{ID: "node2"}}},
+		3: {ID: 3, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{{ID: "node2"}}},
+	}
+
+	tests := []struct {
+		name      string
+		maxMoves  int
+		caughtUp  map[ShardID]bool
+		wantMoved map[ShardID]NodeID
+	}{
+		{
+			name:      "balanced",
+			maxMoves:  4,
+			caughtUp:  map[ShardID]bool{0: true, 1: true, 2: true, 3: true},
+			wantMoved: map[ShardID]NodeID{0: "node2", 1: "node2"},
+		},
+		{
+			name:      "throttled",
+			maxMoves:  1,
+			caughtUp:  map[ShardID]bool{0: true, 1: true, 2: true, 3: true},
+			wantMoved: map[ShardID]NodeID{0: "node2"},
+		},
+		{
+			name:      "follower behind",
+			maxMoves:  4,
+			caughtUp:  map[ShardID]bool{2: true},
+			wantMoved: map[ShardID]NodeID{2: "node2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, moved := BalanceLeaders(shards, tt.maxMoves, func(shardID ShardID, node NodeID) bool {
+				return tt.caughtUp[shardID]
+			})
+			if !reflect.DeepEqual(moved, tt.wantMoved) {
+				t.Errorf("BalanceLeaders() moved = %v, want %v", moved, tt.wantMoved)
+			}
+
+			for shardID, shard := range got {
+				want := shards[shardID]
+				if node, ok := tt.wantMoved[shardID]; ok {
+					want = ShardLocation{ID: shardID, Leader: NodeDetails{ID: node}, Followers: []NodeDetails{{ID: "node1"}}}
+				}
+				if !reflect.DeepEqual(shard, want) {
+					t.Errorf("BalanceLeaders() shard %d = %v, want %v", shardID, shard, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandOverLeadership(t *testing.T) {
+	shards := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{{ID: "node2"}, {ID: "node3"}}},
+		1: {ID: 1, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{{ID: "node2"}}},
+	}
+
+	got := HandOverLeadership(shards, map[ShardID]NodeID{0: "node3", 1: "node4"})
+	want := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node2"}, {ID: "node1"}}},
+		1: shards[1], // node4 does not follow the shard
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("HandOverLeadership() = %v, want %v", got, want)
+	}
+}
@@ -6,6 +6,17 @@ var (
 	ErrDHTNotInitialised     = errors.New("dht is not initialised")
 	ErrDHTAlreadyInitialised = errors.New("dht is already initialised")
 	ErrReplicasLessThanNodes = errors.New("replicas are lesser than physical nodes")
+	ErrShardNotFound         = errors.New("shard not found")
+	ErrInvalidHashing        = errors.New("invalid hashing, must be mod or ring")
+)
+
+// Hashing of the keys to the shards. It must be the same on all the nodes of the cluster
+const (
+	// The shard of a key is its hash modulo the number of shards
+	HashingMod = "mod"
+
+	// The shard of a key is found on a consistent hashing ring
+	HashingRing = "ring"
 )
 
 type ShardID int
@@ -15,10 +26,21 @@ type NodeDetails struct {
 	ID NodeID
 }
 
+// Node is a node of the cluster that the shards are placed on. A node of weight 2 owns
+// about twice as many shards as a node of weight 1. Nodes without a weight have weight 1
+type Node struct {
+	ID     NodeID
+	Weight int
+}
+
 type ShardLocation struct {
 	ID        ShardID
 	Leader    NodeDetails
 	Followers []NodeDetails
+
+	// Nodes copying the shard from the leader to replace a failed replica. They receive the writes
+	// but are not counted in the write concern, and become followers once they have caught up
+	Joining []NodeDetails `json:",omitempty"`
 }
 
 // DHT contains the location of a given key in a distributed data system.
@@ -26,14 +48,40 @@ type DHT interface {
 	// GetLocation returns the location of the leader and follower slot and corresponding node
 	GetShard(key string) (ShardLocation, error)
 
+	// GetShardLocation returns the location of the leader and follower slot for the shard
+	GetShardLocation(shardID ShardID) (ShardLocation, error)
+
 	GetLeaderShardsForNode(nodeID NodeID) []ShardID
 
 	GetAllShardsForNode(nodeID NodeID) []ShardID
 
-	// Load loads data from an already existing configuration.
+	// Load loads data from an already existing configuration along with its epoch.
 	// This must be taken called after confirmation from the master
-	Load(shards map[ShardID]ShardLocation)
+	Load(shards map[ShardID]ShardLocation, epoch uint64)
+
+	// Epoch returns the epoch of the shard map. It is increased by every change of the shard map,
+	// hence the nodes that have applied the same change are on the same epoch
+	Epoch() uint64
 
 	// Snapshot returns the current node vs slot ids map
 	Snapshot() map[ShardID]ShardLocation
 }
+
+// Placer is implemented by the DHTs that place the shards on the nodes themselves.
+// The shards are distributed with InitialiseDHT and Redistribute otherwise
+type Placer interface {
+	// Place returns the shard map of the shards on the nodes, with the leader and replicas-1 followers for every shard
+	Place(shardIDs []ShardID, nodes []Node, replicas int) (map[ShardID]ShardLocation, error)
+}
+
+// New creates an empty DHT with the hashing. virtualNodes is used only by the ring
+func New(hashing string, virtualNodes int) (DHT, error) {
+	switch hashing {
+	case HashingMod:
+		return Create(), nil
+	case HashingRing:
+		return CreateRing(virtualNodes), nil
+	}
+
+	return nil, ErrInvalidHashing
+}
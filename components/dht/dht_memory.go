@@ -11,6 +11,7 @@ import (
 type dht struct {
 	mu     sync.RWMutex
 	shards map[ShardID]ShardLocation
+	epoch  uint64
 }
 
 var _ DHT = (*dht)(nil)
@@ -61,6 +62,18 @@ func (d *dht) GetShard(key string) (ShardLocation, error) {
 	return d.shards[d.hashSlot(key)], nil
 }
 
+func (d *dht) GetShardLocation(shardID ShardID) (ShardLocation, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	shard, ok := d.shards[shardID]
+	if !ok {
+		return ShardLocation{}, ErrShardNotFound
+	}
+
+	return shard, nil
+}
+
 func (d *dht) hashSlot(key string) ShardID {
 	slotCount := uint64(len(d.shards))
 	hashValue := xxhash.Sum64([]byte(key))
@@ -100,16 +113,31 @@ func (d *dht) GetAllShardsForNode(nodeID NodeID) []ShardID {
 				shards = append(shards, shardLocation.ID)
 			}
 		}
+
+		// The joining nodes copy the shard like the followers
+		for _, node := range shardLocation.Joining {
+			if node.ID == nodeID {
+				shards = append(shards, shardLocation.ID)
+			}
+		}
 	}
 
 	return shards
 }
 
-func (d *dht) Load(shards map[ShardID]ShardLocation) {
+func (d *dht) Load(shards map[ShardID]ShardLocation, epoch uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.shards = shards
+	d.epoch = epoch
+}
+
+func (d *dht) Epoch() uint64 {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	d.shards = shards
+	return d.epoch
 }
 
 func (d *dht) Snapshot() map[ShardID]ShardLocation {
@@ -121,10 +149,16 @@ func (d *dht) Snapshot() map[ShardID]ShardLocation {
 		followers := []NodeDetails{}
 		followers = append(followers, shard.Followers...)
 
+		var joining []NodeDetails
+		if len(shard.Joining) > 0 {
+			joining = append(joining, shard.Joining...)
+		}
+
 		m[shardID] = ShardLocation{
 			ID:        shard.ID,
 			Leader:    shard.Leader,
 			Followers: followers,
+			Joining:   joining,
 		}
 	}
 
@@ -13,7 +13,7 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	d.Load(shards) // load data to d
+	d.Load(shards, 1) // load data to d
 }
 
 func Test_dht_GetShard(t *testing.T) {
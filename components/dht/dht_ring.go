@@ -0,0 +1,160 @@
+package dht
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// DefaultVirtualNodes is the number of points of a shard, or of a node of weight 1, on the ring
+const DefaultVirtualNodes = 64
+
+// ring is a consistent hashing implementation of the DHT. Every shard is placed on a ring at
+// virtualNodes points, and a key belongs to the shard of the first point after its hash.
+// Adding or removing a shard only moves the keys between its points and the points before them.
+// The shards are placed on the nodes the same way, see Place.
+type ring struct {
+	*dht
+
+	virtualNodes int
+
+	mu     sync.RWMutex
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash    uint64
+	shardID ShardID
+	nodeID  NodeID
+}
+
+var (
+	_ DHT    = (*ring)(nil)
+	_ Placer = (*ring)(nil)
+)
+
+// CreateRing initializes an empty consistent hashing ring with the number of virtual nodes per shard
+// and per unit of node weight. It must be the same on all the nodes of the cluster.
+func CreateRing(virtualNodes int) *ring {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	return &ring{
+		dht:          Create(),
+		virtualNodes: virtualNodes,
+	}
+}
+
+func (r *ring) GetShard(key string) (ShardLocation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.points) == 0 {
+		return ShardLocation{}, ErrDHTNotInitialised
+	}
+
+	return r.dht.GetShardLocation(r.points[successor(r.points, xxhash.Sum64String(key))].shardID)
+}
+
+func (r *ring) Load(shards map[ShardID]ShardLocation, epoch uint64) {
+	points := make([]ringPoint, 0, len(shards)*r.virtualNodes)
+	for shardID := range shards {
+		for i := 0; i < r.virtualNodes; i++ {
+			points = append(points, ringPoint{hash: hashPoint("shard-"+strconv.Itoa(int(shardID)), i), shardID: shardID})
+		}
+	}
+	sortPoints(points)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dht.Load(shards, epoch)
+	r.points = points
+}
+
+// Place returns the shard map of the shards on the nodes. Every node is placed on a ring at virtualNodes
+// points per unit of its weight, and the owners of a shard are the first distinct nodes after the hash of the shard.
+// The first owner leads the shard. Adding or removing a node only moves the shards it owns or will own.
+func (r *ring) Place(shardIDs []ShardID, nodes []Node, replicas int) (map[ShardID]ShardLocation, error) {
+	if replicas < 1 {
+		// Every shard has a leader
+		replicas = 1
+	}
+
+	distinct := make(map[NodeID]bool, len(nodes))
+	points := []ringPoint{}
+	for _, node := range nodes {
+		if distinct[node.ID] {
+			continue
+		}
+		distinct[node.ID] = true
+
+		weight := node.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for i := 0; i < r.virtualNodes*weight; i++ {
+			points = append(points, ringPoint{hash: hashPoint("node-"+string(node.ID), i), nodeID: node.ID})
+		}
+	}
+
+	if len(distinct) < replicas {
+		return nil, ErrReplicasLessThanNodes
+	}
+	sortPoints(points)
+
+	shards := make(map[ShardID]ShardLocation, len(shardIDs))
+	for _, shardID := range shardIDs {
+		owners := make([]NodeID, 0, replicas)
+		start := successor(points, xxhash.Sum64String("shard-"+strconv.Itoa(int(shardID))))
+		for i := 0; len(owners) < replicas; i++ {
+			node := points[(start+i)%len(points)].nodeID
+			if !containsNode(owners, node) {
+				owners = append(owners, node)
+			}
+		}
+
+		shard := ShardLocation{
+			ID:        shardID,
+			Leader:    NodeDetails{ID: owners[0]},
+			Followers: []NodeDetails{},
+		}
+		for _, node := range owners[1:] {
+			shard.Followers = append(shard.Followers, NodeDetails{ID: node})
+		}
+		shards[shardID] = shard
+	}
+
+	return shards, nil
+}
+
+// successor returns the index of the first point at or after the hash, wrapping around the ring
+func successor(points []ringPoint, hash uint64) int {
+	i := sort.Search(len(points), func(i int) bool { return points[i].hash >= hash })
+	if i == len(points) {
+		return 0
+	}
+
+	return i
+}
+
+func hashPoint(name string, i int) uint64 {
+	return xxhash.Sum64String(name + "-" + strconv.Itoa(i))
+}
+
+// sortPoints sorts the points by their hash. Points with the same hash are ordered by their shard and node,
+// so that all the nodes build the same ring
+func sortPoints(points []ringPoint) {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		if points[i].shardID != points[j].shardID {
+			return points[i].shardID < points[j].shardID
+		}
+		return points[i].nodeID < points[j].nodeID
+	})
+}
@@ -0,0 +1,155 @@
+package dht
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func createRingShards(shardCount int) map[ShardID]ShardLocation {
+	shards := make(map[ShardID]ShardLocation, shardCount)
+	for i := 0; i < shardCount; i++ {
+		shards[ShardID(i)] = ShardLocation{ID: ShardID(i), Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}}
+	}
+
+	return shards
+}
+
+func getShardIDs(shardCount int) []ShardID {
+	shardIDs := make([]ShardID, 0, shardCount)
+	for i := 0; i < shardCount; i++ {
+		shardIDs = append(shardIDs, ShardID(i))
+	}
+
+	return shardIDs
+}
+
+func TestRingGetShard(t *testing.T) {
+	r := CreateRing(DefaultVirtualNodes)
+	if _, err := r.GetShard("ABCD"); err != ErrDHTNotInitialised {
+		t.Errorf("GetShard() on an empty ring error = %v, want %v", err, ErrDHTNotInitialised)
+	}
+
+	const keys = 10000
+	tests := []struct {
+		name         string
+		shards       int
+		newShards    int
+		maxMovedKeys int
+	}{
+		{name: "same shards", shards: 16, newShards: 16, maxMovedKeys: 0},
+		{name: "shard added", shards: 16, newShards: 17, maxMovedKeys: 2 * keys / 17},
+		{name: "shard removed", shards: 17, newShards: 16, maxMovedKeys: 2 * keys / 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, next := CreateRing(DefaultVirtualNodes), CreateRing(DefaultVirtualNodes)
+			current.Load(createRingShards(tt.shards), 1)
+			next.Load(createRingShards(tt.newShards), 2)
+
+			moved := 0
+			for i := 0; i < keys; i++ {
+				key := "job" + strconv.Itoa(i)
+				before, err := current.GetShard(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				after, err := next.GetShard(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if before.ID != after.ID {
+					moved++
+
+					// Keys only move to the added shard or from the removed shard
+					if int(after.ID) < tt.shards && int(before.ID) < tt.newShards {
+						t.Fatalf("key %s moved from shard %d to %d", key, before.ID, after.ID)
+					}
+				}
+			}
+
+			if moved > tt.maxMovedKeys {
+				t.Errorf("moved %d keys, want at most %d", moved, tt.maxMovedKeys)
+			}
+		})
+	}
+}
+
+func TestRingPlace(t *testing.T) {
+	r := CreateRing(DefaultVirtualNodes)
+	shardIDs := getShardIDs(64)
+
+	tests := []struct {
+		name     string
+		nodes    []Node
+		newNodes []Node
+		changed  NodeID
+	}{
+		{
+			name:     "no change",
+			nodes:    []Node{{ID: "node1"}, {ID: "node2"}, {ID: "node3"}},
+			newNodes: []Node{{ID: "node1"}, {ID: "node2"}, {ID: "node3"}},
+		},
+		{
+			name:     "node added",
+			nodes:    []Node{{ID: "node1"}, {ID: "node2"}, {ID: "node3"}},
+			newNodes: []Node{{ID: "node1"}, {ID: "node2"}, {ID: "node3"}, {ID: "node4"}},
+			changed:  "node4",
+		},
+		{
+			name:     "node removed",
+			nodes:    []Node{{ID: "node1"}, {ID: "node2"}, {ID: "node3"}, {ID: "node4"}},
+			newNodes: []Node{{ID: "node1"}, {ID: "node2"}, {ID: "node3"}},
+			changed:  "node4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, err := r.Place(shardIDs, tt.nodes, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			placed, err := r.Place(shardIDs, tt.newNodes, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, shardID := range shardIDs {
+				before, after := current[shardID].GetOwners(), placed[shardID].GetOwners()
+				if len(after) != 2 {
+					t.Fatalf("shard %d has owners %v, want 2", shardID, after)
+				}
+
+				// Only the shards owned by the added or removed node are moved
+				if !reflect.DeepEqual(before, after) && !containsNode(before, tt.changed) && !containsNode(after, tt.changed) {
+					t.Errorf("shard %d moved from %v to %v", shardID, before, after)
+				}
+			}
+		})
+	}
+}
+
+func TestRingPlaceWeights(t *testing.T) {
+	r := CreateRing(DefaultVirtualNodes)
+
+	placed, err := r.Place(getShardIDs(256), []Node{{ID: "node1", Weight: 3}, {ID: "node2"}, {ID: "node3"}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	load := map[NodeID]int{}
+	for _, shard := range placed {
+		load[shard.Leader.ID]++
+	}
+	// The node of weight 3 is expected to lead three times as many shards
+	if load["node1"] < 2*load["node2"] || load["node1"] < 2*load["node3"] {
+		t.Errorf("shards led by the nodes = %v, want node1 to lead more than twice the others", load)
+	}
+
+	if _, err := r.Place(getShardIDs(4), []Node{{ID: "node1"}}, 2); err != ErrReplicasLessThanNodes {
+		t.Errorf("Place() error = %v, want %v", err, ErrReplicasLessThanNodes)
+	}
+}
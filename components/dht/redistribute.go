@@ -0,0 +1,173 @@
+package dht
+
+import "sort"
+
+// Redistribute returns a new shard map for the given nodes with as little movement as possible.
+//
+// Replicas on the nodes that are still present stay where they are, unless the node holds more than
+// its fair share of replicas. The missing replicas are added to the least loaded nodes. The leader of
+// a shard is changed only if its node is removed or leads more shards than the other replicas of the shard.
+// The number of shards does not change, as the hash slot of a key depends on it.
+func Redistribute(shards map[ShardID]ShardLocation, nodes []string, replicas int) (map[ShardID]ShardLocation, error) {
+	if len(shards) == 0 {
+		return nil, ErrDHTNotInitialised
+	}
+
+	if len(nodes) < replicas {
+		return nil, ErrReplicasLessThanNodes
+	}
+
+	present := make(map[NodeID]bool, len(nodes))
+	sortedNodes := make([]NodeID, 0, len(nodes))
+	for _, node := range nodes {
+		if !present[NodeID(node)] {
+			present[NodeID(node)] = true
+			sortedNodes = append(sortedNodes, NodeID(node))
+		}
+	}
+	sort.Slice(sortedNodes, func(i, j int) bool { return sortedNodes[i] < sortedNodes[j] })
+
+	shardIDs := make([]ShardID, 0, len(shards))
+	for shardID := range shards {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	// Owners of every shard with the leader first. Only the nodes that are still present are retained
+	owners := make(map[ShardID][]NodeID, len(shards))
+	load := make(map[NodeID]int, len(sortedNodes))
+	for _, shardID := range shardIDs {
+		shard := shards[shardID]
+		for _, node := range append([]NodeDetails{shard.Leader}, shard.Followers...) {
+			if len(owners[shardID]) == replicas {
+				break
+			}
+			if present[node.ID] && !containsNode(owners[shardID], node.ID) {
+				owners[shardID] = append(owners[shardID], node.ID)
+				load[node.ID]++
+			}
+		}
+	}
+
+	// Remove the replicas from the nodes holding more than their fair share.
+	// Followers are removed before leaders
+	maxLoad := ceilDiv(len(shards)*replicas, len(sortedNodes))
+	for _, followersOnly := range []bool{true, false} {
+		for _, shardID := range shardIDs {
+			for i := len(owners[shardID]) - 1; i >= 0; i-- {
+				if followersOnly && i == 0 {
+					continue
+				}
+
+				node := owners[shardID][i]
+				if load[node] > maxLoad {
+					owners[shardID] = append(owners[shardID][:i], owners[shardID][i+1:]...)
+					load[node]--
+				}
+			}
+		}
+	}
+
+	// Add the missing replicas to the least loaded nodes
+	for _, shardID := range shardIDs {
+		for len(owners[shardID]) < replicas {
+			var selected NodeID
+			for _, node := range sortedNodes {
+				if containsNode(owners[shardID], node) {
+					continue
+				}
+				if selected == "" || load[node] < load[selected] {
+					selected = node
+				}
+			}
+
+			owners[shardID] = append(owners[shardID], selected)
+			load[selected]++
+		}
+	}
+
+	// The first owner is the existing leader if it is still present
+	leaderOf := make(map[ShardID]NodeID, len(shards))
+	leaderCount := make(map[NodeID]int, len(sortedNodes))
+	for _, shardID := range shardIDs {
+		leaderOf[shardID] = owners[shardID][0]
+		leaderCount[owners[shardID][0]]++
+	}
+
+	// Move the leadership to another replica of the shard as long as it reduces the imbalance
+	for moved := true; moved; {
+		moved = false
+		for _, shardID := range shardIDs {
+			leader := leaderOf[shardID]
+			for _, node := range owners[shardID] {
+				if leaderCount[leader]-leaderCount[node] > 1 {
+					leaderCount[leader]--
+					leaderCount[node]++
+					leaderOf[shardID] = node
+					moved = true
+					break
+				}
+			}
+		}
+	}
+
+	redistributed := make(map[ShardID]ShardLocation, len(shards))
+	for _, shardID := range shardIDs {
+		shard := ShardLocation{
+			ID:        shardID,
+			Leader:    NodeDetails{ID: leaderOf[shardID]},
+			Followers: []NodeDetails{},
+		}
+		for _, node := range owners[shardID] {
+			if node != leaderOf[shardID] {
+				shard.Followers = append(shard.Followers, NodeDetails{ID: node})
+			}
+		}
+
+		redistributed[shardID] = shard
+	}
+
+	return redistributed, nil
+}
+
+// GetAddedOwners returns the nodes that own a shard in the new shard map, but not in the current one
+func GetAddedOwners(current, redistributed map[ShardID]ShardLocation) map[ShardID][]NodeID {
+	added := make(map[ShardID][]NodeID)
+	for shardID, shard := range redistributed {
+		currentOwners := current[shardID].GetOwners()
+		for _, node := range shard.GetOwners() {
+			if !containsNode(currentOwners, node) {
+				added[shardID] = append(added[shardID], node)
+			}
+		}
+	}
+
+	return added
+}
+
+// GetOwners returns the leader followed by the followers of the shard
+func (sl ShardLocation) GetOwners() []NodeID {
+	owners := make([]NodeID, 0, len(sl.Followers)+1)
+	if sl.Leader.ID != "" {
+		owners = append(owners, sl.Leader.ID)
+	}
+	for _, follower := range sl.Followers {
+		owners = append(owners, follower.ID)
+	}
+
+	return owners
+}
+
+func containsNode(nodes []NodeID, node NodeID) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
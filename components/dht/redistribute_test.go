@@ -0,0 +1,93 @@
+package dht
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedistribute(t *testing.T) {
+	tests := []struct {
+		name      string
+		nodes     []string
+		newNodes  []string
+		wantMoved int
+	}{
+		{
+			name:      "no change",
+			nodes:     []string{"node1", "node2", "node3"},
+			newNodes:  []string{"node1", "node2", "node3"},
+			wantMoved: 0,
+		},
+		{
+			name:      "node added",
+			nodes:     []string{"node1", "node2", "node3"},
+			newNodes:  []string{"node1", "node2", "node3", "node4"},
+			wantMoved: 6, // node4 gets its fair share of 24 replicas
+		},
+		{
+			name:      "node removed",
+			nodes:     []string{"node1", "node2", "node3", "node4"},
+			newNodes:  []string{"node1", "node2", "node3"},
+			wantMoved: 6, // Only the replicas on node4 are moved
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, err := InitialiseDHT(12, tt.nodes, 2)
+			if err != nil {
+				t.Fatalf("Failed to initialise dht: %v", err)
+			}
+
+			redistributed, err := Redistribute(current, tt.newNodes, 2)
+			if err != nil {
+				t.Fatalf("Failed to redistribute: %v", err)
+			}
+
+			moved := 0
+			for _, nodes := range GetAddedOwners(current, redistributed) {
+				moved += len(nodes)
+			}
+			if moved != tt.wantMoved {
+				t.Errorf("Unexpected replicas moved: got %d, want %d", moved, tt.wantMoved)
+			}
+
+			replicas := make(map[NodeID]int)
+			leaders := make(map[NodeID]int)
+			for shardID, shard := range redistributed {
+				owners := shard.GetOwners()
+				if len(owners) != 2 || owners[0] == owners[1] {
+					t.Errorf("Shard %d has invalid owners: %v", shardID, owners)
+				}
+				for _, node := range owners {
+					replicas[node]++
+				}
+				leaders[shard.Leader.ID]++
+			}
+
+			wantReplicas, wantLeaders := 24/len(tt.newNodes), 12/len(tt.newNodes)
+			for _, node := range tt.newNodes {
+				if replicas[NodeID(node)] != wantReplicas {
+					t.Errorf("Unexpected replicas on %s: got %d, want %d", node, replicas[NodeID(node)], wantReplicas)
+				}
+				if leaders[NodeID(node)] != wantLeaders {
+					t.Errorf("Unexpected leaders on %s: got %d, want %d", node, leaders[NodeID(node)], wantLeaders)
+				}
+			}
+
+			if tt.wantMoved == 0 && !reflect.DeepEqual(current, redistributed) {
+				t.Errorf("Expected shards to be unchanged, got %v", redistributed)
+			}
+		})
+	}
+}
+
+func TestRedistributeLessNodes(t *testing.T) {
+	current, err := InitialiseDHT(4, []string{"node1", "node2", "node3"}, 3)
+	if err != nil {
+		t.Fatalf("Failed to initialise dht: %v", err)
+	}
+
+	if _, err = Redistribute(current, []string{"node1", "node2"}, 3); err != ErrReplicasLessThanNodes {
+		t.Errorf("Expected %v, got %v", ErrReplicasLessThanNodes, err)
+	}
+}
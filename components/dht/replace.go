@@ -0,0 +1,236 @@
+package dht
+
+import "sort"
+
+// ReplaceNode returns a new shard map with the failed node removed from the owners of every shard,
+// along with the spare nodes added to restore the replicas of the shards and the shards that are unavailable.
+//
+// The shards led by the failed node are led by the healthy follower chosen for them in leaders. A spare is chosen for
+// every shard that lost a replica, from the healthy nodes that do not own the shard and are not drained, with the least
+// loaded node first. The spare is added as a joining node and becomes a follower once it has copied the shard, see PromoteJoining.
+// A shard without a leader chosen from its healthy followers is left as is and reported as unavailable.
+func ReplaceNode(shards map[ShardID]ShardLocation, failed NodeID, healthy []NodeID, leaders map[ShardID]NodeID, drained []NodeID) (map[ShardID]ShardLocation, map[ShardID]NodeID, []ShardID) {
+	isHealthy := make(map[NodeID]bool, len(healthy))
+	for _, node := range healthy {
+		if node != failed {
+			isHealthy[node] = true
+		}
+	}
+
+	shardIDs := make([]ShardID, 0, len(shards))
+	for shardID := range shards {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	replaced := make(map[ShardID]ShardLocation, len(shards))
+	lostReplica := []ShardID{}
+	unavailable := []ShardID{}
+	for _, shardID := range shardIDs {
+		shard := shards[shardID]
+		lost := false
+
+		followers := []NodeDetails{}
+		for _, follower := range shard.Followers {
+			if follower.ID == failed {
+				lost = true
+				continue
+			}
+			followers = append(followers, follower)
+		}
+
+		var joining []NodeDetails
+		for _, node := range shard.Joining {
+			if node.ID == failed {
+				lost = true
+				continue
+			}
+			joining = append(joining, node)
+		}
+
+		leader := shard.Leader
+		if leader.ID == failed {
+			next := -1
+			for i, follower := range followers {
+				if follower.ID == leaders[shardID] && isHealthy[follower.ID] {
+					next = i
+					break
+				}
+			}
+
+			if next < 0 {
+				// None of the replicas can lead the shard
+				replaced[shardID] = shard
+				unavailable = append(unavailable, shardID)
+				continue
+			}
+
+			lost = true
+			leader = followers[next]
+			followers = append(followers[:next], followers[next+1:]...)
+		}
+
+		replaced[shardID] = ShardLocation{
+			ID:        shardID,
+			Leader:    leader,
+			Followers: followers,
+			Joining:   joining,
+		}
+		if lost {
+			lostReplica = append(lostReplica, shardID)
+		}
+	}
+
+	// Number of shards owned by every healthy node
+	load := make(map[NodeID]int, len(isHealthy))
+	for _, shard := range replaced {
+		for _, node := range shard.getAllNodes() {
+			if isHealthy[node] {
+				load[node]++
+			}
+		}
+	}
+
+	spares := make([]NodeID, 0, len(isHealthy))
+	for node := range isHealthy {
+		if !containsNode(drained, node) {
+			spares = append(spares, node)
+		}
+	}
+	sort.Slice(spares, func(i, j int) bool { return spares[i] < spares[j] })
+
+	added := make(map[ShardID]NodeID)
+	for _, shardID := range lostReplica {
+		shard := replaced[shardID]
+		owners := shard.getAllNodes()
+
+		var selected NodeID
+		for _, node := range spares {
+			if containsNode(owners, node) {
+				continue
+			}
+			if selected == "" || load[node] < load[selected] {
+				selected = node
+			}
+		}
+		if selected == "" {
+			// All the healthy nodes already own the shard
+			continue
+		}
+
+		shard.Joining = append(shard.Joining, NodeDetails{ID: selected})
+		replaced[shardID] = shard
+		added[shardID] = selected
+		load[selected]++
+	}
+
+	return replaced, added, unavailable
+}
+
+// PromoteJoining returns a copy of the shard map with the joining nodes made followers of the shards.
+// The nodes that are no longer joining a shard are ignored.
+func PromoteJoining(shards map[ShardID]ShardLocation, promoted map[ShardID]NodeID) map[ShardID]ShardLocation {
+	m := make(map[ShardID]ShardLocation, len(shards))
+	for shardID, shard := range shards {
+		node, ok := promoted[shardID]
+		if !ok {
+			m[shardID] = shard
+			continue
+		}
+
+		followers := append([]NodeDetails{}, shard.Followers...)
+		var joining []NodeDetails
+		for _, n := range shard.Joining {
+			if n.ID == node {
+				followers = append(followers, n)
+				continue
+			}
+			joining = append(joining, n)
+		}
+
+		shard.Followers = followers
+		shard.Joining = joining
+		m[shardID] = shard
+	}
+
+	return m
+}
+
+// RejoinNode returns a copy of the shard map with the node added as a joining node of the shards, along with the shards
+// it was added to. It is used when a node that was replaced after a failure comes back. The shards that do not exist
+// or that the node already owns are skipped.
+func RejoinNode(shards map[ShardID]ShardLocation, node NodeID, shardIDs []ShardID) (map[ShardID]ShardLocation, []ShardID) {
+	m := make(map[ShardID]ShardLocation, len(shards))
+	for shardID, shard := range shards {
+		m[shardID] = shard
+	}
+
+	added := []ShardID{}
+	for _, shardID := range shardIDs {
+		shard, ok := m[shardID]
+		if !ok || containsNode(shard.getAllNodes(), node) {
+			continue
+		}
+
+		shard.Joining = append(append([]NodeDetails{}, shard.Joining...), NodeDetails{ID: node})
+		m[shardID] = shard
+		added = append(added, shardID)
+	}
+
+	return m, added
+}
+
+// RemoveSpares returns a copy of the shard map with the spares removed from the followers and the joining nodes
+// of the shards. A spare that leads its shard is kept, as its leadership has to be handed over first.
+func RemoveSpares(shards map[ShardID]ShardLocation, spares map[ShardID]NodeID) map[ShardID]ShardLocation {
+	m := make(map[ShardID]ShardLocation, len(shards))
+	for shardID, shard := range shards {
+		m[shardID] = shard
+
+		spare, ok := spares[shardID]
+		if !ok || shard.Leader.ID == spare {
+			continue
+		}
+
+		followers := []NodeDetails{}
+		for _, n := range shard.Followers {
+			if n.ID != spare {
+				followers = append(followers, n)
+			}
+		}
+
+		var joining []NodeDetails
+		for _, n := range shard.Joining {
+			if n.ID != spare {
+				joining = append(joining, n)
+			}
+		}
+
+		shard.Followers = followers
+		shard.Joining = joining
+		m[shardID] = shard
+	}
+
+	return m
+}
+
+// IsJoining returns true if the node is copying the shard
+func (sl ShardLocation) IsJoining(nodeID NodeID) bool {
+	for _, node := range sl.Joining {
+		if node.ID == nodeID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getAllNodes returns the owners of the shard along with the joining nodes
+func (sl ShardLocation) getAllNodes() []NodeID {
+	nodes := sl.GetOwners()
+	for _, node := range sl.Joining {
+		nodes = append(nodes, node.ID)
+	}
+
+	return nodes
+}
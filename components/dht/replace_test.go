@@ -0,0 +1,176 @@
+package dht
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplaceNode(t *testing.T) {
+	shards := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{{ID: "node2"}, {ID: "node3"}}},
+		1: {ID: 1, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node1"}}},
+		2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node4"}}},
+		3: {ID: 3, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}},
+	}
+
+	tests := []struct {
+		name            string
+		healthy         []NodeID
+		leaders         map[ShardID]NodeID
+		drained         []NodeID
+		wantShards      map[ShardID]ShardLocation
+		wantAdded       map[ShardID]NodeID
+		wantUnavailable []ShardID
+	}{
+		{
+			name:    "spare available",
+			healthy: []NodeID{"node2", "node3", "node4"},
+			leaders: map[ShardID]NodeID{0: "node2"},
+			wantShards: map[ShardID]ShardLocation{
+				0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}}, Joining: []NodeDetails{{ID: "node4"}}},
+				1: {ID: 1, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{}, Joining: []NodeDetails{{ID: "node3"}}},
+				2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node4"}}},
+				3: {ID: 3, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}},
+			},
+			wantAdded:       map[ShardID]NodeID{0: "node4", 1: "node3"}, // The least loaded nodes
+			wantUnavailable: []ShardID{3},
+		},
+		{
+			name:    "chosen follower is promoted",
+			healthy: []NodeID{"node2", "node3", "node4"},
+			leaders: map[ShardID]NodeID{0: "node3"},
+			wantShards: map[ShardID]ShardLocation{
+				0: {ID: 0, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node2"}}, Joining: []NodeDetails{{ID: "node4"}}},
+				1: {ID: 1, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{}, Joining: []NodeDetails{{ID: "node3"}}},
+				2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node4"}}},
+				3: {ID: 3, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}},
+			},
+			wantAdded:       map[ShardID]NodeID{0: "node4", 1: "node3"},
+			wantUnavailable: []ShardID{3},
+		},
+		{
+			name:    "unhealthy follower is not promoted",
+			healthy: []NodeID{"node3", "node4"},
+			leaders: map[ShardID]NodeID{0: "node2"},
+			wantShards: map[ShardID]ShardLocation{
+				0: shards[0],
+				1: {ID: 1, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{}, Joining: []NodeDetails{{ID: "node4"}}},
+				2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node4"}}},
+				3: {ID: 3, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}},
+			},
+			wantAdded:       map[ShardID]NodeID{1: "node4"},
+			wantUnavailable: []ShardID{0, 3},
+		},
+		{
+			name:    "drained node is not a spare",
+			healthy: []NodeID{"node2", "node3", "node4"},
+			leaders: map[ShardID]NodeID{0: "node2"},
+			drained: []NodeID{"node4"},
+			wantShards: map[ShardID]ShardLocation{
+				0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}}},
+				1: {ID: 1, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{}, Joining: []NodeDetails{{ID: "node3"}}},
+				2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node4"}}},
+				3: {ID: 3, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}},
+			},
+			wantAdded:       map[ShardID]NodeID{1: "node3"},
+			wantUnavailable: []ShardID{3},
+		},
+		{
+			name:    "no spare",
+			healthy: []NodeID{"node2"},
+			leaders: map[ShardID]NodeID{0: "node2"},
+			wantShards: map[ShardID]ShardLocation{
+				0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}}},
+				1: {ID: 1, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{}},
+				2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node4"}}},
+				3: {ID: 3, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}},
+			},
+			wantAdded:       map[ShardID]NodeID{},
+			wantUnavailable: []ShardID{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, added, unavailable := ReplaceNode(shards, "node1", tt.healthy, tt.leaders, tt.drained)
+			if !reflect.DeepEqual(got, tt.wantShards) {
+				t.Errorf("ReplaceNode() shards = %v, want %v", got, tt.wantShards)
+			}
+			if !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("ReplaceNode() added = %v, want %v", added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(unavailable, tt.wantUnavailable) {
+				t.Errorf("ReplaceNode() unavailable = %v, want %v", unavailable, tt.wantUnavailable)
+			}
+		})
+	}
+}
+
+func TestPromoteJoining(t *testing.T) {
+	shards := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}}, Joining: []NodeDetails{{ID: "node4"}}},
+		1: {ID: 1, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{}, Joining: []NodeDetails{{ID: "node4"}}},
+	}
+
+	got := PromoteJoining(shards, map[ShardID]NodeID{0: "node4"})
+	want := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}, {ID: "node4"}}},
+		1: shards[1],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PromoteJoining() = %v, want %v", got, want)
+	}
+
+	// The joining nodes open the shards to copy them
+	d := Create()
+	d.Load(shards, 1)
+	if got := len(d.GetAllShardsForNode("node4")); got != 2 {
+		t.Errorf("joining node has %d shards, want 2", got)
+	}
+}
+
+func TestRejoinNode(t *testing.T) {
+	shards := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}}},
+		1: {ID: 1, Leader: NodeDetails{ID: "node1"}, Followers: []NodeDetails{}},
+		2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{}, Joining: []NodeDetails{{ID: "node4"}}},
+	}
+
+	// node1 still leads shard 1 as it was unavailable, and shard 5 does not exist
+	got, added := RejoinNode(shards, "node1", []ShardID{0, 1, 2, 5})
+	want := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}}, Joining: []NodeDetails{{ID: "node1"}}},
+		1: shards[1],
+		2: {ID: 2, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{}, Joining: []NodeDetails{{ID: "node4"}, {ID: "node1"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RejoinNode() = %v, want %v", got, want)
+	}
+	if wantAdded := []ShardID{0, 2}; !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("RejoinNode() added = %v, want %v", added, wantAdded)
+	}
+	if len(shards[2].Joining) != 1 {
+		t.Errorf("RejoinNode() changed the shard map passed to it")
+	}
+}
+
+func TestRemoveSpares(t *testing.T) {
+	shards := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}, {ID: "node1"}}},
+		1: {ID: 1, Leader: NodeDetails{ID: "node3"}, Followers: []NodeDetails{{ID: "node1"}}},
+		2: {ID: 2, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node1"}}, Joining: []NodeDetails{{ID: "node3"}}},
+		3: {ID: 3, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node3"}}},
+	}
+
+	// The spare of shard 1 leads it, hence it is kept
+	got := RemoveSpares(shards, map[ShardID]NodeID{0: "node3", 1: "node3", 2: "node3"})
+	want := map[ShardID]ShardLocation{
+		0: {ID: 0, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node1"}}},
+		1: shards[1],
+		2: {ID: 2, Leader: NodeDetails{ID: "node2"}, Followers: []NodeDetails{{ID: "node1"}}},
+		3: shards[3],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RemoveSpares() = %v, want %v", got, want)
+	}
+}
@@ -7,8 +7,10 @@ import (
 
 type jobList []*jobEntry
 
+// Less orders the jobs by the time they have to be published at, which is the time of the
+// next retry for the jobs being retried
 func (jq jobList) Less(i, j int) bool {
-	return jq[i].job.TriggerMS < jq[j].job.TriggerMS
+	return jq[i].job.GetTriggerTime().Before(jq[j].job.GetTriggerTime())
 }
 
 func (jq jobList) Len() int {
@@ -21,6 +21,11 @@ type Executor interface {
 	// When the job is ready to be executed, it will be sent to the job channel.
 	Queue(job jobmodels.Job) error
 
+	// QueueOverdue adds a job whose trigger time has already passed to the execution queue.
+	// It is sent to the job channel on the next tick. It is used to recover the jobs that
+	// were due when the shard leader changed.
+	QueueOverdue(job jobmodels.Job) error
+
 	// Delete deletes the queued job.
 	// If the job is not queued, it will return ErrJobNotFound
 	Delete(jobID string) error
@@ -28,6 +33,10 @@ type Executor interface {
 	// returns the job with the given jobID.
 	GetJob(jobId string) (job *jobmodels.Job, version int, deleted bool, err error)
 
+	// Len returns the number of entries in the execution queue.
+	// The entries of the jobs that are updated or deleted are counted until they are popped.
+	Len() int
+
 	// Close closes the executor and waits for all the jobs to finish executing.
 	Close()
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/aarthikrao/timeMachine/models/jobmodels"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
 )
 
 var (
@@ -97,6 +98,7 @@ func (e *executorImpl) Queue(job jobmodels.Job) error {
 		}
 		e.jobs[job.ID] = entry
 		e.jobQueue.AddJob(&entry)
+		metrics.JobsQueued.Inc()
 
 	} else if exists && !inGracePeriod {
 		// This means the updated trigger time of the job doesnt lie within the graceperiod
@@ -110,11 +112,39 @@ func (e *executorImpl) Queue(job jobmodels.Job) error {
 		entry.job = &job
 		e.jobs[job.ID] = entry
 		e.jobQueue.AddJob(&entry)
+		metrics.JobsQueued.Inc()
 	}
 
 	return nil
 }
 
+func (e *executorImpl) QueueOverdue(job jobmodels.Job) error {
+	if e.isClosed {
+		return ErrExecutorIsClosed
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entry, exists := e.jobs[job.ID]
+	if exists {
+		// Only the latest version of the job is dispatched
+		entry.version++
+		entry.deleted = false
+	}
+	entry.job = &job
+
+	e.jobs[job.ID] = entry
+	e.jobQueue.AddJob(&entry)
+	metrics.JobsQueued.Inc()
+
+	return nil
+}
+
+func (e *executorImpl) Len() int {
+	return e.jobQueue.Len()
+}
+
 func (e *executorImpl) Delete(jobId string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -127,3 +127,84 @@ func TestQueueAndWait(t *testing.T) {
 	}
 
 }
+
+func TestQueueOverdue(t *testing.T) {
+	jobCh := make(chan *jobmodels.Job)
+	gracePeriod := 15 * time.Second // Tests time out after 30 seconds
+	accuracy := 50 * time.Millisecond
+
+	executor := NewExecutor(jobCh, gracePeriod, accuracy)
+
+	j := jobmodels.Job{
+		ID:        "job1",
+		TriggerMS: int(time.Now().Add(-30 * time.Second).UnixMilli()),
+		Route:     "route2",
+	}
+
+	if err := executor.Queue(j); err != ErrTooLate {
+		t.Errorf("Expected error: %v, got: %v", ErrTooLate, err)
+	}
+
+	if err := executor.QueueOverdue(j); err != nil {
+		t.Errorf("Failed to queue overdue job: %v", err)
+	}
+
+	select {
+	case recievedJob := <-jobCh:
+		if recievedJob.ID != j.ID {
+			t.Errorf("Unexpected job ID: got %s, want %s", recievedJob.ID, j.ID)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Overdue job was not dispatched")
+	}
+
+	executor.Close()
+}
+
+func TestQueueRetryingJob(t *testing.T) {
+	jobCh := make(chan *jobmodels.Job, 2)
+	gracePeriod := 15 * time.Second // Tests time out after 30 seconds
+	accuracy := 50 * time.Millisecond
+
+	executor := NewExecutor(jobCh, gracePeriod, accuracy)
+
+	// The retrying job was triggered before the scheduled job, but it is retried after it
+	retrying := jobmodels.Job{
+		ID:            "job1",
+		TriggerMS:     int(time.Now().Add(100 * time.Millisecond).UnixMilli()),
+		State:         jobmodels.ExecutionRetrying,
+		NextAttemptMS: int(time.Now().Add(3 * time.Second).UnixMilli()),
+		Route:         "route2",
+	}
+	scheduled := jobmodels.Job{
+		ID:        "job2",
+		TriggerMS: int(time.Now().Add(200 * time.Millisecond).UnixMilli()),
+		Route:     "route2",
+	}
+
+	for _, j := range []jobmodels.Job{retrying, scheduled} {
+		if err := executor.Queue(j); err != nil {
+			t.Errorf("Failed to queue job: %v", err)
+		}
+	}
+
+	select {
+	case recievedJob := <-jobCh:
+		if recievedJob.ID != scheduled.ID {
+			t.Errorf("Unexpected job ID: got %s, want %s", recievedJob.ID, scheduled.ID)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Scheduled job was held back by the retrying job")
+	}
+
+	select {
+	case recievedJob := <-jobCh:
+		if recievedJob.ID != retrying.ID {
+			t.Errorf("Unexpected job ID: got %s, want %s", recievedJob.ID, retrying.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Retrying job was not dispatched")
+	}
+
+	executor.Close()
+}
@@ -0,0 +1,243 @@
+// Package failuredetector implements the phi accrual failure detector.
+//
+// Instead of marking a node failed after a fixed number of missed heartbeats, the detector learns the
+// distribution of the intervals between the heartbeats of every node. Phi is the suspicion that the node
+// has failed given the time since its last heartbeat, on a log10 scale: a phi of 1 means a 10% chance that
+// the heartbeat is only late, 2 means 1%, 3 means 0.1% and so on. The detector adapts to slow networks and
+// busy nodes, as their heartbeats arrive with larger intervals.
+package failuredetector
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+// The thresholds are not positive, or the suspect threshold is above the failure threshold
+var ErrInvalidThresholds = errors.New("invalid suspicion thresholds")
+
+// Status is the state of a node as per its phi
+type Status string
+
+const (
+	StatusHealthy Status = "healthy"
+
+	// Phi is at or above the suspect threshold. The node is not replaced yet
+	StatusSuspect Status = "suspect"
+
+	// Phi is at or above the failure threshold
+	StatusFailed Status = "failed"
+)
+
+type Config struct {
+	// Phi at which a node is suspected
+	SuspectThreshold float64
+
+	// Phi at which a node is considered failed
+	FailureThreshold float64
+
+	// Number of the latest heartbeat intervals used to estimate their distribution
+	MaxSamples int
+
+	// Lower bound of the standard deviation of the intervals, so that a node with very regular heartbeats
+	// is not considered failed when a heartbeat is slightly late
+	MinStdDeviation time.Duration
+
+	// Delay of the heartbeats that is tolerated on top of their mean interval, for example during a GC pause
+	AcceptablePause time.Duration
+
+	// Expected interval of the heartbeats, used till the intervals of a node are observed
+	FirstHeartbeatEstimate time.Duration
+}
+
+// DefaultConfig returns the config for heartbeats sent every interval
+func DefaultConfig(interval time.Duration) Config {
+	return Config{
+		SuspectThreshold:       5,
+		FailureThreshold:       8,
+		MaxSamples:             1000,
+		MinStdDeviation:        interval / 2,
+		AcceptablePause:        interval,
+		FirstHeartbeatEstimate: interval,
+	}
+}
+
+// Validate returns ErrInvalidThresholds if the thresholds are not usable
+func (c Config) Validate() error {
+	if c.SuspectThreshold <= 0 || c.FailureThreshold <= 0 || c.SuspectThreshold > c.FailureThreshold {
+		return ErrInvalidThresholds
+	}
+
+	return nil
+}
+
+// NodeState is the suspicion level of a node
+type NodeState struct {
+	NodeID dht.NodeID `json:"node_id"`
+	Phi    float64    `json:"phi"`
+	Status Status     `json:"status"`
+
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+
+	// Mean interval between the heartbeats of the node
+	MeanInterval time.Duration `json:"mean_interval"`
+}
+
+// Detector tracks the heartbeats of the nodes. It is safe for concurrent use
+type Detector struct {
+	cfg Config
+
+	mu    sync.Mutex
+	nodes map[dht.NodeID]*history
+}
+
+// history holds the latest heartbeat intervals of a node in milliseconds
+type history struct {
+	intervals []float64
+	sum       float64
+	sumSq     float64
+
+	lastHeartbeat time.Time
+}
+
+func CreateDetector(cfg Config) (*Detector, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.MaxSamples < 2 {
+		cfg.MaxSamples = 2
+	}
+
+	return &Detector{
+		cfg:   cfg,
+		nodes: make(map[dht.NodeID]*history),
+	}, nil
+}
+
+// Heartbeat records a heartbeat of the node received at now
+func (d *Detector) Heartbeat(nodeID dht.NodeID, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.nodes[nodeID]
+	if !ok {
+		// The first intervals are assumed around the estimate, as there is nothing to learn from yet
+		estimate := toMillis(d.cfg.FirstHeartbeatEstimate)
+		h = &history{}
+		h.add(estimate-estimate/4, d.cfg.MaxSamples)
+		h.add(estimate+estimate/4, d.cfg.MaxSamples)
+		h.lastHeartbeat = now
+		d.nodes[nodeID] = h
+		return
+	}
+
+	if now.After(h.lastHeartbeat) {
+		h.add(toMillis(now.Sub(h.lastHeartbeat)), d.cfg.MaxSamples)
+		h.lastHeartbeat = now
+	}
+}
+
+// Phi returns the suspicion level of the node at now. It is 0 for a node without heartbeats
+func (d *Detector) Phi(nodeID dht.NodeID, now time.Time) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.nodes[nodeID]
+	if !ok {
+		return 0
+	}
+
+	return d.phi(h, now)
+}
+
+// State returns the suspicion level and status of the node at now
+func (d *Detector) State(nodeID dht.NodeID, now time.Time) NodeState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state := NodeState{NodeID: nodeID, Status: StatusHealthy}
+	h, ok := d.nodes[nodeID]
+	if !ok {
+		return state
+	}
+
+	state.Phi = d.phi(h, now)
+	state.LastHeartbeat = h.lastHeartbeat
+	state.MeanInterval = time.Duration(h.mean() * float64(time.Millisecond))
+	switch {
+	case state.Phi >= d.cfg.FailureThreshold:
+		state.Status = StatusFailed
+	case state.Phi >= d.cfg.SuspectThreshold:
+		state.Status = StatusSuspect
+	}
+
+	return state
+}
+
+// Remove forgets the heartbeats of the node
+func (d *Detector) Remove(nodeID dht.NodeID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.nodes, nodeID)
+}
+
+// Reset forgets the heartbeats of all the nodes
+func (d *Detector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nodes = make(map[dht.NodeID]*history)
+}
+
+// phi is -log10 of the probability that a heartbeat arrives later than the time since the last heartbeat,
+// assuming the intervals are normally distributed. The normal CDF is approximated with a logistic function.
+func (d *Detector) phi(h *history, now time.Time) float64 {
+	elapsed := toMillis(now.Sub(h.lastHeartbeat))
+	mean := h.mean() + toMillis(d.cfg.AcceptablePause)
+	stdDeviation := math.Max(h.stdDeviation(), toMillis(d.cfg.MinStdDeviation))
+	if stdDeviation <= 0 {
+		stdDeviation = 1
+	}
+
+	y := (elapsed - mean) / stdDeviation
+	e := math.Exp(-y * (1.5976 + 0.070566*y*y))
+	if elapsed > mean {
+		return -math.Log10(e / (1 + e))
+	}
+	return -math.Log10(1 - 1/(1+e))
+}
+
+func (h *history) add(interval float64, maxSamples int) {
+	if len(h.intervals) >= maxSamples {
+		oldest := h.intervals[0]
+		h.intervals = h.intervals[1:]
+		h.sum -= oldest
+		h.sumSq -= oldest * oldest
+	}
+
+	h.intervals = append(h.intervals, interval)
+	h.sum += interval
+	h.sumSq += interval * interval
+}
+
+func (h *history) mean() float64 {
+	return h.sum / float64(len(h.intervals))
+}
+
+func (h *history) stdDeviation() float64 {
+	mean := h.mean()
+	variance := h.sumSq/float64(len(h.intervals)) - mean*mean
+	if variance <= 0 {
+		return 0
+	}
+
+	return math.Sqrt(variance)
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,67 @@
+package failuredetector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectorStatus(t *testing.T) {
+	d, err := CreateDetector(DefaultConfig(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		d.Heartbeat("node1", start.Add(time.Duration(i)*time.Second))
+	}
+	last := start.Add(19 * time.Second)
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    Status
+	}{
+		{name: "on time", elapsed: time.Second, want: StatusHealthy},
+		{name: "late", elapsed: 4 * time.Second, want: StatusHealthy},
+		{name: "suspected", elapsed: 4500 * time.Millisecond, want: StatusSuspect},
+		{name: "failed", elapsed: 6 * time.Second, want: StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := d.State("node1", last.Add(tt.elapsed))
+			if state.Status != tt.want {
+				t.Errorf("State() status = %s at phi %.2f, want %s", state.Status, state.Phi, tt.want)
+			}
+		})
+	}
+
+	if phi := d.Phi("node2", last); phi != 0 {
+		t.Errorf("Phi() of a node without heartbeats = %.2f, want 0", phi)
+	}
+}
+
+func TestDetectorAdapts(t *testing.T) {
+	d, err := CreateDetector(DefaultConfig(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// node2 sends its heartbeats slower than node1, hence it is suspected later
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		d.Heartbeat("node1", start.Add(time.Duration(i)*time.Second))
+		d.Heartbeat("node2", start.Add(time.Duration(i)*3*time.Second))
+	}
+
+	fast := d.Phi("node1", start.Add(19*time.Second).Add(5*time.Second))
+	slow := d.Phi("node2", start.Add(57*time.Second).Add(5*time.Second))
+	if slow >= fast {
+		t.Errorf("Phi() of the slow node = %.2f, want less than %.2f of the fast node", slow, fast)
+	}
+
+	if _, err = CreateDetector(Config{SuspectThreshold: 8, FailureThreshold: 5}); err != ErrInvalidThresholds {
+		t.Errorf("CreateDetector() error = %v, want %v", err, ErrInvalidThresholds)
+	}
+}
@@ -0,0 +1,66 @@
+package jobstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+// ErrStaleEpoch is returned by a follower when a write is replicated by a node that is not the leader of the shard
+// in its shard map, and the node is not on a newer epoch of the shard map. The node is no longer the leader of the shard.
+var ErrStaleEpoch = errors.New("replicated by a leader on a stale shard map epoch")
+
+// Sender is the node that forwarded or replicated a write, along with the epoch of its shard map
+// when it routed the write
+type Sender struct {
+	NodeID dht.NodeID
+	Epoch  uint64
+}
+
+type senderKey struct{}
+
+// GetSender returns the sender of the write in ctx. It is false if the write was received from a client,
+// or from a node that does not send its epoch
+func GetSender(ctx context.Context) (Sender, bool) {
+	s, ok := ctx.Value(senderKey{}).(Sender)
+	return s, ok
+}
+
+// WithSender returns ctx with the sender of the write
+func WithSender(ctx context.Context, s Sender) context.Context {
+	return context.WithValue(ctx, senderKey{}, s)
+}
+
+// CheckForwarded returns ShardMapMismatchError if the write was forwarded by a node on a newer epoch of the
+// shard map than this node. This node may no longer be the leader of the shard, and can only route the write
+// once it has applied the newer shard map.
+func CheckForwarded(ctx context.Context, self dht.NodeID, epoch uint64) error {
+	s, ok := GetSender(ctx)
+	if !ok || s.Epoch <= epoch {
+		return nil
+	}
+
+	return &ShardMapMismatchError{
+		NodeID:     self,
+		DHTVersion: epoch,
+		Path:       GetForwarding(ctx).Path,
+	}
+}
+
+// CheckReplicated returns ErrStaleEpoch if the write was replicated by a node that is not the leader of the shard in
+// this node's shard map, on the same or an older epoch of the shard map. The epoch is increased by a change to any
+// shard, hence the writes of the leader of the shard are accepted on any epoch. Writes from a sender on a newer epoch
+// are accepted, as this node has not yet applied the shard map that made the sender the leader.
+func CheckReplicated(ctx context.Context, shardLoc dht.ShardLocation, epoch uint64) error {
+	s, ok := GetSender(ctx)
+	if !ok {
+		return nil
+	}
+
+	if s.NodeID != shardLoc.Leader.ID && s.Epoch <= epoch {
+		return ErrStaleEpoch
+	}
+
+	return nil
+}
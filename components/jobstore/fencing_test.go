@@ -0,0 +1,104 @@
+package jobstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+func TestCheckReplicated(t *testing.T) {
+	shardLoc := dht.ShardLocation{ID: 1, Leader: dht.NodeDetails{ID: "node1"}}
+
+	tests := []struct {
+		name    string
+		sender  *Sender
+		wantErr error
+	}{
+		{name: "leader on the same epoch", sender: &Sender{NodeID: "node1", Epoch: 5}},
+		{name: "leader on a newer epoch", sender: &Sender{NodeID: "node2", Epoch: 6}},
+		{name: "leader on an older epoch", sender: &Sender{NodeID: "node1", Epoch: 4}},
+		{name: "not the leader on the same epoch", sender: &Sender{NodeID: "node2", Epoch: 5}, wantErr: ErrStaleEpoch},
+		{name: "not the leader on an older epoch", sender: &Sender{NodeID: "node2", Epoch: 4}, wantErr: ErrStaleEpoch},
+		{name: "sender without epoch", sender: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.sender != nil {
+				ctx = WithSender(ctx, *tt.sender)
+			}
+
+			if err := CheckReplicated(ctx, shardLoc, 5); err != tt.wantErr {
+				t.Errorf("CheckReplicated() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckReplicatedAfterUnrelatedChange(t *testing.T) {
+	appDht := dht.Create()
+	appDht.Load(map[dht.ShardID]dht.ShardLocation{
+		1: {ID: 1, Leader: dht.NodeDetails{ID: "node1"}, Followers: []dht.NodeDetails{{ID: "node2"}}},
+		2: {ID: 2, Leader: dht.NodeDetails{ID: "node2"}, Followers: []dht.NodeDetails{{ID: "node1"}}},
+	}, 5)
+
+	// The leadership of shard 2 is handed over, the leaders replicate on the epoch before the change
+	appDht.Load(map[dht.ShardID]dht.ShardLocation{
+		1: {ID: 1, Leader: dht.NodeDetails{ID: "node1"}, Followers: []dht.NodeDetails{{ID: "node2"}}},
+		2: {ID: 2, Leader: dht.NodeDetails{ID: "node1"}, Followers: []dht.NodeDetails{{ID: "node2"}}},
+	}, 6)
+
+	tests := []struct {
+		name    string
+		shardID dht.ShardID
+		sender  Sender
+		wantErr error
+	}{
+		{name: "leader of the unchanged shard", shardID: 1, sender: Sender{NodeID: "node1", Epoch: 5}},
+		{name: "former leader of the changed shard", shardID: 2, sender: Sender{NodeID: "node2", Epoch: 5}, wantErr: ErrStaleEpoch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shardLoc, err := appDht.GetShardLocation(tt.shardID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := WithSender(context.Background(), tt.sender)
+			if err := CheckReplicated(ctx, shardLoc, appDht.Epoch()); err != tt.wantErr {
+				t.Errorf("CheckReplicated() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckForwarded(t *testing.T) {
+	tests := []struct {
+		name    string
+		sender  *Sender
+		wantErr bool
+	}{
+		{name: "sender on the same epoch", sender: &Sender{NodeID: "node1", Epoch: 5}},
+		{name: "sender on an older epoch", sender: &Sender{NodeID: "node1", Epoch: 4}},
+		{name: "sender on a newer epoch", sender: &Sender{NodeID: "node1", Epoch: 6}, wantErr: true},
+		{name: "request from a client", sender: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithForwarding(context.Background(), Forwarding{Path: []dht.NodeID{"node1"}, Hops: 1})
+			if tt.sender != nil {
+				ctx = WithSender(ctx, *tt.sender)
+			}
+
+			err := CheckForwarded(ctx, "node2", 5)
+			if got := errors.Is(err, ErrShardMapMismatch); got != tt.wantErr {
+				t.Errorf("CheckForwarded() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
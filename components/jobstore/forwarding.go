@@ -0,0 +1,87 @@
+package jobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+// MaxForwardingHops is the number of times a write can be forwarded between the nodes to reach the leader of its shard.
+// A write is forwarded once if the nodes agree on the shard map, and one more time if a node has not yet applied
+// the latest change of the shard map.
+const MaxForwardingHops = 3
+
+// ErrShardMapMismatch is matched by ShardMapMismatchError with errors.Is
+var ErrShardMapMismatch = errors.New("shard map mismatch")
+
+// ShardMapMismatchError is returned when a write is forwarded in a loop, more than MaxForwardingHops times,
+// or to a node on an older epoch of the shard map, as the nodes on its path do not agree on the leader of its shard.
+// This happens while a change in the shard map is being applied on the nodes. The write can be retried once
+// the nodes are on the same epoch of the shard map.
+type ShardMapMismatchError struct {
+	// Node that rejected the write and the epoch of its shard map
+	NodeID     dht.NodeID
+	DHTVersion uint64
+
+	// Nodes that forwarded the write, in order
+	Path []dht.NodeID
+}
+
+func (e *ShardMapMismatchError) Error() string {
+	return fmt.Sprintf("shard map mismatch: write forwarded through %v, node %s is on shard map epoch %d",
+		e.Path, e.NodeID, e.DHTVersion)
+}
+
+func (e *ShardMapMismatchError) Is(target error) bool {
+	return target == ErrShardMapMismatch
+}
+
+// Forwarding records the nodes that have forwarded a write on its way to the leader of its shard
+type Forwarding struct {
+	Path []dht.NodeID
+	Hops int
+}
+
+type forwardingKey struct{}
+
+// GetForwarding returns the forwarding of the write in ctx. It is empty if the write was not forwarded
+func GetForwarding(ctx context.Context) Forwarding {
+	f, _ := ctx.Value(forwardingKey{}).(Forwarding)
+	return f
+}
+
+// WithForwarding returns ctx with the forwarding of the write received from another node
+func WithForwarding(ctx context.Context, f Forwarding) context.Context {
+	return context.WithValue(ctx, forwardingKey{}, f)
+}
+
+// Forward returns ctx with the node added to the forwarding of the write. It returns ShardMapMismatchError
+// if the write has already passed through the node or the leader, or has been forwarded too many times.
+func Forward(ctx context.Context, self, leader dht.NodeID, dhtVersion uint64) (context.Context, error) {
+	f := GetForwarding(ctx)
+	if f.Hops >= MaxForwardingHops || f.contains(self) || f.contains(leader) {
+		return ctx, &ShardMapMismatchError{
+			NodeID:     self,
+			DHTVersion: dhtVersion,
+			Path:       f.Path,
+		}
+	}
+
+	path := make([]dht.NodeID, 0, len(f.Path)+1)
+	path = append(path, f.Path...)
+	path = append(path, self)
+
+	return WithForwarding(ctx, Forwarding{Path: path, Hops: f.Hops + 1}), nil
+}
+
+func (f Forwarding) contains(nodeID dht.NodeID) bool {
+	for _, id := range f.Path {
+		if id == nodeID {
+			return true
+		}
+	}
+
+	return false
+}
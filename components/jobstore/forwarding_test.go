@@ -0,0 +1,84 @@
+package jobstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+func TestForward(t *testing.T) {
+	tests := []struct {
+		name         string
+		forwarding   *Forwarding
+		self, leader dht.NodeID
+		wantPath     []dht.NodeID
+		wantErr      bool
+	}{
+		{
+			name:     "request from a client",
+			self:     "node1",
+			leader:   "node2",
+			wantPath: []dht.NodeID{"node1"},
+		},
+		{
+			name:       "node with a newer shard map",
+			forwarding: &Forwarding{Path: []dht.NodeID{"node1"}, Hops: 1},
+			self:       "node2",
+			leader:     "node3",
+			wantPath:   []dht.NodeID{"node1", "node2"},
+		},
+		{
+			name:       "forwarded back to the sender",
+			forwarding: &Forwarding{Path: []dht.NodeID{"node1"}, Hops: 1},
+			self:       "node2",
+			leader:     "node1",
+			wantErr:    true,
+		},
+		{
+			name:       "forwarded back to this node",
+			forwarding: &Forwarding{Path: []dht.NodeID{"node2", "node3"}, Hops: 2},
+			self:       "node2",
+			leader:     "node4",
+			wantErr:    true,
+		},
+		{
+			name:       "too many hops",
+			forwarding: &Forwarding{Path: []dht.NodeID{"node1", "node2", "node3"}, Hops: MaxForwardingHops},
+			self:       "node4",
+			leader:     "node5",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.forwarding != nil {
+				ctx = WithForwarding(ctx, *tt.forwarding)
+			}
+
+			ctx, err := Forward(ctx, tt.self, tt.leader, 42)
+			if tt.wantErr {
+				var mismatch *ShardMapMismatchError
+				if !errors.As(err, &mismatch) || !errors.Is(err, ErrShardMapMismatch) {
+					t.Fatalf("Forward() error = %v, want shard map mismatch", err)
+				}
+				if mismatch.NodeID != tt.self || mismatch.DHTVersion != 42 {
+					t.Errorf("Forward() error on node %s version %d, want node %s version 42", mismatch.NodeID, mismatch.DHTVersion, tt.self)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Forward() error = %v", err)
+			}
+
+			got := GetForwarding(ctx)
+			if !reflect.DeepEqual(got.Path, tt.wantPath) || got.Hops != len(tt.wantPath) {
+				t.Errorf("Forward() = %v, want path %v", got, tt.wantPath)
+			}
+		})
+	}
+}
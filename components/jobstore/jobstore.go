@@ -3,6 +3,10 @@
 package jobstore
 
 import (
+	"context"
+
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/dht"
 	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
 )
 
@@ -37,7 +41,72 @@ type JobFetcher interface {
 type JobStoreWithReplicator interface {
 	JobStore
 
-	ReplicateSetJob(collection string, job *jm.Job) (offset int64, err error)
-	ReplicateDeleteJob(collection, jobID string) (offset int64, err error)
-	HealthCheck() (bool, error)
+	// SetJobWithOptions sets the job and waits for the replicas required by the write concern in the options.
+	// The writes carry the trace of the request in ctx, and the remote stores send it to the other nodes
+	SetJobWithOptions(ctx context.Context, collection string, job *jm.Job, opts jm.WriteOptions) (jm.WriteResult, error)
+	DeleteJobWithOptions(ctx context.Context, collection, jobID string, opts jm.WriteOptions) (jm.WriteResult, error)
+
+	// CancelJobWithOptions moves the scheduled job to the cancelled state, so that it is not published
+	CancelJobWithOptions(ctx context.Context, collection, jobID string, opts jm.WriteOptions) (jm.WriteResult, error)
+
+	// SetJobs sets the jobs in the shards of the jobs. The jobs of a shard are written together.
+	// It returns the result of every job in the order of the jobs
+	SetJobs(ctx context.Context, collection string, jobs []*jm.Job, opts jm.WriteOptions) ([]jm.BatchResult, error)
+	DeleteJobs(ctx context.Context, collection string, jobIDs []string, opts jm.WriteOptions) ([]jm.BatchResult, error)
+
+	// ReplicateSetJob sets the job on the follower shard. leaderOffset is the wal offset
+	// of the write on the leader shard. It returns the latest offset of the follower shard.
+	ReplicateSetJob(ctx context.Context, collection string, job *jm.Job, leaderOffset int64) (offset int64, err error)
+	ReplicateDeleteJob(ctx context.Context, collection, jobID string, leaderOffset int64) (offset int64, err error)
+
+	// ReplicateBatch appends the batch entry of the leader shard to the follower shard and applies it.
+	// It returns the latest offset of the follower shard.
+	ReplicateBatch(ctx context.Context, shardID dht.ShardID, le wal.LogEntry) (offset int64, err error)
+
+	// StreamLogEntries calls f on all the wal entries of the shard after the offset.
+	// follower is the node that is catching up with the shard.
+	StreamLogEntries(follower dht.NodeID, shardID dht.ShardID, offset int64, f func(wal.LogEntry) error) error
+
+	// StreamShardSnapshot calls f on the chunks of a snapshot of the shard starting from the byte offset.
+	// If snapshotOffset does not match the snapshot on the node, a new snapshot is streamed from the beginning.
+	StreamShardSnapshot(follower dht.NodeID, shardID dht.ShardID, snapshotOffset, byteOffset int64, f func(SnapshotChunk) error) error
+
+	// GetShardOffset returns the latest wal offset of the shard on the node
+	GetShardOffset(shardID dht.ShardID) (int64, error)
+
+	// GetShardOffsets returns the latest wal offset and the high water mark of the shard on the node
+	GetShardOffsets(shardID dht.ShardID) (ShardOffsets, error)
+
+	// GetDeadLetterJobs returns the dead lettered jobs of the collection in the shard on the node
+	GetDeadLetterJobs(shardID dht.ShardID, collection string) ([]*jm.Job, error)
+
+	// GetJobs returns the jobs of the collection that match the query in the shard on the node
+	GetJobs(shardID dht.ShardID, collection string, query jm.JobQuery) ([]*jm.Job, error)
+
+	// HealthCheck returns true if the node is ready to accept requests. The check is bound by the deadline of ctx
+	HealthCheck(ctx context.Context) (bool, error)
+}
+
+// ShardOffsets are the wal offsets of a shard on a node
+type ShardOffsets struct {
+	// Latest wal offset applied to the shard
+	Applied int64
+
+	// Highest wal offset of the leader shard replicated to the shard, including the entries that are not yet applied.
+	// It is the same as Applied on the leader shard.
+	HighWaterMark int64
+}
+
+// SnapshotChunk is a part of the snapshot of a shard that is streamed to another node
+type SnapshotChunk struct {
+	// Last wal offset applied in the snapshot. It identifies the snapshot
+	SnapshotOffset int64
+
+	// Size and the hex encoded sha256 checksum of the whole snapshot
+	Size     int64
+	Checksum string
+
+	// Position of the data in the snapshot
+	ByteOffset int64
+	Data       []byte
 }
@@ -2,10 +2,17 @@ package network
 
 import (
 	"context"
+	"io"
 	"time"
 
+	"github.com/aarthikrao/timeMachine/components/datashard"
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/components/jobstore"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
 )
@@ -27,6 +34,12 @@ func CreateJobStoreClient(conn *grpc.ClientConn, rpcTimeout time.Duration) *netw
 	}
 }
 
+// withTimeout returns the context of a write rpc. Only the trace and the forwarding of ctx are carried over, so that
+// the rpc is bounded by the rpc timeout alone. The leader keeps replicating to the followers after the request has returned.
+func (nh *networkHandler) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(withForwardingMetadata(tracing.Detach(ctx), ctx), time.Now().Add(nh.rpcTimeout))
+}
+
 func (nh *networkHandler) GetJob(collection, jobID string) (*jm.Job, error) {
 	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
 	defer cancelFunc()
@@ -40,75 +53,239 @@ func (nh *networkHandler) GetJob(collection, jobID string) (*jm.Job, error) {
 		return nil, err
 	}
 
-	return &jm.Job{
-		TriggerMS: int(resp.TriggerTime),
-		ID:        resp.ID,
-		Meta:      resp.Meta,
-		Route:     resp.Route,
-	}, nil
+	return jm.GetJobFromCreationDetails(resp), nil
 
 }
 
 func (nh *networkHandler) SetJob(collection string, job *jm.Job) (offset int64, err error) {
-	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+	result, err := nh.SetJobWithOptions(context.Background(), collection, job, jm.WriteOptions{})
+	return result.Offset, err
+}
+
+func (nh *networkHandler) SetJobWithOptions(ctx context.Context, collection string, job *jm.Job, opts jm.WriteOptions) (jm.WriteResult, error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
 	defer cancelFunc()
 
-	_, err = nh.client.SetJob(ctx, &jm.JobCreationDetails{
-		TriggerTime: int64(job.TriggerMS),
-		ID:          job.ID,
-		Meta:        job.Meta,
-		Route:       job.Route,
-		Collection:  collection,
-	})
+	jd := job.ToCreationDetails(collection)
+	jd.WriteConcern = string(opts.WriteConcern)
+	jd.IfVersion = jm.ToIfVersion(opts.IfVersion)
+
+	resp, err := nh.client.SetJob(ctx, jd)
+	if err != nil {
+		return jm.WriteResult{}, getWriteError(err)
+	}
 
-	return 0, err
+	return getWriteResult(resp)
 }
 
 func (nh *networkHandler) DeleteJob(collection, jobID string) (offset int64, err error) {
-	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+	result, err := nh.DeleteJobWithOptions(context.Background(), collection, jobID, jm.WriteOptions{})
+	return result.Offset, err
+}
+
+func (nh *networkHandler) DeleteJobWithOptions(ctx context.Context, collection, jobID string, opts jm.WriteOptions) (jm.WriteResult, error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
 	defer cancelFunc()
 
-	_, err = nh.client.DeleteJob(ctx, &jm.JobFetchDetails{
-		Collection: collection,
-		ID:         jobID,
+	resp, err := nh.client.DeleteJob(ctx, &jm.JobFetchDetails{
+		Collection:   collection,
+		ID:           jobID,
+		WriteConcern: string(opts.WriteConcern),
+		IfVersion:    jm.ToIfVersion(opts.IfVersion),
 	})
+	if err != nil {
+		return jm.WriteResult{}, getWriteError(err)
+	}
 
-	return 0, err
+	return getWriteResult(resp)
+}
+
+func (nh *networkHandler) CancelJobWithOptions(ctx context.Context, collection, jobID string, opts jm.WriteOptions) (jm.WriteResult, error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
+	defer cancelFunc()
+
+	resp, err := nh.client.CancelJob(ctx, &jm.JobFetchDetails{
+		Collection:   collection,
+		ID:           jobID,
+		WriteConcern: string(opts.WriteConcern),
+	})
+	if err != nil {
+		return jm.WriteResult{}, getWriteError(err)
+	}
+
+	return getWriteResult(resp)
+}
+
+func (nh *networkHandler) SetJobs(ctx context.Context, collection string, jobs []*jm.Job, opts jm.WriteOptions) ([]jm.BatchResult, error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
+	defer cancelFunc()
+
+	req := &BatchSetRequest{
+		Collection:   collection,
+		Jobs:         make([]*jm.JobCreationDetails, 0, len(jobs)),
+		WriteConcern: string(opts.WriteConcern),
+	}
+	for _, job := range jobs {
+		req.Jobs = append(req.Jobs, job.ToCreationDetails(collection))
+	}
+
+	resp, err := nh.client.SetJobs(ctx, req)
+	if err != nil {
+		return nil, getWriteError(err)
+	}
+
+	return GetBatchResults(resp), nil
+}
+
+func (nh *networkHandler) DeleteJobs(ctx context.Context, collection string, jobIDs []string, opts jm.WriteOptions) ([]jm.BatchResult, error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
+	defer cancelFunc()
+
+	resp, err := nh.client.DeleteJobs(ctx, &BatchDeleteRequest{
+		Collection:   collection,
+		IDs:          jobIDs,
+		WriteConcern: string(opts.WriteConcern),
+	})
+	if err != nil {
+		return nil, getWriteError(err)
+	}
+
+	return GetBatchResults(resp), nil
+}
+
+// getWriteResult returns ErrWriteConcernNotSatisfied along with the result if
+// the leader could not get enough acknowledgements from its followers
+func getWriteResult(resp *jm.WriteResponse) (jm.WriteResult, error) {
+	result := jm.GetWriteResultFromResponse(resp)
+	if !result.Satisfied() {
+		return result, jm.ErrWriteConcernNotSatisfied
+	}
+
+	return result, nil
+}
+
+// getWriteError converts the version conflict, the reused idempotency key and the shard map mismatch
+// returned by the leader back to their errors
+func getWriteError(err error) error {
+	if e, ok := getShardMapMismatch(err); ok {
+		return e
+	}
+
+	switch status.Code(err) {
+	case codes.FailedPrecondition:
+		return jm.ErrVersionConflict
+	case codes.AlreadyExists:
+		return jm.ErrIdempotencyKeyReused
+	}
+
+	return err
+}
+
+// getReplicationError converts the rejection of a replicated write by the follower back to jobstore.ErrStaleEpoch
+func getReplicationError(err error) error {
+	if status.Code(err) == codes.FailedPrecondition {
+		return jobstore.ErrStaleEpoch
+	}
+
+	return err
 }
 
 func (nh *networkHandler) Type() jobstore.JobStoreType {
 	return jobstore.Network
 }
 
-func (nh *networkHandler) ReplicateSetJob(collection string, job *jm.Job) (offset int64, err error) {
-	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+func (nh *networkHandler) ReplicateSetJob(ctx context.Context, collection string, job *jm.Job, leaderOffset int64) (offset int64, err error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
 	defer cancelFunc()
 
-	_, err = nh.client.ReplicateSetJob(ctx, &jm.JobCreationDetails{
-		TriggerTime: int64(job.TriggerMS),
-		ID:          job.ID,
-		Meta:        job.Meta,
-		Route:       job.Route,
-		Collection:  collection,
-	})
+	jd := job.ToCreationDetails(collection)
+	jd.Offset = leaderOffset
+
+	resp, err := nh.client.ReplicateSetJob(ctx, jd)
+	if err != nil {
+		return 0, getReplicationError(err)
+	}
 
-	return 0, err
+	return resp.Offset, nil
 }
 
-func (nh *networkHandler) ReplicateDeleteJob(collection, jobID string) (offset int64, err error) {
-	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+func (nh *networkHandler) ReplicateDeleteJob(ctx context.Context, collection, jobID string, leaderOffset int64) (offset int64, err error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
 	defer cancelFunc()
 
-	_, err = nh.client.ReplicateDeleteJob(ctx, &jm.JobFetchDetails{
+	resp, err := nh.client.ReplicateDeleteJob(ctx, &jm.JobFetchDetails{
 		Collection: collection,
 		ID:         jobID,
+		Offset:     leaderOffset,
 	})
+	if err != nil {
+		return 0, getReplicationError(err)
+	}
 
-	return 0, err
+	return resp.Offset, nil
 }
 
-func (nh *networkHandler) HealthCheck() (bool, error) {
-	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+func (nh *networkHandler) ReplicateBatch(ctx context.Context, shardID dht.ShardID, le wal.LogEntry) (offset int64, err error) {
+	ctx, cancelFunc := nh.withTimeout(ctx)
+	defer cancelFunc()
+
+	resp, err := nh.client.ReplicateBatch(ctx, &ReplicateBatchRequest{
+		ShardID: int64(shardID),
+		Entry: &LogEntry{
+			Offset:     le.Offset,
+			Operation:  int32(le.Operation),
+			Collection: le.Collection,
+			Data:       le.Data,
+		},
+	})
+	if err != nil {
+		return 0, getReplicationError(err)
+	}
+
+	return resp.Offset, nil
+}
+
+// StreamLogEntries calls f on all the wal entries of the shard after the offset.
+// The stream is not bound by the rpc timeout as the follower might be far behind the leader.
+func (nh *networkHandler) StreamLogEntries(follower dht.NodeID, shardID dht.ShardID, offset int64, f func(wal.LogEntry) error) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	stream, err := nh.client.StreamLogEntries(ctx, &LogStreamRequest{
+		ShardID: int64(shardID),
+		Offset:  offset,
+		NodeID:  string(follower),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if status.Code(err) == codes.OutOfRange {
+			return datashard.ErrOffsetNotAvailable
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = f(wal.LogEntry{
+			Offset:     entry.Offset,
+			Operation:  wal.LogCommand(entry.Operation),
+			Collection: entry.Collection,
+			Data:       entry.Data,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// HealthCheck is bound by the rpc timeout, or by the deadline of ctx if it is earlier
+func (nh *networkHandler) HealthCheck(ctx context.Context) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, nh.rpcTimeout)
 	defer cancelFunc()
 
 	resp, err := nh.client.HealthCheck(ctx, &jm.HealthRequest{})
@@ -118,3 +295,114 @@ func (nh *networkHandler) HealthCheck() (bool, error) {
 
 	return resp.Healthy, nil
 }
+
+// StreamShardSnapshot calls f on the chunks of the snapshot of the shard.
+// Like StreamLogEntries, the stream is not bound by the rpc timeout.
+func (nh *networkHandler) StreamShardSnapshot(follower dht.NodeID, shardID dht.ShardID, snapshotOffset, byteOffset int64, f func(jobstore.SnapshotChunk) error) error {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	stream, err := nh.client.StreamShardSnapshot(ctx, &ShardSnapshotRequest{
+		ShardID:        int64(shardID),
+		SnapshotOffset: snapshotOffset,
+		ByteOffset:     byteOffset,
+		NodeID:         string(follower),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = f(jobstore.SnapshotChunk{
+			SnapshotOffset: chunk.SnapshotOffset,
+			Size:           chunk.Size,
+			Checksum:       chunk.Checksum,
+			ByteOffset:     chunk.ByteOffset,
+			Data:           chunk.Data,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func (nh *networkHandler) GetShardOffset(shardID dht.ShardID) (int64, error) {
+	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+	defer cancelFunc()
+
+	resp, err := nh.client.GetShardOffset(ctx, &ShardOffsetRequest{
+		ShardID: int64(shardID),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Offset, nil
+}
+
+func (nh *networkHandler) GetShardOffsets(shardID dht.ShardID) (jobstore.ShardOffsets, error) {
+	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+	defer cancelFunc()
+
+	resp, err := nh.client.GetShardOffset(ctx, &ShardOffsetRequest{
+		ShardID: int64(shardID),
+	})
+	if err != nil {
+		return jobstore.ShardOffsets{}, err
+	}
+
+	return jobstore.ShardOffsets{Applied: resp.Offset, HighWaterMark: resp.HighWaterMark}, nil
+}
+
+func (nh *networkHandler) GetDeadLetterJobs(shardID dht.ShardID, collection string) ([]*jm.Job, error) {
+	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+	defer cancelFunc()
+
+	resp, err := nh.client.GetDeadLetterJobs(ctx, &DeadLetterRequest{
+		ShardID:    int64(shardID),
+		Collection: collection,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*jm.Job, 0, len(resp.Jobs))
+	for _, jd := range resp.Jobs {
+		jobs = append(jobs, jm.GetJobFromCreationDetails(jd))
+	}
+
+	return jobs, nil
+}
+
+func (nh *networkHandler) GetJobs(shardID dht.ShardID, collection string, query jm.JobQuery) ([]*jm.Job, error) {
+	ctx, cancelFunc := context.WithDeadline(context.Background(), time.Now().Add(nh.rpcTimeout))
+	defer cancelFunc()
+
+	resp, err := nh.client.GetJobs(ctx, &GetJobsRequest{
+		ShardID:    int64(shardID),
+		Collection: collection,
+		FromTime:   int64(query.FromMS),
+		ToTime:     int64(query.ToMS),
+		Route:      query.Route,
+		Limit:      int64(query.Limit),
+		AfterTime:  int64(query.AfterMS),
+		AfterID:    query.AfterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*jm.Job, 0, len(resp.Jobs))
+	for _, jd := range resp.Jobs {
+		jobs = append(jobs, jm.GetJobFromCreationDetails(jd))
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,31 @@
+package network
+
+import jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+
+// ToBatchResponse converts the results of a batch write to the GRPC message
+func ToBatchResponse(results []jm.BatchResult) *BatchResponse {
+	resp := &BatchResponse{Results: make([]*BatchResult, 0, len(results))}
+	for _, r := range results {
+		resp.Results = append(resp.Results, &BatchResult{
+			ID:     r.ID,
+			Result: r.WriteResult.ToWriteResponse(),
+			Error:  r.Error,
+		})
+	}
+
+	return resp
+}
+
+// GetBatchResults converts the GRPC message to the results of a batch write
+func GetBatchResults(resp *BatchResponse) []jm.BatchResult {
+	results := make([]jm.BatchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		result := jm.BatchResult{ID: r.ID, Error: r.Error}
+		if r.Result != nil {
+			result.WriteResult = jm.GetWriteResultFromResponse(r.Result)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
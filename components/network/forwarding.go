@@ -0,0 +1,140 @@
+package network
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys of the forwarding of a write
+const (
+	// Nodes that forwarded the write, in order
+	forwardedByKey = "timemachine-forwarded-by"
+
+	// Number of times the write was forwarded
+	hopsKey = "timemachine-hops"
+
+	// Node that forwarded or replicated the write, and the epoch of its shard map
+	senderKey      = "timemachine-sender"
+	senderEpochKey = "timemachine-sender-epoch"
+)
+
+// Details of the ShardMapMismatchError returned over GRPC
+const (
+	errorDomain            = "timemachine"
+	shardMapMismatchReason = "SHARD_MAP_MISMATCH"
+)
+
+// withForwardingMetadata returns ctx with the forwarding and the sender of the write in from added to its outgoing metadata
+func withForwardingMetadata(ctx, from context.Context) context.Context {
+	md := metadata.MD{}
+
+	if f := jobstore.GetForwarding(from); f.Hops > 0 {
+		md.Set(hopsKey, strconv.Itoa(f.Hops))
+		for _, nodeID := range f.Path {
+			md.Append(forwardedByKey, string(nodeID))
+		}
+	}
+
+	if s, ok := jobstore.GetSender(from); ok {
+		md.Set(senderKey, string(s.NodeID))
+		md.Set(senderEpochKey, strconv.FormatUint(s.Epoch, 10))
+	}
+
+	if md.Len() == 0 {
+		return ctx
+	}
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// ForwardingInterceptor reads the forwarding and the sender of the write from the incoming metadata into the context of the request
+func ForwardingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	if len(md.Get(hopsKey)) > 0 {
+		hops, err := strconv.Atoi(md.Get(hopsKey)[0])
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid "+hopsKey)
+		}
+
+		f := jobstore.Forwarding{Hops: hops}
+		for _, nodeID := range md.Get(forwardedByKey) {
+			f.Path = append(f.Path, dht.NodeID(nodeID))
+		}
+		ctx = jobstore.WithForwarding(ctx, f)
+	}
+
+	if len(md.Get(senderKey)) > 0 && len(md.Get(senderEpochKey)) > 0 {
+		epoch, err := strconv.ParseUint(md.Get(senderEpochKey)[0], 10, 64)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid "+senderEpochKey)
+		}
+
+		ctx = jobstore.WithSender(ctx, jobstore.Sender{NodeID: dht.NodeID(md.Get(senderKey)[0]), Epoch: epoch})
+	}
+
+	return handler(ctx, req)
+}
+
+// GetShardMapMismatchStatus converts the error to a GRPC status. The details of the error are retained,
+// so that the client can convert it back
+func GetShardMapMismatchStatus(e *jobstore.ShardMapMismatchError) error {
+	path := make([]string, 0, len(e.Path))
+	for _, nodeID := range e.Path {
+		path = append(path, string(nodeID))
+	}
+
+	st, err := status.New(codes.Aborted, e.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: shardMapMismatchReason,
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"node_id":     string(e.NodeID),
+			"dht_version": strconv.FormatUint(e.DHTVersion, 10),
+			"path":        strings.Join(path, ","),
+		},
+	})
+	if err != nil {
+		return status.Error(codes.Aborted, e.Error())
+	}
+
+	return st.Err()
+}
+
+// getShardMapMismatch returns the ShardMapMismatchError in the GRPC status returned by the other node
+func getShardMapMismatch(err error) (*jobstore.ShardMapMismatchError, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.Aborted {
+		return nil, false
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != errorDomain || info.Reason != shardMapMismatchReason {
+			continue
+		}
+
+		e := &jobstore.ShardMapMismatchError{NodeID: dht.NodeID(info.Metadata["node_id"])}
+		e.DHTVersion, _ = strconv.ParseUint(info.Metadata["dht_version"], 10, 64)
+		if info.Metadata["path"] != "" {
+			for _, nodeID := range strings.Split(info.Metadata["path"], ",") {
+				e.Path = append(e.Path, dht.NodeID(nodeID))
+			}
+		}
+
+		return e, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,72 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestForwardingMetadata(t *testing.T) {
+	want := jobstore.Forwarding{Path: []dht.NodeID{"node1", "node2"}, Hops: 2}
+	wantSender := jobstore.Sender{NodeID: "node2", Epoch: 7}
+	from := jobstore.WithSender(jobstore.WithForwarding(context.Background(), want), wantSender)
+	out := withForwardingMetadata(context.Background(), from)
+
+	// The outgoing metadata of the client is the incoming metadata of the server
+	md, _ := metadata.FromOutgoingContext(out)
+	in := metadata.NewIncomingContext(context.Background(), md)
+
+	var got jobstore.Forwarding
+	var gotSender jobstore.Sender
+	_, err := ForwardingInterceptor(in, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = jobstore.GetForwarding(ctx)
+		gotSender, _ = jobstore.GetSender(ctx)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("forwarding = %v, want %v", got, want)
+	}
+	if gotSender != wantSender {
+		t.Errorf("sender = %v, want %v", gotSender, wantSender)
+	}
+}
+
+func TestShardMapMismatchStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *jobstore.ShardMapMismatchError
+	}{
+		{
+			name: "forwarded in a loop",
+			err:  &jobstore.ShardMapMismatchError{NodeID: "node2", DHTVersion: 12, Path: []dht.NodeID{"node1", "node3"}},
+		},
+		{
+			name: "not forwarded",
+			err:  &jobstore.ShardMapMismatchError{NodeID: "node1", DHTVersion: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := getWriteError(GetShardMapMismatchStatus(tt.err))
+
+			var got *jobstore.ShardMapMismatchError
+			if !errors.As(err, &got) {
+				t.Fatalf("getWriteError() = %v, want shard map mismatch", err)
+			}
+			if !reflect.DeepEqual(got, tt.err) {
+				t.Errorf("getWriteError() = %+v, want %+v", got, tt.err)
+			}
+		})
+	}
+}
@@ -26,13 +26,35 @@ type JobStoreClient interface {
 	// GetJob fetches the job from a time machine instance
 	GetJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.JobCreationDetails, error)
 	// SetJob adds the job to a time machine instance
-	SetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.JobCreationDetails, error)
+	SetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error)
 	// DeleteJob will remove the job from time machine instance
-	DeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.Empty, error)
+	DeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error)
+	// CancelJob stops a scheduled job from being published. The job is retained with its history
+	CancelJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error)
 	// ReplicateSetJob is the same as SetJob. It is called only by the leader to replicate the job on the follower
-	ReplicateSetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.JobCreationDetails, error)
+	ReplicateSetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error)
 	// ReplicateDeleteJob is the same as DeleteJobJob. It is called only by the leader to replicate the job on the follower
-	ReplicateDeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.Empty, error)
+	ReplicateDeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error)
+	// StreamLogEntries streams the wal entries of a shard after the given offset.
+	// It is called by the followers to catch up with the leader shard
+	StreamLogEntries(ctx context.Context, in *LogStreamRequest, opts ...grpc.CallOption) (JobStore_StreamLogEntriesClient, error)
+	// StreamShardSnapshot streams a snapshot of the bolt database of a shard in chunks.
+	// It is called by the followers that are too far behind to catch up from the wal.
+	// The transfer can be resumed by passing the snapshot offset and the bytes received so far
+	StreamShardSnapshot(ctx context.Context, in *ShardSnapshotRequest, opts ...grpc.CallOption) (JobStore_StreamShardSnapshotClient, error)
+	// GetShardOffset returns the latest wal offset of a shard on the node.
+	// It is used to find out if a new owner of the shard has caught up during redistribution
+	GetShardOffset(ctx context.Context, in *ShardOffsetRequest, opts ...grpc.CallOption) (*ShardOffsetResponse, error)
+	// GetDeadLetterJobs returns the dead lettered jobs of a collection in the leader shard on the node
+	GetDeadLetterJobs(ctx context.Context, in *DeadLetterRequest, opts ...grpc.CallOption) (*JobList, error)
+	// SetJobs adds the jobs to the shards led by the node. The jobs of a shard are written together
+	SetJobs(ctx context.Context, in *BatchSetRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// DeleteJobs removes the jobs from the shards led by the node. The jobs of a shard are deleted together
+	DeleteJobs(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// ReplicateBatch is called only by the leader to replicate a batch of writes on the follower
+	ReplicateBatch(ctx context.Context, in *ReplicateBatchRequest, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error)
+	// GetJobs returns the jobs of a collection that match the query in the leader shard on the node
+	GetJobs(ctx context.Context, in *GetJobsRequest, opts ...grpc.CallOption) (*JobList, error)
 	// Used only to make sure the node is servicable
 	HealthCheck(ctx context.Context, in *jobmodels.HealthRequest, opts ...grpc.CallOption) (*jobmodels.HealthResponse, error)
 }
@@ -54,8 +76,8 @@ func (c *jobStoreClient) GetJob(ctx context.Context, in *jobmodels.JobFetchDetai
 	return out, nil
 }
 
-func (c *jobStoreClient) SetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.JobCreationDetails, error) {
-	out := new(jobmodels.JobCreationDetails)
+func (c *jobStoreClient) SetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error) {
+	out := new(jobmodels.WriteResponse)
 	err := c.cc.Invoke(ctx, "/network.JobStore/SetJob", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -63,8 +85,8 @@ func (c *jobStoreClient) SetJob(ctx context.Context, in *jobmodels.JobCreationDe
 	return out, nil
 }
 
-func (c *jobStoreClient) DeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.Empty, error) {
-	out := new(jobmodels.Empty)
+func (c *jobStoreClient) DeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error) {
+	out := new(jobmodels.WriteResponse)
 	err := c.cc.Invoke(ctx, "/network.JobStore/DeleteJob", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -72,8 +94,17 @@ func (c *jobStoreClient) DeleteJob(ctx context.Context, in *jobmodels.JobFetchDe
 	return out, nil
 }
 
-func (c *jobStoreClient) ReplicateSetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.JobCreationDetails, error) {
-	out := new(jobmodels.JobCreationDetails)
+func (c *jobStoreClient) CancelJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error) {
+	out := new(jobmodels.WriteResponse)
+	err := c.cc.Invoke(ctx, "/network.JobStore/CancelJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStoreClient) ReplicateSetJob(ctx context.Context, in *jobmodels.JobCreationDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error) {
+	out := new(jobmodels.WriteResponse)
 	err := c.cc.Invoke(ctx, "/network.JobStore/ReplicateSetJob", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -81,8 +112,8 @@ func (c *jobStoreClient) ReplicateSetJob(ctx context.Context, in *jobmodels.JobC
 	return out, nil
 }
 
-func (c *jobStoreClient) ReplicateDeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.Empty, error) {
-	out := new(jobmodels.Empty)
+func (c *jobStoreClient) ReplicateDeleteJob(ctx context.Context, in *jobmodels.JobFetchDetails, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error) {
+	out := new(jobmodels.WriteResponse)
 	err := c.cc.Invoke(ctx, "/network.JobStore/ReplicateDeleteJob", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -90,6 +121,124 @@ func (c *jobStoreClient) ReplicateDeleteJob(ctx context.Context, in *jobmodels.J
 	return out, nil
 }
 
+func (c *jobStoreClient) StreamLogEntries(ctx context.Context, in *LogStreamRequest, opts ...grpc.CallOption) (JobStore_StreamLogEntriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobStore_ServiceDesc.Streams[0], "/network.JobStore/StreamLogEntries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobStoreStreamLogEntriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobStore_StreamLogEntriesClient interface {
+	Recv() (*LogEntry, error)
+	grpc.ClientStream
+}
+
+type jobStoreStreamLogEntriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobStoreStreamLogEntriesClient) Recv() (*LogEntry, error) {
+	m := new(LogEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobStoreClient) StreamShardSnapshot(ctx context.Context, in *ShardSnapshotRequest, opts ...grpc.CallOption) (JobStore_StreamShardSnapshotClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JobStore_ServiceDesc.Streams[1], "/network.JobStore/StreamShardSnapshot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jobStoreStreamShardSnapshotClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JobStore_StreamShardSnapshotClient interface {
+	Recv() (*ShardSnapshotChunk, error)
+	grpc.ClientStream
+}
+
+type jobStoreStreamShardSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *jobStoreStreamShardSnapshotClient) Recv() (*ShardSnapshotChunk, error) {
+	m := new(ShardSnapshotChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *jobStoreClient) GetShardOffset(ctx context.Context, in *ShardOffsetRequest, opts ...grpc.CallOption) (*ShardOffsetResponse, error) {
+	out := new(ShardOffsetResponse)
+	err := c.cc.Invoke(ctx, "/network.JobStore/GetShardOffset", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStoreClient) GetDeadLetterJobs(ctx context.Context, in *DeadLetterRequest, opts ...grpc.CallOption) (*JobList, error) {
+	out := new(JobList)
+	err := c.cc.Invoke(ctx, "/network.JobStore/GetDeadLetterJobs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStoreClient) SetJobs(ctx context.Context, in *BatchSetRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/network.JobStore/SetJobs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStoreClient) DeleteJobs(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/network.JobStore/DeleteJobs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStoreClient) ReplicateBatch(ctx context.Context, in *ReplicateBatchRequest, opts ...grpc.CallOption) (*jobmodels.WriteResponse, error) {
+	out := new(jobmodels.WriteResponse)
+	err := c.cc.Invoke(ctx, "/network.JobStore/ReplicateBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jobStoreClient) GetJobs(ctx context.Context, in *GetJobsRequest, opts ...grpc.CallOption) (*JobList, error) {
+	out := new(JobList)
+	err := c.cc.Invoke(ctx, "/network.JobStore/GetJobs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *jobStoreClient) HealthCheck(ctx context.Context, in *jobmodels.HealthRequest, opts ...grpc.CallOption) (*jobmodels.HealthResponse, error) {
 	out := new(jobmodels.HealthResponse)
 	err := c.cc.Invoke(ctx, "/network.JobStore/HealthCheck", in, out, opts...)
@@ -106,13 +255,35 @@ type JobStoreServer interface {
 	// GetJob fetches the job from a time machine instance
 	GetJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.JobCreationDetails, error)
 	// SetJob adds the job to a time machine instance
-	SetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.JobCreationDetails, error)
+	SetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.WriteResponse, error)
 	// DeleteJob will remove the job from time machine instance
-	DeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.Empty, error)
+	DeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error)
+	// CancelJob stops a scheduled job from being published. The job is retained with its history
+	CancelJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error)
 	// ReplicateSetJob is the same as SetJob. It is called only by the leader to replicate the job on the follower
-	ReplicateSetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.JobCreationDetails, error)
+	ReplicateSetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.WriteResponse, error)
 	// ReplicateDeleteJob is the same as DeleteJobJob. It is called only by the leader to replicate the job on the follower
-	ReplicateDeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.Empty, error)
+	ReplicateDeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error)
+	// StreamLogEntries streams the wal entries of a shard after the given offset.
+	// It is called by the followers to catch up with the leader shard
+	StreamLogEntries(*LogStreamRequest, JobStore_StreamLogEntriesServer) error
+	// StreamShardSnapshot streams a snapshot of the bolt database of a shard in chunks.
+	// It is called by the followers that are too far behind to catch up from the wal.
+	// The transfer can be resumed by passing the snapshot offset and the bytes received so far
+	StreamShardSnapshot(*ShardSnapshotRequest, JobStore_StreamShardSnapshotServer) error
+	// GetShardOffset returns the latest wal offset of a shard on the node.
+	// It is used to find out if a new owner of the shard has caught up during redistribution
+	GetShardOffset(context.Context, *ShardOffsetRequest) (*ShardOffsetResponse, error)
+	// GetDeadLetterJobs returns the dead lettered jobs of a collection in the leader shard on the node
+	GetDeadLetterJobs(context.Context, *DeadLetterRequest) (*JobList, error)
+	// SetJobs adds the jobs to the shards led by the node. The jobs of a shard are written together
+	SetJobs(context.Context, *BatchSetRequest) (*BatchResponse, error)
+	// DeleteJobs removes the jobs from the shards led by the node. The jobs of a shard are deleted together
+	DeleteJobs(context.Context, *BatchDeleteRequest) (*BatchResponse, error)
+	// ReplicateBatch is called only by the leader to replicate a batch of writes on the follower
+	ReplicateBatch(context.Context, *ReplicateBatchRequest) (*jobmodels.WriteResponse, error)
+	// GetJobs returns the jobs of a collection that match the query in the leader shard on the node
+	GetJobs(context.Context, *GetJobsRequest) (*JobList, error)
 	// Used only to make sure the node is servicable
 	HealthCheck(context.Context, *jobmodels.HealthRequest) (*jobmodels.HealthResponse, error)
 	mustEmbedUnimplementedJobStoreServer()
@@ -125,18 +296,45 @@ type UnimplementedJobStoreServer struct {
 func (UnimplementedJobStoreServer) GetJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.JobCreationDetails, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
 }
-func (UnimplementedJobStoreServer) SetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.JobCreationDetails, error) {
+func (UnimplementedJobStoreServer) SetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetJob not implemented")
 }
-func (UnimplementedJobStoreServer) DeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.Empty, error) {
+func (UnimplementedJobStoreServer) DeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteJob not implemented")
 }
-func (UnimplementedJobStoreServer) ReplicateSetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.JobCreationDetails, error) {
+func (UnimplementedJobStoreServer) CancelJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedJobStoreServer) ReplicateSetJob(context.Context, *jobmodels.JobCreationDetails) (*jobmodels.WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReplicateSetJob not implemented")
 }
-func (UnimplementedJobStoreServer) ReplicateDeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.Empty, error) {
+func (UnimplementedJobStoreServer) ReplicateDeleteJob(context.Context, *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReplicateDeleteJob not implemented")
 }
+func (UnimplementedJobStoreServer) StreamLogEntries(*LogStreamRequest, JobStore_StreamLogEntriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLogEntries not implemented")
+}
+func (UnimplementedJobStoreServer) StreamShardSnapshot(*ShardSnapshotRequest, JobStore_StreamShardSnapshotServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamShardSnapshot not implemented")
+}
+func (UnimplementedJobStoreServer) GetShardOffset(context.Context, *ShardOffsetRequest) (*ShardOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShardOffset not implemented")
+}
+func (UnimplementedJobStoreServer) GetDeadLetterJobs(context.Context, *DeadLetterRequest) (*JobList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeadLetterJobs not implemented")
+}
+func (UnimplementedJobStoreServer) SetJobs(context.Context, *BatchSetRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetJobs not implemented")
+}
+func (UnimplementedJobStoreServer) DeleteJobs(context.Context, *BatchDeleteRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteJobs not implemented")
+}
+func (UnimplementedJobStoreServer) ReplicateBatch(context.Context, *ReplicateBatchRequest) (*jobmodels.WriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplicateBatch not implemented")
+}
+func (UnimplementedJobStoreServer) GetJobs(context.Context, *GetJobsRequest) (*JobList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJobs not implemented")
+}
 func (UnimplementedJobStoreServer) HealthCheck(context.Context, *jobmodels.HealthRequest) (*jobmodels.HealthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -207,6 +405,24 @@ func _JobStore_DeleteJob_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobStore_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(jobmodels.JobFetchDetails)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStoreServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.JobStore/CancelJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStoreServer).CancelJob(ctx, req.(*jobmodels.JobFetchDetails))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _JobStore_ReplicateSetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(jobmodels.JobCreationDetails)
 	if err := dec(in); err != nil {
@@ -243,6 +459,156 @@ func _JobStore_ReplicateDeleteJob_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _JobStore_StreamLogEntries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobStoreServer).StreamLogEntries(m, &jobStoreStreamLogEntriesServer{stream})
+}
+
+type JobStore_StreamLogEntriesServer interface {
+	Send(*LogEntry) error
+	grpc.ServerStream
+}
+
+type jobStoreStreamLogEntriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobStoreStreamLogEntriesServer) Send(m *LogEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobStore_StreamShardSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ShardSnapshotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JobStoreServer).StreamShardSnapshot(m, &jobStoreStreamShardSnapshotServer{stream})
+}
+
+type JobStore_StreamShardSnapshotServer interface {
+	Send(*ShardSnapshotChunk) error
+	grpc.ServerStream
+}
+
+type jobStoreStreamShardSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *jobStoreStreamShardSnapshotServer) Send(m *ShardSnapshotChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _JobStore_GetShardOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShardOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStoreServer).GetShardOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.JobStore/GetShardOffset",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStoreServer).GetShardOffset(ctx, req.(*ShardOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobStore_GetDeadLetterJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStoreServer).GetDeadLetterJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.JobStore/GetDeadLetterJobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStoreServer).GetDeadLetterJobs(ctx, req.(*DeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobStore_SetJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStoreServer).SetJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.JobStore/SetJobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStoreServer).SetJobs(ctx, req.(*BatchSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobStore_DeleteJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStoreServer).DeleteJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.JobStore/DeleteJobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStoreServer).DeleteJobs(ctx, req.(*BatchDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobStore_ReplicateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStoreServer).ReplicateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.JobStore/ReplicateBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStoreServer).ReplicateBatch(ctx, req.(*ReplicateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JobStore_GetJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JobStoreServer).GetJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/network.JobStore/GetJobs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JobStoreServer).GetJobs(ctx, req.(*GetJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _JobStore_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(jobmodels.HealthRequest)
 	if err := dec(in); err != nil {
@@ -280,6 +646,10 @@ var JobStore_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteJob",
 			Handler:    _JobStore_DeleteJob_Handler,
 		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _JobStore_CancelJob_Handler,
+		},
 		{
 			MethodName: "ReplicateSetJob",
 			Handler:    _JobStore_ReplicateSetJob_Handler,
@@ -288,11 +658,46 @@ var JobStore_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReplicateDeleteJob",
 			Handler:    _JobStore_ReplicateDeleteJob_Handler,
 		},
+		{
+			MethodName: "GetShardOffset",
+			Handler:    _JobStore_GetShardOffset_Handler,
+		},
+		{
+			MethodName: "GetDeadLetterJobs",
+			Handler:    _JobStore_GetDeadLetterJobs_Handler,
+		},
+		{
+			MethodName: "SetJobs",
+			Handler:    _JobStore_SetJobs_Handler,
+		},
+		{
+			MethodName: "DeleteJobs",
+			Handler:    _JobStore_DeleteJobs_Handler,
+		},
+		{
+			MethodName: "ReplicateBatch",
+			Handler:    _JobStore_ReplicateBatch_Handler,
+		},
+		{
+			MethodName: "GetJobs",
+			Handler:    _JobStore_GetJobs_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _JobStore_HealthCheck_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLogEntries",
+			Handler:       _JobStore_StreamLogEntries_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamShardSnapshot",
+			Handler:       _JobStore_StreamShardSnapshot_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "components/network/network.proto",
 }
@@ -2,15 +2,23 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
+	"github.com/aarthikrao/timeMachine/components/datashard"
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/components/jobstore"
 	"github.com/aarthikrao/timeMachine/components/network"
 	jobmodels "github.com/aarthikrao/timeMachine/models/jobmodels"
 	"github.com/aarthikrao/timeMachine/process/cordinator"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type server struct {
@@ -35,7 +43,11 @@ func InitServer(
 	if err != nil {
 		log.Error("failed to listen: %v", zap.Error(err))
 	}
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor, network.ForwardingInterceptor),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor),
+		tracing.ServerOption(),
+	)
 	network.RegisterJobStoreServer(grpcServer, jobStoreServer)
 	jobStoreServer.grpcServer = grpcServer
 
@@ -61,55 +73,251 @@ func (s *server) GetJob(ctx context.Context, jd *jobmodels.JobFetchDetails) (*jo
 		return nil, err
 	}
 
-	return &jobmodels.JobCreationDetails{
-		ID:          job.ID,
-		TriggerTime: int64(job.TriggerMS),
-		Meta:        job.Meta,
-		Route:       job.Route,
-		Collection:  jd.Collection,
-	}, err
+	return job.ToCreationDetails(jd.Collection), err
 
 }
 
-// SetJob adds the job to a time machine instance
-func (s *server) SetJob(ctx context.Context, jd *jobmodels.JobCreationDetails) (*jobmodels.JobCreationDetails, error) {
-	_, err := s.cp.SetJob(jd.Collection, &jobmodels.Job{
-		ID:        jd.ID,
-		TriggerMS: int(jd.TriggerTime),
-		Meta:      jd.Meta,
-		Route:     jd.Route,
-	})
+// SetJob adds the job to a time machine instance.
+// If the write concern is not satisfied, the acknowledgements are returned without an error
+// so that the caller can find out how many replicas acknowledged the write.
+func (s *server) SetJob(ctx context.Context, jd *jobmodels.JobCreationDetails) (*jobmodels.WriteResponse, error) {
+	result, err := s.cp.SetJobWithOptions(
+		ctx,
+		jd.Collection,
+		jobmodels.GetJobFromCreationDetails(jd),
+		jobmodels.WriteOptions{
+			WriteConcern: jobmodels.WriteConcern(jd.WriteConcern),
+			IfVersion:    jobmodels.GetIfVersion(jd.IfVersion),
+		},
+	)
+	if err == jobmodels.ErrWriteConcernNotSatisfied {
+		err = nil
+	}
+	if err != nil {
+		return nil, getWriteStatus(err)
+	}
+
+	return result.ToWriteResponse(), nil
+}
+
+// getWriteStatus converts the errors of a write that the client converts back, so that they can be reported to the caller
+func getWriteStatus(err error) error {
+	var mismatch *jobstore.ShardMapMismatchError
+	switch {
+	case errors.As(err, &mismatch):
+		return network.GetShardMapMismatchStatus(mismatch)
+	case err == jobmodels.ErrVersionConflict:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case err == jobmodels.ErrIdempotencyKeyReused:
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
 
-	return jd, err
+	return err
 }
 
 // DeleteJob will remove the job from time machine instance
-func (s *server) DeleteJob(ctx context.Context, jd *jobmodels.JobFetchDetails) (*jobmodels.Empty, error) {
-	_, err := s.cp.DeleteJob(jd.Collection, jd.ID)
-	return &jobmodels.Empty{}, err
+func (s *server) DeleteJob(ctx context.Context, jd *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error) {
+	result, err := s.cp.DeleteJobWithOptions(
+		ctx,
+		jd.Collection,
+		jd.ID,
+		jobmodels.WriteOptions{
+			WriteConcern: jobmodels.WriteConcern(jd.WriteConcern),
+			IfVersion:    jobmodels.GetIfVersion(jd.IfVersion),
+		},
+	)
+	if err == jobmodels.ErrWriteConcernNotSatisfied {
+		err = nil
+	}
+	if err != nil {
+		return nil, getWriteStatus(err)
+	}
+
+	return result.ToWriteResponse(), nil
+}
+
+// CancelJob stops the job from being published
+func (s *server) CancelJob(ctx context.Context, jd *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error) {
+	result, err := s.cp.CancelJobWithOptions(
+		ctx,
+		jd.Collection,
+		jd.ID,
+		jobmodels.WriteOptions{WriteConcern: jobmodels.WriteConcern(jd.WriteConcern)},
+	)
+	if err == jobmodels.ErrWriteConcernNotSatisfied {
+		err = nil
+	}
+	if err != nil {
+		return nil, getWriteStatus(err)
+	}
+
+	return result.ToWriteResponse(), nil
+}
+
+// SetJobs adds a batch of jobs to time machine instance
+func (s *server) SetJobs(ctx context.Context, req *network.BatchSetRequest) (*network.BatchResponse, error) {
+	jobs := make([]*jobmodels.Job, 0, len(req.Jobs))
+	for _, jd := range req.Jobs {
+		jobs = append(jobs, jobmodels.GetJobFromCreationDetails(jd))
+	}
+
+	results, err := s.cp.SetJobs(
+		ctx,
+		req.Collection,
+		jobs,
+		jobmodels.WriteOptions{WriteConcern: jobmodels.WriteConcern(req.WriteConcern)},
+	)
+	if err != nil {
+		return nil, getWriteStatus(err)
+	}
+
+	return network.ToBatchResponse(results), nil
+}
+
+// DeleteJobs removes a batch of jobs from time machine instance
+func (s *server) DeleteJobs(ctx context.Context, req *network.BatchDeleteRequest) (*network.BatchResponse, error) {
+	results, err := s.cp.DeleteJobs(
+		ctx,
+		req.Collection,
+		req.IDs,
+		jobmodels.WriteOptions{WriteConcern: jobmodels.WriteConcern(req.WriteConcern)},
+	)
+	if err != nil {
+		return nil, getWriteStatus(err)
+	}
+
+	return network.ToBatchResponse(results), nil
 }
 
 // ReplicateSetJob is the same as SetJob. It is called only by the leader to replicate the job on the follower
-func (s *server) ReplicateSetJob(ctx context.Context, jd *jobmodels.JobCreationDetails) (*jobmodels.JobCreationDetails, error) {
-	_, err := s.cp.ReplicateSetJob(jd.Collection, &jobmodels.Job{
-		ID:        jd.ID,
-		TriggerMS: int(jd.TriggerTime),
-		Meta:      jd.Meta,
-		Route:     jd.Route,
-	})
+func (s *server) ReplicateSetJob(ctx context.Context, jd *jobmodels.JobCreationDetails) (*jobmodels.WriteResponse, error) {
+	offset, err := s.cp.ReplicateSetJob(ctx, jd.Collection, jobmodels.GetJobFromCreationDetails(jd), jd.Offset)
 
-	return jd, err
+	return &jobmodels.WriteResponse{Offset: offset}, getReplicationStatus(err)
 }
 
 // ReplicateDeleteJob is the same as DeleteJobJob. It is called only by the leader to replicate the job on the follower
-func (s *server) ReplicateDeleteJob(ctx context.Context, jd *jobmodels.JobFetchDetails) (*jobmodels.Empty, error) {
-	_, err := s.cp.ReplicateDeleteJob(jd.Collection, jd.ID)
-	return &jobmodels.Empty{}, err
+func (s *server) ReplicateDeleteJob(ctx context.Context, jd *jobmodels.JobFetchDetails) (*jobmodels.WriteResponse, error) {
+	offset, err := s.cp.ReplicateDeleteJob(ctx, jd.Collection, jd.ID, jd.Offset)
+	return &jobmodels.WriteResponse{Offset: offset}, getReplicationStatus(err)
+}
+
+// ReplicateBatch is called only by the leader to replicate a batch of writes on the follower
+func (s *server) ReplicateBatch(ctx context.Context, req *network.ReplicateBatchRequest) (*jobmodels.WriteResponse, error) {
+	if req.Entry == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing wal entry")
+	}
+
+	offset, err := s.cp.ReplicateBatch(ctx, dht.ShardID(req.ShardID), wal.LogEntry{
+		Offset:     req.Entry.Offset,
+		Operation:  wal.LogCommand(req.Entry.Operation),
+		Collection: req.Entry.Collection,
+		Data:       req.Entry.Data,
+	})
+	return &jobmodels.WriteResponse{Offset: offset}, getReplicationStatus(err)
+}
+
+// getReplicationStatus converts the rejection of a write replicated by a leader on a stale epoch,
+// so that the leader can convert it back
+func getReplicationStatus(err error) error {
+	if errors.Is(err, jobstore.ErrStaleEpoch) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return err
+}
+
+// StreamLogEntries streams the wal entries of a shard after the given offset.
+// It is called by the followers to catch up with the leader shard
+func (s *server) StreamLogEntries(req *network.LogStreamRequest, stream network.JobStore_StreamLogEntriesServer) error {
+	err := s.cp.StreamLogEntries(
+		dht.NodeID(req.NodeID),
+		dht.ShardID(req.ShardID),
+		req.Offset,
+		func(le wal.LogEntry) error {
+			return stream.Send(&network.LogEntry{
+				Offset:     le.Offset,
+				Operation:  int32(le.Operation),
+				Collection: le.Collection,
+				Data:       le.Data,
+			})
+		})
+	if err == datashard.ErrOffsetNotAvailable {
+		// The client converts it back so that the follower can request a snapshot instead
+		return status.Error(codes.OutOfRange, err.Error())
+	}
+
+	return err
+}
+
+// StreamShardSnapshot streams a snapshot of a shard on this node in chunks
+func (s *server) StreamShardSnapshot(req *network.ShardSnapshotRequest, stream network.JobStore_StreamShardSnapshotServer) error {
+	return s.cp.StreamShardSnapshot(
+		dht.NodeID(req.NodeID),
+		dht.ShardID(req.ShardID),
+		req.SnapshotOffset,
+		req.ByteOffset,
+		func(chunk jobstore.SnapshotChunk) error {
+			return stream.Send(&network.ShardSnapshotChunk{
+				SnapshotOffset: chunk.SnapshotOffset,
+				Size:           chunk.Size,
+				Checksum:       chunk.Checksum,
+				ByteOffset:     chunk.ByteOffset,
+				Data:           chunk.Data,
+			})
+		})
+}
+
+// GetShardOffset returns the latest wal offset of a shard on this node
+func (s *server) GetShardOffset(ctx context.Context, req *network.ShardOffsetRequest) (*network.ShardOffsetResponse, error) {
+	offsets, err := s.cp.GetShardOffsets(dht.ShardID(req.ShardID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &network.ShardOffsetResponse{Offset: offsets.Applied, HighWaterMark: offsets.HighWaterMark}, nil
+}
+
+// GetDeadLetterJobs returns the dead lettered jobs of a collection in the leader shard on this node
+func (s *server) GetDeadLetterJobs(ctx context.Context, req *network.DeadLetterRequest) (*network.JobList, error) {
+	jobs, err := s.cp.GetDeadLetterJobs(dht.ShardID(req.ShardID), req.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &network.JobList{}
+	for _, j := range jobs {
+		resp.Jobs = append(resp.Jobs, j.ToCreationDetails(req.Collection))
+	}
+
+	return resp, nil
+}
+
+// GetJobs returns the jobs of a collection that match the query in the leader shard on this node
+func (s *server) GetJobs(ctx context.Context, req *network.GetJobsRequest) (*network.JobList, error) {
+	jobs, err := s.cp.GetJobs(dht.ShardID(req.ShardID), req.Collection, jobmodels.JobQuery{
+		FromMS:  int(req.FromTime),
+		ToMS:    int(req.ToTime),
+		Route:   req.Route,
+		Limit:   int(req.Limit),
+		AfterMS: int(req.AfterTime),
+		AfterID: req.AfterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &network.JobList{}
+	for _, j := range jobs {
+		resp.Jobs = append(resp.Jobs, j.ToCreationDetails(req.Collection))
+	}
+
+	return resp, nil
 }
 
 // Health check
-func (s *server) HealthCheck(context.Context, *jobmodels.HealthRequest) (*jobmodels.HealthResponse, error) {
-	healthy, err := s.cp.HealthCheck()
+func (s *server) HealthCheck(ctx context.Context, req *jobmodels.HealthRequest) (*jobmodels.HealthResponse, error) {
+	healthy, err := s.cp.HealthCheck(ctx)
 
 	return &jobmodels.HealthResponse{
 		Healthy: healthy,
@@ -38,18 +38,26 @@ func (rs *RouteStore) GetRoute(id string) *rm.Route {
 	return rs.m[id]
 }
 
-// Snapshot returns the current snapshot of the route store
+// Snapshot returns a copy of the current snapshot of the route store
 func (rs *RouteStore) Snapshot() map[string]*rm.Route {
-	rs.mu.Lock()
-	defer rs.mu.Unlock()
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
 
-	return rs.m // TODO: Safe to return map ?
+	m := make(map[string]*rm.Route, len(rs.m))
+	for id, route := range rs.m {
+		m[id] = route
+	}
+
+	return m
 }
 
-// Loads the map to the route store
+// Load replaces the routes in the route store with a copy of the map
 func (rs *RouteStore) Load(m map[string]*rm.Route) {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
-	rs.m = m
+	rs.m = make(map[string]*rm.Route, len(m))
+	for id, route := range m {
+		rs.m[id] = route
+	}
 }
@@ -1,39 +1,64 @@
 package rest
 
 import (
+	"io"
 	"net/http" 
+	"time"
 
 	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
 	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/models/config"
+	"github.com/aarthikrao/timeMachine/process/clusterhealth"
 	"github.com/aarthikrao/timeMachine/process/nodemanager"
+	"github.com/aarthikrao/timeMachine/process/replicator"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// Time given to the new owners of the shards to catch up during redistribution
+const redistributeTimeout = 5 * time.Minute // TODO: Move to config
+
+// Time given to the followers of a drained node to catch up and take over its shards
+const drainTimeout = 10 * time.Minute // TODO: Move to config
+
 type clusterMessage struct {
 	NodeID      string `json:"node_id,omitempty" bson:"node_id,omitempty"`
 	RaftAddress string `json:"raft_address,omitempty" bson:"raft_address,omitempty"`
 }
 
+type redistributeMessage struct {
+	// Weights of the nodes, used when the shards are placed on a consistent hashing ring
+	Weights map[string]int `json:"weights,omitempty" bson:"weights,omitempty"`
+}
+
 type clusterRestHandler struct {
-	cp      consensus.Consensus
-	appDht  dht.DHT
-	nodeMgr *nodemanager.NodeManager
-	log     *zap.Logger
+	cp         consensus.Consensus
+	nodeConfig fsm.NodeConfig
+	appDht     dht.DHT
+	nodeMgr    *nodemanager.NodeManager
+	replicator *replicator.Replicator
+	health     *clusterhealth.ClusterHealth
+	log        *zap.Logger
 }
 
 func CreateClusterRestHandler(
 	cp consensus.Consensus,
+	nodeConfig fsm.NodeConfig,
 	appDht dht.DHT,
 	nodeMgr *nodemanager.NodeManager,
+	replicator *replicator.Replicator,
+	health *clusterhealth.ClusterHealth,
 	log *zap.Logger,
 ) *clusterRestHandler {
 	return &clusterRestHandler{
-		cp:      cp,
-		appDht:  appDht,
-		nodeMgr: nodeMgr,
-		log:     log,
+		cp:         cp,
+		nodeConfig: nodeConfig,
+		appDht:     appDht,
+		nodeMgr:    nodeMgr,
+		replicator: replicator,
+		health:     health,
+		log:        log,
 	}
 }
 
@@ -41,6 +66,38 @@ func (crh *clusterRestHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, crh.cp.Stats())
 }
 
+// GetReplicationStatus returns the wal offsets and the replication lag of the shards on this node
+func (crh *clusterRestHandler) GetReplicationStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"shards": crh.replicator.Status(),
+	})
+}
+
+// GetShards returns the shard map applied on this node along with its epoch
+func (crh *clusterRestHandler) GetShards(c *gin.Context) {
+	// The epoch is read first, so that it is never newer than the shard map
+	epoch := crh.appDht.Epoch()
+	c.JSON(http.StatusOK, gin.H{
+		"epoch":  epoch,
+		"shards": crh.appDht.Snapshot(),
+	})
+}
+
+// GetFailovers returns the latest failover decisions committed to the raft log, oldest first
+func (crh *clusterRestHandler) GetFailovers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"failovers": crh.nodeConfig.GetFailovers(),
+	})
+}
+
+// GetHealth returns the suspicion level of the nodes. The nodes are only checked by the raft leader
+func (crh *clusterRestHandler) GetHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"is_leader": crh.cp.IsLeader(),
+		"nodes":     crh.health.GetNodeHealth(),
+	})
+}
+
 func (crh *clusterRestHandler) GetConfigurations(c *gin.Context) {
 	cf, err := crh.cp.GetConfigurations()
 	if err != nil {
@@ -123,8 +180,79 @@ func (crh *clusterRestHandler) Redistribute(c *gin.Context) {
 		return
 	}
 
-	// TODO: Yet to implement
+	// The body is optional
+	var rm redistributeMessage
+	if err := c.ShouldBindJSON(&rm); err != nil && err != io.EOF {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	shards, err := crh.nodeMgr.Redistribute(redistributeTimeout, rm.Weights)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"shards": shards,
+	})
+}
+
+// Drain hands the leadership of the shards of the node over to their followers, and stops it from being assigned
+// new shards. The handover continues in the background, its progress is returned by GetDrainStatus
+func (crh *clusterRestHandler) Drain(c *gin.Context) {
+	if !crh.cp.IsLeader() {
+		c.AbortWithStatusJSON(http.StatusBadRequest,
+			gin.H{
+				"error":  "not leader",
+				"leader": crh.cp.GetLeaderAddress(),
+			},
+		)
+		return
+	}
+
+	status, err := crh.nodeMgr.Drain(dht.NodeID(c.Param("nodeID")), drainTimeout)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"drain":  status,
+	})
+}
+
+// Undrain restores the normal operation of a drained node
+func (crh *clusterRestHandler) Undrain(c *gin.Context) {
+	if !crh.cp.IsLeader() {
+		c.AbortWithStatusJSON(http.StatusBadRequest,
+			gin.H{
+				"error":  "not leader",
+				"leader": crh.cp.GetLeaderAddress(),
+			},
+		)
+		return
+	}
+
+	status, err := crh.nodeMgr.Undrain(dht.NodeID(c.Param("nodeID")))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"drain":  status,
+	})
+}
+
+// GetDrainStatus returns the progress of the drain of the node, as per the shard map applied on this node
+func (crh *clusterRestHandler) GetDrainStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"drain": crh.nodeMgr.GetDrainStatus(dht.NodeID(c.Param("nodeID"))),
+	})
 }
 
 func (crh *clusterRestHandler) Configure(c *gin.Context) {
@@ -149,7 +277,7 @@ func (crh *clusterRestHandler) Configure(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	}
 
-	err := crh.nodeMgr.InitAppDHT(cf.Shards, cf.Replicas)
+	err := crh.nodeMgr.InitAppDHT(cf.Shards, cf.Replicas, cf.Weights)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
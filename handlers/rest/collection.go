@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/aarthikrao/timeMachine/models/collectionmodels"
+	"github.com/aarthikrao/timeMachine/process/cordinator"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type collectionRestHandler struct {
+	cp  *cordinator.CordinatorProcess
+	log *zap.Logger
+}
+
+func CreateCollectionRestHandler(cp *cordinator.CordinatorProcess, log *zap.Logger) *collectionRestHandler {
+	return &collectionRestHandler{
+		cp:  cp,
+		log: log,
+	}
+}
+
+func (crh *collectionRestHandler) GetCollection(c *gin.Context) {
+	name := c.Param("name")
+
+	collection, err := crh.cp.GetCollection(name)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+func (crh *collectionRestHandler) SetCollection(c *gin.Context) {
+	var collection collectionmodels.Collection
+	if err := c.BindJSON(&collection); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := crh.cp.SetCollection(&collection); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
+
+func (crh *collectionRestHandler) DeleteCollection(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := crh.cp.DeleteCollection(name); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/aarthikrao/timeMachine/models/jobmodels"
+	"github.com/aarthikrao/timeMachine/process/cordinator"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type deadLetterRestHandler struct {
+	cordinatorProcess *cordinator.CordinatorProcess
+	log               *zap.Logger
+}
+
+func CreateDeadLetterRestHandler(
+	cordinatorProcess *cordinator.CordinatorProcess,
+	log *zap.Logger,
+) *deadLetterRestHandler {
+	return &deadLetterRestHandler{
+		cordinatorProcess: cordinatorProcess,
+		log:               log,
+	}
+}
+
+// GetDeadLetterJobs returns the dead lettered jobs of the collection across all the shards
+func (dlh *deadLetterRestHandler) GetDeadLetterJobs(c *gin.Context) {
+	collection := c.Param("collection")
+
+	jobs, err := dlh.cordinatorProcess.ListDeadLetterJobs(collection)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs": jobs,
+	})
+}
+
+// RedriveJob schedules the dead lettered job again. The write concern can be passed in the write_concern query param
+func (dlh *deadLetterRestHandler) RedriveJob(c *gin.Context) {
+	collection := c.Param("collection")
+	jobID := c.Param("jobID")
+
+	result, err := dlh.cordinatorProcess.RedriveJob(c.Request.Context(), collection, jobID, getWriteOptions(c))
+	if err == jobmodels.ErrWriteConcernNotSatisfied {
+		abortWithWriteResult(c, result, err)
+		return
+	}
+	if abortWithShardMapMismatch(c, err) {
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dlh.log.Debug("Job redriven", zap.String("collection", collection), zap.String("jobID", jobID))
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "ok",
+		"offset":        result.Offset,
+		"write_concern": result.WriteConcern,
+		"acknowledged":  result.Acknowledged,
+		"replicas":      result.Replicas,
+	})
+}
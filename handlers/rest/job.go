@@ -1,14 +1,22 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/aarthikrao/timeMachine/components/jobstore"
 	"github.com/aarthikrao/timeMachine/models/jobmodels"
 	"github.com/aarthikrao/timeMachine/process/cordinator"
+	timeutil "github.com/aarthikrao/timeMachine/utils/time"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// The if_version query param is not a version
+var errInvalidIfVersion = errors.New("invalid if_version")
+
 type jobRestHandler struct {
 	cordinatorProcess *cordinator.CordinatorProcess
 	log               *zap.Logger
@@ -37,16 +45,110 @@ func (jrh *jobRestHandler) GetJob(c *gin.Context) {
 	c.JSON(http.StatusOK, job)
 }
 
+// ListJobs returns a page of the jobs of the collection. The jobs can be filtered by the from_ms and to_ms
+// range of their trigger time and by route. The next page is fetched by passing the next_cursor of the
+// response in the cursor query param along with the same filters.
+func (jrh *jobRestHandler) ListJobs(c *gin.Context) {
+	query := jobmodels.JobQuery{Route: c.Query("route")}
+
+	var err error
+	for param, value := range map[string]*int{
+		"from_ms": &query.FromMS,
+		"to_ms":   &query.ToMS,
+		"limit":   &query.Limit,
+	} {
+		if c.Query(param) == "" {
+			continue
+		}
+		if *value, err = strconv.Atoi(c.Query(param)); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+			return
+		}
+	}
+
+	if err = query.SetCursor(c.Query("cursor")); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := jrh.cordinatorProcess.ListJobs(c.Param("collection"), query)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetJobStatus returns the execution status of the job
+func (jrh *jobRestHandler) GetJobStatus(c *gin.Context) {
+	job, err := jrh.cordinatorProcess.GetJob(c.Param("collection"), c.Param("jobID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job.GetStatus())
+}
+
+// GetJobHistory returns the changes in the execution state of the job, oldest first
+func (jrh *jobRestHandler) GetJobHistory(c *gin.Context) {
+	job, err := jrh.cordinatorProcess.GetJob(c.Param("collection"), c.Param("jobID"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history := job.History
+	if history == nil {
+		history = []jobmodels.ExecutionEvent{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         job.ID,
+		"collection": job.Collection,
+		"history":    history,
+	})
+}
+
+// SetJob sets the job in the collection. The write concern can be passed in the
+// write_concern query param, else the default write concern of the collection is used.
+// If the if_version query param is passed, the job is set only if its current version matches.
+// A job with an idempotency_key that was already set with the key returns the result of the earlier set.
 func (jrh *jobRestHandler) SetJob(c *gin.Context) {
 	collection := c.Param("collection")
 
+	opts, err := getVersionedWriteOptions(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var job jobmodels.Job
 	if err := c.BindJSON(&job); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	offset, err := jrh.cordinatorProcess.SetJob(collection, &job)
+	// The execution details cannot be set by the clients. A job that is set again is scheduled
+	// again even if it was already delivered, and its history starts afresh
+	job.ResetExecution(timeutil.GetCurrentMillis())
+
+	// The publish of the job is linked to the trace of this request
+	job.TraceContext = tracing.Inject(c.Request.Context())
+
+	result, err := jrh.cordinatorProcess.SetJobWithOptions(c.Request.Context(), collection, &job, opts)
+	if err == jobmodels.ErrWriteConcernNotSatisfied {
+		abortWithWriteResult(c, result, err)
+		return
+	}
+	if abortWithShardMapMismatch(c, err) {
+		return
+	}
+	if err == jobmodels.ErrVersionConflict || err == jobmodels.ErrIdempotencyKeyReused {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -55,23 +157,177 @@ func (jrh *jobRestHandler) SetJob(c *gin.Context) {
 	jrh.log.Debug("Job set", zap.String("collection", collection), zap.Any("job", job))
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-		"offset": offset,
+		"status":        "ok",
+		"offset":        result.Offset,
+		"write_concern": result.WriteConcern,
+		"acknowledged":  result.Acknowledged,
+		"replicas":      result.Replicas,
+		"version":       result.Version,
+		"replayed":      result.Replayed,
 	})
 }
 
+// DeleteJob deletes the job from the collection. As with SetJob, the if_version query param can be passed
 func (jrh *jobRestHandler) DeleteJob(c *gin.Context) {
 	collection := c.Param("collection")
 	jobID := c.Param("jobID")
 
-	offset, err := jrh.cordinatorProcess.DeleteJob(collection, jobID)
+	opts, err := getVersionedWriteOptions(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := jrh.cordinatorProcess.DeleteJobWithOptions(c.Request.Context(), collection, jobID, opts)
+	if err == jobmodels.ErrWriteConcernNotSatisfied {
+		abortWithWriteResult(c, result, err)
+		return
+	}
+	if abortWithShardMapMismatch(c, err) {
+		return
+	}
+	if err == jobmodels.ErrVersionConflict {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":        "ok",
+		"offset":        result.Offset,
+		"write_concern": result.WriteConcern,
+		"acknowledged":  result.Acknowledged,
+		"replicas":      result.Replicas,
+	})
+}
+
+// SetJobs sets a batch of jobs in the collection. The result of every job is returned in the order of the jobs,
+// as a job may fail while the others are set. The write concern can be passed in the write_concern query param.
+func (jrh *jobRestHandler) SetJobs(c *gin.Context) {
+	var req struct {
+		Jobs []*jobmodels.Job `json:"jobs"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := timeutil.GetCurrentMillis()
+	traceContext := tracing.Inject(c.Request.Context())
+	for i, job := range req.Jobs {
+		if job == nil {
+			req.Jobs[i] = &jobmodels.Job{}
+			continue
+		}
+		job.ResetExecution(now)
+		job.TraceContext = traceContext
+	}
+
+	results, err := jrh.cordinatorProcess.SetJobs(c.Request.Context(), c.Param("collection"), req.Jobs, getWriteOptions(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// DeleteJobs deletes a batch of jobs from the collection. The result of every job is returned in the order of the IDs
+func (jrh *jobRestHandler) DeleteJobs(c *gin.Context) {
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := jrh.cordinatorProcess.DeleteJobs(c.Request.Context(), c.Param("collection"), req.IDs, getWriteOptions(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// CancelJob stops the job from being published. Unlike DeleteJob, the job and its history are retained.
+func (jrh *jobRestHandler) CancelJob(c *gin.Context) {
+	collection := c.Param("collection")
+	jobID := c.Param("jobID")
+
+	result, err := jrh.cordinatorProcess.CancelJobWithOptions(c.Request.Context(), collection, jobID, getWriteOptions(c))
+	if err == jobmodels.ErrWriteConcernNotSatisfied {
+		abortWithWriteResult(c, result, err)
+		return
+	}
+	if abortWithShardMapMismatch(c, err) {
+		return
+	}
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-		"offset": offset,
+		"status":        "ok",
+		"offset":        result.Offset,
+		"write_concern": result.WriteConcern,
+		"acknowledged":  result.Acknowledged,
+		"replicas":      result.Replicas,
+	})
+}
+
+func getWriteOptions(c *gin.Context) jobmodels.WriteOptions {
+	return jobmodels.WriteOptions{
+		WriteConcern: jobmodels.WriteConcern(c.Query("write_concern")),
+	}
+}
+
+// getVersionedWriteOptions also reads the version precondition from the if_version query param
+func getVersionedWriteOptions(c *gin.Context) (jobmodels.WriteOptions, error) {
+	opts := getWriteOptions(c)
+	if c.Query("if_version") == "" {
+		return opts, nil
+	}
+
+	ifVersion, err := strconv.Atoi(c.Query("if_version"))
+	if err != nil || ifVersion < 0 {
+		return opts, errInvalidIfVersion
+	}
+	opts.IfVersion = &ifVersion
+
+	return opts, nil
+}
+
+// abortWithWriteResult is used when the write is applied on the leader, but not enough replicas have acknowledged it
+func abortWithWriteResult(c *gin.Context, result jobmodels.WriteResult, err error) {
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+		"error":         err.Error(),
+		"offset":        result.Offset,
+		"write_concern": result.WriteConcern,
+		"acknowledged":  result.Acknowledged,
+		"replicas":      result.Replicas,
+	})
+}
+
+// abortWithShardMapMismatch is used when the write was forwarded in a loop, as the nodes do not agree on the leader
+// of its shard. The client can retry once the nodes have applied the same shard map. It returns false for the other errors
+func abortWithShardMapMismatch(c *gin.Context, err error) bool {
+	var mismatch *jobstore.ShardMapMismatchError
+	if !errors.As(err, &mismatch) {
+		return false
+	}
+
+	c.Header("Retry-After", "1")
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+		"error":       err.Error(),
+		"retryable":   true,
+		"node_id":     mismatch.NodeID,
+		"dht_version": mismatch.DHTVersion,
 	})
+	return true
 }
@@ -0,0 +1,34 @@
+package collectionmodels
+
+import (
+	"errors"
+
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+)
+
+// Collection contains the defaults applied to all the jobs in the collection
+type Collection struct {
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+
+	// Write concern used when the write request does not specify one
+	WriteConcern jm.WriteConcern `json:"write_concern,omitempty" bson:"write_concern,omitempty"`
+
+	// Misfire policy of the jobs that are picked up after the misfire threshold of the node
+	MisfirePolicy jm.MisfirePolicy `json:"misfire_policy,omitempty" bson:"misfire_policy,omitempty"`
+}
+
+var (
+	ErrInvalidCollectionName = errors.New("invalid collection name")
+)
+
+func (c Collection) Valid() error {
+	if len(c.Name) == 0 {
+		return ErrInvalidCollectionName
+	}
+
+	if err := c.WriteConcern.Valid(); err != nil {
+		return err
+	}
+
+	return c.MisfirePolicy.Valid()
+}
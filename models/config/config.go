@@ -3,4 +3,8 @@ package config
 type Config struct {
 	Shards   int `json:"shards,omitempty" bson:"shards,omitempty"`
 	Replicas int `json:"replicas,omitempty" bson:"replicas,omitempty"`
+
+	// Weights of the nodes, used when the shards are placed on a consistent hashing ring.
+	// Nodes without a weight have weight 1
+	Weights map[string]int `json:"weights,omitempty" bson:"weights,omitempty"`
 }
@@ -0,0 +1,29 @@
+package jobmodels
+
+import "errors"
+
+// Maximum number of jobs in a batch request
+const MaxBatchSize = 1000
+
+var (
+	// The batch has no jobs or more than MaxBatchSize jobs
+	ErrInvalidBatchSize = errors.New("batch must contain between 1 and 1000 jobs")
+)
+
+// BatchResult is the outcome of the write of a job in a batch. The jobs of a shard are written
+// together, hence they have the same write result. Error is set if the job was not written, or if
+// the write concern was not satisfied.
+type BatchResult struct {
+	ID string `json:"id"`
+	WriteResult
+	Error string `json:"error,omitempty"`
+}
+
+// ValidBatchSize returns ErrInvalidBatchSize if the batch is empty or too large
+func ValidBatchSize(size int) error {
+	if size <= 0 || size > MaxBatchSize {
+		return ErrInvalidBatchSize
+	}
+
+	return nil
+}
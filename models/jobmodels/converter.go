@@ -0,0 +1,107 @@
+package jobmodels
+
+// ToCreationDetails converts the job to the GRPC message
+func (j *Job) ToCreationDetails(collection string) *JobCreationDetails {
+	jd := &JobCreationDetails{
+		ID:              j.ID,
+		TriggerTime:     int64(j.TriggerMS),
+		Meta:            j.Meta,
+		Route:           j.Route,
+		Collection:      collection,
+		State:           string(j.State),
+		DispatchedTime:  int64(j.DispatchedMS),
+		Attempts:        int64(j.Attempts),
+		NextAttemptTime: int64(j.NextAttemptMS),
+		LastError:       j.LastError,
+		ClaimedBy:       j.ClaimedBy,
+		Version:         int64(j.Version),
+		IdempotencyKey:  j.IdempotencyKey,
+		TraceContext:    j.TraceContext,
+	}
+
+	if j.Recurrence != nil {
+		jd.Recurrence = &JobRecurrence{
+			Cron:           j.Recurrence.Cron,
+			IntervalMS:     int64(j.Recurrence.IntervalMS),
+			EndMS:          int64(j.Recurrence.EndMS),
+			MaxOccurrences: int64(j.Recurrence.MaxOccurrences),
+			Occurrences:    int64(j.Recurrence.Occurrences),
+		}
+	}
+
+	for _, event := range j.History {
+		jd.History = append(jd.History, &JobExecutionEvent{
+			State:        string(event.State),
+			Time:         int64(event.TimeMS),
+			ResponseCode: int32(event.ResponseCode),
+			Error:        event.Error,
+		})
+	}
+
+	return jd
+}
+
+// GetJobFromCreationDetails converts the GRPC message to job
+func GetJobFromCreationDetails(jd *JobCreationDetails) *Job {
+	j := &Job{
+		ID:             jd.ID,
+		TriggerMS:      int(jd.TriggerTime),
+		Meta:           jd.Meta,
+		Route:          jd.Route,
+		Collection:     jd.Collection,
+		State:          ExecutionState(jd.State),
+		DispatchedMS:   int(jd.DispatchedTime),
+		Attempts:       int(jd.Attempts),
+		NextAttemptMS:  int(jd.NextAttemptTime),
+		LastError:      jd.LastError,
+		ClaimedBy:      jd.ClaimedBy,
+		Version:        int(jd.Version),
+		IdempotencyKey: jd.IdempotencyKey,
+		TraceContext:   jd.TraceContext,
+	}
+
+	if jd.Recurrence != nil {
+		j.Recurrence = &Recurrence{
+			Cron:           jd.Recurrence.Cron,
+			IntervalMS:     int(jd.Recurrence.IntervalMS),
+			EndMS:          int(jd.Recurrence.EndMS),
+			MaxOccurrences: int(jd.Recurrence.MaxOccurrences),
+			Occurrences:    int(jd.Recurrence.Occurrences),
+		}
+	}
+
+	for _, event := range jd.History {
+		j.History = append(j.History, ExecutionEvent{
+			State:        ExecutionState(event.State),
+			TimeMS:       int(event.Time),
+			ResponseCode: int(event.ResponseCode),
+			Error:        event.Error,
+		})
+	}
+
+	return j
+}
+
+// ToWriteResponse converts the write result to the GRPC message
+func (wr WriteResult) ToWriteResponse() *WriteResponse {
+	return &WriteResponse{
+		Offset:       wr.Offset,
+		WriteConcern: string(wr.WriteConcern),
+		Acknowledged: int32(wr.Acknowledged),
+		Replicas:     int32(wr.Replicas),
+		Version:      int64(wr.Version),
+		Replayed:     wr.Replayed,
+	}
+}
+
+// GetWriteResultFromResponse converts the GRPC message to write result
+func GetWriteResultFromResponse(resp *WriteResponse) WriteResult {
+	return WriteResult{
+		Offset:       resp.Offset,
+		WriteConcern: WriteConcern(resp.WriteConcern),
+		Acknowledged: int(resp.Acknowledged),
+		Replicas:     int(resp.Replicas),
+		Version:      int(resp.Version),
+		Replayed:     resp.Replayed,
+	}
+}
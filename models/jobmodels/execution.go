@@ -0,0 +1,190 @@
+package jobmodels
+
+import "errors"
+
+// ExecutionState tracks the delivery of a job. It is stored with the job and replicated
+// to the followers, so that a newly promoted shard leader knows which jobs are already delivered.
+type ExecutionState string
+
+const (
+	// The job is waiting for its trigger time. Jobs stored without a state are also scheduled
+	ExecutionScheduled ExecutionState = "scheduled"
+
+	// The job has been claimed by the shard leader and is being published
+	ExecutionDispatched ExecutionState = "dispatched"
+
+	// The job could not be published and will be retried at its next attempt time
+	ExecutionRetrying ExecutionState = "retrying"
+
+	// The job has been published to its route
+	ExecutionDelivered ExecutionState = "delivered"
+
+	// The job missed its trigger time and was not published as per the misfire policy
+	ExecutionSkipped ExecutionState = "skipped"
+
+	// The job was not published and has been moved to the dead letter state
+	ExecutionDeadLettered ExecutionState = "dead_lettered"
+
+	// The job was cancelled before it was published
+	ExecutionCancelled ExecutionState = "cancelled"
+)
+
+// Number of execution events retained in the history of a job. The oldest events are removed first
+const MaxExecutionHistory = 20
+
+var (
+	// The job cannot move from its current execution state to the requested one
+	ErrInvalidExecutionTransition = errors.New("invalid execution state transition")
+
+	// The job was updated after it was queued for execution
+	ErrJobChanged = errors.New("job changed after it was queued")
+
+	// The job has already been dispatched with the same claim
+	ErrAlreadyDispatched = errors.New("job already dispatched")
+)
+
+// CanTransitionTo returns true if a job in this state can move to the next state.
+// A dispatched job is dispatched again only with a new claim, see Job.ApplyExecution.
+func (es ExecutionState) CanTransitionTo(next ExecutionState) bool {
+	switch es {
+	case "", ExecutionScheduled, ExecutionRetrying:
+		return next == ExecutionDispatched || next == ExecutionSkipped || next == ExecutionDeadLettered || next == ExecutionCancelled
+	case ExecutionDispatched:
+		// The publish may be in progress, hence the job cannot be cancelled
+		return next != "" && next != ExecutionScheduled && next != ExecutionCancelled && next != ExecutionDispatched
+	}
+
+	return false
+}
+
+// IsPending returns true if the job is yet to be delivered, skipped or dead lettered.
+// A dispatched job is pending, as the leader that claimed it may fail before the delivery is recorded.
+func (es ExecutionState) IsPending() bool {
+	switch es {
+	case "", ExecutionScheduled, ExecutionDispatched, ExecutionRetrying:
+		return true
+	}
+
+	return false
+}
+
+// ExecutionUpdate moves a stored job to the next execution state
+type ExecutionUpdate struct {
+	State ExecutionState
+
+	// Error of the failed attempt. Used while retrying or dead lettering the job
+	Error string
+
+	// Time of the next retry in milliseconds. Used while retrying the job
+	NextAttemptMS int
+
+	// Response code returned by the route. Only set for the HTTP routes
+	ResponseCode int
+
+	// Claim of the shard leader dispatching the job. Used while dispatching the job
+	ClaimedBy string
+}
+
+// ExecutionEvent records a change in the execution state of a job
+type ExecutionEvent struct {
+	State  ExecutionState `json:"state" bson:"state"`
+	TimeMS int            `json:"time_ms" bson:"time_ms"`
+
+	// Response code of the route and the error of the attempt, if any
+	ResponseCode int    `json:"response_code,omitempty" bson:"response_code,omitempty"`
+	Error        string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// ApplyExecution moves the job to the state of the update. It returns ErrInvalidExecutionTransition
+// if the job cannot move to the state.
+//
+// A dispatched job is dispatched again only with a different claim, i.e. by a new shard leader or by the
+// leader after a restart, as the leader that claimed it may have failed before the delivery was recorded.
+// It returns ErrAlreadyDispatched for the same claim.
+func (j *Job) ApplyExecution(update ExecutionUpdate, nowMS int) error {
+	if j.State == ExecutionDispatched && update.State == ExecutionDispatched {
+		if update.ClaimedBy == "" || update.ClaimedBy == j.ClaimedBy {
+			return ErrAlreadyDispatched
+		}
+	} else if !j.State.CanTransitionTo(update.State) {
+		return ErrInvalidExecutionTransition
+	}
+
+	switch update.State {
+	case ExecutionDispatched:
+		j.DispatchedMS = nowMS
+		j.ClaimedBy = update.ClaimedBy
+		j.Attempts++
+	case ExecutionRetrying:
+		j.NextAttemptMS = update.NextAttemptMS
+	}
+
+	if update.Error != "" {
+		j.LastError = update.Error
+	}
+
+	j.State = update.State
+	j.addEvent(ExecutionEvent{
+		State:        update.State,
+		TimeMS:       nowMS,
+		ResponseCode: update.ResponseCode,
+		Error:        update.Error,
+	})
+	return nil
+}
+
+// JobStatus is the current execution status of a job
+type JobStatus struct {
+	ID         string         `json:"id"`
+	Collection string         `json:"collection"`
+	State      ExecutionState `json:"state"`
+	TriggerMS  int            `json:"trigger_ms"`
+
+	Attempts      int `json:"attempts"`
+	DispatchedMS  int `json:"dispatched_ms,omitempty"`
+	NextAttemptMS int `json:"next_attempt_ms,omitempty"`
+
+	// Details of the last execution event
+	UpdatedMS    int    `json:"updated_ms,omitempty"`
+	ResponseCode int    `json:"response_code,omitempty"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+// GetStatus returns the execution status of the job. Jobs stored without a state are scheduled
+func (j *Job) GetStatus() JobStatus {
+	status := JobStatus{
+		ID:           j.ID,
+		Collection:   j.Collection,
+		State:        j.State,
+		TriggerMS:    j.TriggerMS,
+		Attempts:     j.Attempts,
+		DispatchedMS: j.DispatchedMS,
+		LastError:    j.LastError,
+	}
+	if status.State == "" {
+		status.State = ExecutionScheduled
+	}
+	if j.State == ExecutionRetrying {
+		status.NextAttemptMS = j.NextAttemptMS
+	}
+	if len(j.History) > 0 {
+		last := j.History[len(j.History)-1]
+		status.UpdatedMS = last.TimeMS
+		status.ResponseCode = last.ResponseCode
+	}
+
+	return status
+}
+
+// addEvent appends the event to the history of the job, retaining the latest MaxExecutionHistory events.
+// A new slice is created as the history may be shared with a copy of the job
+func (j *Job) addEvent(event ExecutionEvent) {
+	start := 0
+	if len(j.History) >= MaxExecutionHistory {
+		start = len(j.History) - MaxExecutionHistory + 1
+	}
+
+	history := make([]ExecutionEvent, 0, len(j.History)-start+1)
+	history = append(history, j.History[start:]...)
+	j.History = append(history, event)
+}
@@ -0,0 +1,141 @@
+package jobmodels
+
+import "testing"
+
+func TestExecutionState_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from ExecutionState
+		to   ExecutionState
+		want bool
+	}{
+		{from: "", to: ExecutionDispatched, want: true},
+		{from: ExecutionScheduled, to: ExecutionDispatched, want: true},
+		{from: ExecutionScheduled, to: ExecutionDelivered, want: false},
+		{from: ExecutionScheduled, to: ExecutionSkipped, want: true},
+		{from: ExecutionScheduled, to: ExecutionDeadLettered, want: true},
+		{from: ExecutionDispatched, to: ExecutionDispatched, want: false},
+		{from: ExecutionDispatched, to: ExecutionDelivered, want: true},
+		{from: ExecutionDispatched, to: ExecutionSkipped, want: true},
+		{from: ExecutionDispatched, to: ExecutionScheduled, want: false},
+		{from: ExecutionDelivered, to: ExecutionDispatched, want: false},
+		{from: ExecutionDelivered, to: ExecutionDelivered, want: false},
+		{from: ExecutionSkipped, to: ExecutionDispatched, want: false},
+		{from: ExecutionDeadLettered, to: ExecutionDispatched, want: false},
+		{from: ExecutionScheduled, to: ExecutionCancelled, want: true},
+		{from: ExecutionRetrying, to: ExecutionCancelled, want: true},
+		{from: ExecutionDispatched, to: ExecutionCancelled, want: false},
+		{from: ExecutionDelivered, to: ExecutionCancelled, want: false},
+		{from: ExecutionCancelled, to: ExecutionDispatched, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%q.CanTransitionTo(%q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestExecutionState_IsPending(t *testing.T) {
+	tests := []struct {
+		es   ExecutionState
+		want bool
+	}{
+		{es: "", want: true},
+		{es: ExecutionScheduled, want: true},
+		{es: ExecutionDispatched, want: true},
+		{es: ExecutionDelivered, want: false},
+		{es: ExecutionSkipped, want: false},
+		{es: ExecutionDeadLettered, want: false},
+		{es: ExecutionCancelled, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.es.IsPending(); got != tt.want {
+			t.Errorf("%q.IsPending() = %v, want %v", tt.es, got, tt.want)
+		}
+	}
+}
+
+func TestJob_ApplyExecution(t *testing.T) {
+	now := 28000000 * 60000
+	job := &Job{ID: "job1", TriggerMS: now, State: ExecutionScheduled}
+
+	steps := []struct {
+		update       ExecutionUpdate
+		wantErr      error
+		wantAttempts int
+		wantBucket   int
+	}{
+		{update: ExecutionUpdate{State: ExecutionDelivered}, wantErr: ErrInvalidExecutionTransition, wantBucket: now},
+		{update: ExecutionUpdate{State: ExecutionDispatched, ClaimedBy: "node1/1/1"}, wantAttempts: 1, wantBucket: now},
+		{update: ExecutionUpdate{State: ExecutionRetrying, Error: "timeout", NextAttemptMS: now + 120000}, wantAttempts: 1, wantBucket: now + 120000},
+		{update: ExecutionUpdate{State: ExecutionDispatched, ClaimedBy: "node1/1/1"}, wantAttempts: 2, wantBucket: now},
+		{update: ExecutionUpdate{State: ExecutionDispatched, ClaimedBy: "node1/1/1"}, wantErr: ErrAlreadyDispatched, wantAttempts: 2, wantBucket: now},
+		{update: ExecutionUpdate{State: ExecutionDispatched}, wantErr: ErrAlreadyDispatched, wantAttempts: 2, wantBucket: now},
+		{update: ExecutionUpdate{State: ExecutionDispatched, ClaimedBy: "node2/2/1"}, wantAttempts: 3, wantBucket: now},
+		{update: ExecutionUpdate{State: ExecutionDeadLettered, Error: "timeout again"}, wantAttempts: 3, wantBucket: now},
+		{update: ExecutionUpdate{State: ExecutionDispatched, ClaimedBy: "node3/3/1"}, wantErr: ErrInvalidExecutionTransition, wantAttempts: 3, wantBucket: now},
+	}
+
+	for i, step := range steps {
+		if err := job.ApplyExecution(step.update, now); err != step.wantErr {
+			t.Errorf("step %d: ApplyExecution() error = %v, wantErr %v", i, err, step.wantErr)
+		}
+		if job.Attempts != step.wantAttempts {
+			t.Errorf("step %d: attempts = %d, want %d", i, job.Attempts, step.wantAttempts)
+		}
+		if got := job.GetTriggerTime().UnixMilli(); got != int64(step.wantBucket) {
+			t.Errorf("step %d: trigger time = %d, want %d", i, got, step.wantBucket)
+		}
+	}
+
+	if job.LastError != "timeout again" {
+		t.Errorf("last error = %q, want %q", job.LastError, "timeout again")
+	}
+}
+
+func TestJob_History(t *testing.T) {
+	job := &Job{ID: "job1", TriggerMS: 1000, State: ExecutionDelivered, Attempts: 3}
+	job.ResetExecution(100)
+
+	if job.State != ExecutionScheduled || job.Attempts != 0 || len(job.History) != 1 {
+		t.Fatalf("ResetExecution() = %+v, want a scheduled job with one event", job)
+	}
+
+	job.ApplyExecution(ExecutionUpdate{State: ExecutionDispatched}, 1000)
+	job.ApplyExecution(ExecutionUpdate{State: ExecutionRetrying, Error: "bad gateway", ResponseCode: 502, NextAttemptMS: 2000}, 1010)
+
+	status := job.GetStatus()
+	want := JobStatus{
+		ID:            "job1",
+		State:         ExecutionRetrying,
+		TriggerMS:     1000,
+		Attempts:      1,
+		DispatchedMS:  1000,
+		NextAttemptMS: 2000,
+		UpdatedMS:     1010,
+		ResponseCode:  502,
+		LastError:     "bad gateway",
+	}
+	if status != want {
+		t.Errorf("GetStatus() = %+v, want %+v", status, want)
+	}
+
+	// The history of a copy of the job is not changed
+	copied := *job
+	copied.Reschedule(1500)
+	if len(job.History) != 3 || len(copied.History) != 4 {
+		t.Errorf("history lengths = %d and %d, want 3 and 4", len(job.History), len(copied.History))
+	}
+
+	for i := 0; i < MaxExecutionHistory; i++ {
+		job.ApplyExecution(ExecutionUpdate{State: ExecutionDispatched}, 3000+i)
+		job.ApplyExecution(ExecutionUpdate{State: ExecutionRetrying}, 3000+i)
+	}
+	if len(job.History) != MaxExecutionHistory {
+		t.Errorf("history length = %d, want %d", len(job.History), MaxExecutionHistory)
+	}
+	if last := job.History[len(job.History)-1]; last.TimeMS != 3000+MaxExecutionHistory-1 {
+		t.Errorf("last event time = %d, want %d", last.TimeMS, 3000+MaxExecutionHistory-1)
+	}
+}
@@ -0,0 +1,27 @@
+package jobmodels
+
+import "errors"
+
+// Maximum length of the idempotency key of a job
+const MaxIdempotencyKeyLength = 256
+
+var (
+	// The idempotency key was used to set a different job within the idempotency window
+	ErrIdempotencyKeyReused = errors.New("idempotency key is already used by another job")
+
+	// The idempotency keys are only honoured while setting a single job
+	ErrIdempotencyKeyInBatch = errors.New("idempotency key is not supported in batch writes")
+)
+
+// IdempotencyRecord is the outcome of the first set of a job with an idempotency key.
+// The sets of the job with the same key within the idempotency window return it instead of writing again.
+type IdempotencyRecord struct {
+	JobID string `json:"job_id"`
+
+	// Wal offset and the version of the job of the first set
+	Offset  int64 `json:"offset"`
+	Version int   `json:"version"`
+
+	// Time the record was applied on this replica in milliseconds
+	CreatedMS int `json:"created_ms"`
+}
@@ -16,6 +16,38 @@ type Job struct {
 	TriggerMS int             `json:"trigger_ms,omitempty" bson:"trigger_ms,omitempty"`
 	Meta      json.RawMessage `json:"meta,omitempty" bson:"meta,omitempty"`
 	Route     string          `json:"route,omitempty" bson:"route,omitempty"`
+
+	// Collection the job belongs to. It is set by time machine when the job is stored
+	Collection string `json:"collection,omitempty" bson:"collection,omitempty"`
+
+	// Recurrence is set for jobs that have to be rescheduled after they are triggered
+	Recurrence *Recurrence `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+
+	// Execution state of the job and the last time it was dispatched. It is set by time machine
+	State        ExecutionState `json:"state,omitempty" bson:"state,omitempty"`
+	DispatchedMS int            `json:"dispatched_ms,omitempty" bson:"dispatched_ms,omitempty"`
+
+	// Number of times the job was dispatched, the time of the next retry in milliseconds
+	// and the error of the last failed attempt. They are set by time machine
+	Attempts      int    `json:"attempts,omitempty" bson:"attempts,omitempty"`
+	NextAttemptMS int    `json:"next_attempt_ms,omitempty" bson:"next_attempt_ms,omitempty"`
+	LastError     string `json:"last_error,omitempty" bson:"last_error,omitempty"`
+
+	// Claim of the shard leader that last dispatched the job. A dispatched job is claimed again only
+	// with a different claim, see ApplyExecution. It is set by time machine
+	ClaimedBy string `json:"claimed_by,omitempty" bson:"claimed_by,omitempty"`
+
+	// Changes in the execution state of the job, oldest first. It is set by time machine
+	History []ExecutionEvent `json:"history,omitempty" bson:"history,omitempty"`
+
+	// Version of the job. It is set by time machine and incremented every time the job is set
+	Version int `json:"version,omitempty" bson:"version,omitempty"`
+
+	// The job is set only once for the same key within the idempotency window, so that a set can be retried safely
+	IdempotencyKey string `json:"idempotency_key,omitempty" bson:"idempotency_key,omitempty"`
+
+	// W3C trace context of the request that set the job. The publish of the job is linked to it. It is set by time machine
+	TraceContext map[string]string `json:"trace_context,omitempty" bson:"trace_context,omitempty"`
 }
 
 func (j *Job) Valid() error {
@@ -28,13 +60,50 @@ func (j *Job) Valid() error {
 	if j.Route == "" {
 		return fmt.Errorf("invalid route")
 	}
+	if len(j.IdempotencyKey) > MaxIdempotencyKeyLength {
+		return fmt.Errorf("idempotency_key is longer than %d characters", MaxIdempotencyKeyLength)
+	}
+	if j.Recurrence != nil {
+		if err := j.Recurrence.Valid(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// ResetExecution clears the execution details and the history, so that the job is scheduled afresh
+func (j *Job) ResetExecution(nowMS int) {
+	j.History = nil
+	j.Reschedule(nowMS)
+}
+
+// Reschedule clears the execution details, so that the job is scheduled again.
+// The history of the job is retained
+func (j *Job) Reschedule(nowMS int) {
+	j.State = ExecutionScheduled
+	j.DispatchedMS = 0
+	j.Attempts = 0
+	j.NextAttemptMS = 0
+	j.LastError = ""
+	j.ClaimedBy = ""
+	j.addEvent(ExecutionEvent{State: ExecutionScheduled, TimeMS: nowMS})
+}
+
+// getScheduledMS returns the time of the next retry if the job is being retried, else the trigger time
+func (j *Job) getScheduledMS() int {
+	if j.State == ExecutionRetrying && j.NextAttemptMS > 0 {
+		return j.NextAttemptMS
+	}
+
+	return j.TriggerMS
+}
+
+// GetMinuteBucketName returns the minute bucket in which the job is scheduled.
+// Jobs being retried are scheduled in the minute of their next retry
 func (j *Job) GetMinuteBucketName() []byte {
 	// Get the minutes since epoch
-	jobMinute := j.TriggerMS / 60000
+	jobMinute := j.getScheduledMS() / 60000
 
 	return []byte(strconv.Itoa(jobMinute))
 }
@@ -49,7 +118,7 @@ func (j *Job) GetUniqueKey(collection string) []byte {
 }
 
 func (j *Job) StringifyTriggerTime() []byte {
-	return []byte(fmt.Sprintf("%d", j.TriggerMS))
+	return []byte(fmt.Sprintf("%d", j.getScheduledMS()))
 }
 
 // TODO: Change to msgpack later
@@ -69,6 +138,8 @@ func GetJobFromBytes(by []byte) (*Job, error) {
 	return &j, nil
 }
 
+// GetTriggerTime returns the time at which the job has to be published.
+// It is the time of the next retry for the jobs being retried
 func (job *Job) GetTriggerTime() time.Time {
-	return time.UnixMilli(int64(job.TriggerMS))
+	return time.UnixMilli(int64(job.getScheduledMS()))
 }
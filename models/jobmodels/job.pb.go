@@ -26,11 +26,35 @@ type JobCreationDetails struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ID          string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	TriggerTime int64  `protobuf:"varint,2,opt,name=TriggerTime,proto3" json:"TriggerTime,omitempty"`
-	Meta        []byte `protobuf:"bytes,3,opt,name=Meta,proto3" json:"Meta,omitempty"`
-	Route       string `protobuf:"bytes,4,opt,name=Route,proto3" json:"Route,omitempty"`
-	Collection  string `protobuf:"bytes,5,opt,name=Collection,proto3" json:"Collection,omitempty"`
+	ID          string         `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	TriggerTime int64          `protobuf:"varint,2,opt,name=TriggerTime,proto3" json:"TriggerTime,omitempty"`
+	Meta        []byte         `protobuf:"bytes,3,opt,name=Meta,proto3" json:"Meta,omitempty"`
+	Route       string         `protobuf:"bytes,4,opt,name=Route,proto3" json:"Route,omitempty"`
+	Collection  string         `protobuf:"bytes,5,opt,name=Collection,proto3" json:"Collection,omitempty"`
+	Recurrence  *JobRecurrence `protobuf:"bytes,6,opt,name=Recurrence,proto3" json:"Recurrence,omitempty"`
+	// Used only while replicating. Contains the wal offset of the write on the leader shard
+	Offset int64 `protobuf:"varint,7,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	// one, quorum or all. Empty value means the default of the collection
+	WriteConcern string `protobuf:"bytes,8,opt,name=WriteConcern,proto3" json:"WriteConcern,omitempty"`
+	// Execution state of the job and the last time it was dispatched in milliseconds
+	State          string `protobuf:"bytes,9,opt,name=State,proto3" json:"State,omitempty"`
+	DispatchedTime int64  `protobuf:"varint,10,opt,name=DispatchedTime,proto3" json:"DispatchedTime,omitempty"`
+	// Number of attempts, the time of the next retry in milliseconds and the error of the last attempt
+	Attempts        int64  `protobuf:"varint,11,opt,name=Attempts,proto3" json:"Attempts,omitempty"`
+	NextAttemptTime int64  `protobuf:"varint,12,opt,name=NextAttemptTime,proto3" json:"NextAttemptTime,omitempty"`
+	LastError       string `protobuf:"bytes,13,opt,name=LastError,proto3" json:"LastError,omitempty"`
+	// Changes in the execution state of the job, oldest first
+	History []*JobExecutionEvent `protobuf:"bytes,14,rep,name=History,proto3" json:"History,omitempty"`
+	// Version of the job. It is incremented on every set
+	Version int64 `protobuf:"varint,15,opt,name=Version,proto3" json:"Version,omitempty"`
+	// Used only while setting. The job is set only if its current version matches. 0 means the job must not exist
+	IfVersion *int64 `protobuf:"varint,16,opt,name=IfVersion,proto3,oneof" json:"IfVersion,omitempty"`
+	// Writes of the job with the same key are applied only once within the idempotency window
+	IdempotencyKey string `protobuf:"bytes,17,opt,name=IdempotencyKey,proto3" json:"IdempotencyKey,omitempty"`
+	// W3C trace context of the request that set the job
+	TraceContext map[string]string `protobuf:"bytes,18,rep,name=TraceContext,proto3" json:"TraceContext,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Claim of the shard leader that last dispatched the job
+	ClaimedBy string `protobuf:"bytes,19,opt,name=ClaimedBy,proto3" json:"ClaimedBy,omitempty"`
 }
 
 func (x *JobCreationDetails) Reset() {
@@ -100,6 +124,256 @@ func (x *JobCreationDetails) GetCollection() string {
 	return ""
 }
 
+func (x *JobCreationDetails) GetRecurrence() *JobRecurrence {
+	if x != nil {
+		return x.Recurrence
+	}
+	return nil
+}
+
+func (x *JobCreationDetails) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *JobCreationDetails) GetWriteConcern() string {
+	if x != nil {
+		return x.WriteConcern
+	}
+	return ""
+}
+
+func (x *JobCreationDetails) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *JobCreationDetails) GetDispatchedTime() int64 {
+	if x != nil {
+		return x.DispatchedTime
+	}
+	return 0
+}
+
+func (x *JobCreationDetails) GetAttempts() int64 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *JobCreationDetails) GetNextAttemptTime() int64 {
+	if x != nil {
+		return x.NextAttemptTime
+	}
+	return 0
+}
+
+func (x *JobCreationDetails) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *JobCreationDetails) GetHistory() []*JobExecutionEvent {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *JobCreationDetails) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *JobCreationDetails) GetIfVersion() int64 {
+	if x != nil && x.IfVersion != nil {
+		return *x.IfVersion
+	}
+	return 0
+}
+
+func (x *JobCreationDetails) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *JobCreationDetails) GetTraceContext() map[string]string {
+	if x != nil {
+		return x.TraceContext
+	}
+	return nil
+}
+
+func (x *JobCreationDetails) GetClaimedBy() string {
+	if x != nil {
+		return x.ClaimedBy
+	}
+	return ""
+}
+
+// Records a change in the execution state of a job
+type JobExecutionEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State        string `protobuf:"bytes,1,opt,name=State,proto3" json:"State,omitempty"`
+	Time         int64  `protobuf:"varint,2,opt,name=Time,proto3" json:"Time,omitempty"`
+	ResponseCode int32  `protobuf:"varint,3,opt,name=ResponseCode,proto3" json:"ResponseCode,omitempty"`
+	Error        string `protobuf:"bytes,4,opt,name=Error,proto3" json:"Error,omitempty"`
+}
+
+func (x *JobExecutionEvent) Reset() {
+	*x = JobExecutionEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_models_jobmodels_job_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobExecutionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobExecutionEvent) ProtoMessage() {}
+
+func (x *JobExecutionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_models_jobmodels_job_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobExecutionEvent.ProtoReflect.Descriptor instead.
+func (*JobExecutionEvent) Descriptor() ([]byte, []int) {
+	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *JobExecutionEvent) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *JobExecutionEvent) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *JobExecutionEvent) GetResponseCode() int32 {
+	if x != nil {
+		return x.ResponseCode
+	}
+	return 0
+}
+
+func (x *JobExecutionEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Used to reschedule the recurring jobs
+type JobRecurrence struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cron           string `protobuf:"bytes,1,opt,name=Cron,proto3" json:"Cron,omitempty"`
+	IntervalMS     int64  `protobuf:"varint,2,opt,name=IntervalMS,proto3" json:"IntervalMS,omitempty"`
+	EndMS          int64  `protobuf:"varint,3,opt,name=EndMS,proto3" json:"EndMS,omitempty"`
+	MaxOccurrences int64  `protobuf:"varint,4,opt,name=MaxOccurrences,proto3" json:"MaxOccurrences,omitempty"`
+	Occurrences    int64  `protobuf:"varint,5,opt,name=Occurrences,proto3" json:"Occurrences,omitempty"`
+}
+
+func (x *JobRecurrence) Reset() {
+	*x = JobRecurrence{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_models_jobmodels_job_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobRecurrence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobRecurrence) ProtoMessage() {}
+
+func (x *JobRecurrence) ProtoReflect() protoreflect.Message {
+	mi := &file_models_jobmodels_job_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobRecurrence.ProtoReflect.Descriptor instead.
+func (*JobRecurrence) Descriptor() ([]byte, []int) {
+	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *JobRecurrence) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *JobRecurrence) GetIntervalMS() int64 {
+	if x != nil {
+		return x.IntervalMS
+	}
+	return 0
+}
+
+func (x *JobRecurrence) GetEndMS() int64 {
+	if x != nil {
+		return x.EndMS
+	}
+	return 0
+}
+
+func (x *JobRecurrence) GetMaxOccurrences() int64 {
+	if x != nil {
+		return x.MaxOccurrences
+	}
+	return 0
+}
+
+func (x *JobRecurrence) GetOccurrences() int64 {
+	if x != nil {
+		return x.Occurrences
+	}
+	return 0
+}
+
 // Used to fetch and delete job
 type JobFetchDetails struct {
 	state         protoimpl.MessageState
@@ -108,12 +382,18 @@ type JobFetchDetails struct {
 
 	ID         string `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 	Collection string `protobuf:"bytes,2,opt,name=Collection,proto3" json:"Collection,omitempty"`
+	// Used only while replicating. Contains the wal offset of the delete on the leader shard
+	Offset int64 `protobuf:"varint,3,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	// Used only while deleting. one, quorum or all. Empty value means the default of the collection
+	WriteConcern string `protobuf:"bytes,4,opt,name=WriteConcern,proto3" json:"WriteConcern,omitempty"`
+	// Used only while deleting. The job is deleted only if its current version matches
+	IfVersion *int64 `protobuf:"varint,5,opt,name=IfVersion,proto3,oneof" json:"IfVersion,omitempty"`
 }
 
 func (x *JobFetchDetails) Reset() {
 	*x = JobFetchDetails{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_models_jobmodels_job_proto_msgTypes[1]
+		mi := &file_models_jobmodels_job_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -126,7 +406,7 @@ func (x *JobFetchDetails) String() string {
 func (*JobFetchDetails) ProtoMessage() {}
 
 func (x *JobFetchDetails) ProtoReflect() protoreflect.Message {
-	mi := &file_models_jobmodels_job_proto_msgTypes[1]
+	mi := &file_models_jobmodels_job_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -139,7 +419,7 @@ func (x *JobFetchDetails) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JobFetchDetails.ProtoReflect.Descriptor instead.
 func (*JobFetchDetails) Descriptor() ([]byte, []int) {
-	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{1}
+	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *JobFetchDetails) GetID() string {
@@ -156,6 +436,121 @@ func (x *JobFetchDetails) GetCollection() string {
 	return ""
 }
 
+func (x *JobFetchDetails) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *JobFetchDetails) GetWriteConcern() string {
+	if x != nil {
+		return x.WriteConcern
+	}
+	return ""
+}
+
+func (x *JobFetchDetails) GetIfVersion() int64 {
+	if x != nil && x.IfVersion != nil {
+		return *x.IfVersion
+	}
+	return 0
+}
+
+// Returned for all the write operations
+type WriteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// wal offset of the write on the shard
+	Offset int64 `protobuf:"varint,1,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	// Write concern used for the write and the number of replicas including the leader
+	// that acknowledged it. Not set while replicating
+	WriteConcern string `protobuf:"bytes,2,opt,name=WriteConcern,proto3" json:"WriteConcern,omitempty"`
+	Acknowledged int32  `protobuf:"varint,3,opt,name=Acknowledged,proto3" json:"Acknowledged,omitempty"`
+	Replicas     int32  `protobuf:"varint,4,opt,name=Replicas,proto3" json:"Replicas,omitempty"`
+	// Version of the job after a set
+	Version int64 `protobuf:"varint,5,opt,name=Version,proto3" json:"Version,omitempty"`
+	// True if the set was not applied as the job was already set with its idempotency key.
+	// The offset and the version are of the earlier set
+	Replayed bool `protobuf:"varint,6,opt,name=Replayed,proto3" json:"Replayed,omitempty"`
+}
+
+func (x *WriteResponse) Reset() {
+	*x = WriteResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_models_jobmodels_job_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteResponse) ProtoMessage() {}
+
+func (x *WriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_models_jobmodels_job_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteResponse.ProtoReflect.Descriptor instead.
+func (*WriteResponse) Descriptor() ([]byte, []int) {
+	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WriteResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *WriteResponse) GetWriteConcern() string {
+	if x != nil {
+		return x.WriteConcern
+	}
+	return ""
+}
+
+func (x *WriteResponse) GetAcknowledged() int32 {
+	if x != nil {
+		return x.Acknowledged
+	}
+	return 0
+}
+
+func (x *WriteResponse) GetReplicas() int32 {
+	if x != nil {
+		return x.Replicas
+	}
+	return 0
+}
+
+func (x *WriteResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *WriteResponse) GetReplayed() bool {
+	if x != nil {
+		return x.Replayed
+	}
+	return false
+}
+
 // Empty message because grpc doesnt allow methods without return
 type Empty struct {
 	state         protoimpl.MessageState
@@ -166,7 +561,7 @@ type Empty struct {
 func (x *Empty) Reset() {
 	*x = Empty{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_models_jobmodels_job_proto_msgTypes[2]
+		mi := &file_models_jobmodels_job_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -179,7 +574,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_models_jobmodels_job_proto_msgTypes[2]
+	mi := &file_models_jobmodels_job_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -192,7 +587,7 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{2}
+	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{5}
 }
 
 // For futureproofing the health check API
@@ -205,7 +600,7 @@ type HealthRequest struct {
 func (x *HealthRequest) Reset() {
 	*x = HealthRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_models_jobmodels_job_proto_msgTypes[3]
+		mi := &file_models_jobmodels_job_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -218,7 +613,7 @@ func (x *HealthRequest) String() string {
 func (*HealthRequest) ProtoMessage() {}
 
 func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_models_jobmodels_job_proto_msgTypes[3]
+	mi := &file_models_jobmodels_job_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -231,7 +626,7 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
 func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{3}
+	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{6}
 }
 
 type HealthResponse struct {
@@ -245,7 +640,7 @@ type HealthResponse struct {
 func (x *HealthResponse) Reset() {
 	*x = HealthResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_models_jobmodels_job_proto_msgTypes[4]
+		mi := &file_models_jobmodels_job_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -258,7 +653,7 @@ func (x *HealthResponse) String() string {
 func (*HealthResponse) ProtoMessage() {}
 
 func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_models_jobmodels_job_proto_msgTypes[4]
+	mi := &file_models_jobmodels_job_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,7 +666,7 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
 func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{4}
+	return file_models_jobmodels_job_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *HealthResponse) GetHealthy() bool {
@@ -286,7 +681,7 @@ var File_models_jobmodels_job_proto protoreflect.FileDescriptor
 var file_models_jobmodels_job_proto_rawDesc = []byte{
 	0x0a, 0x1a, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2f, 0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64, 0x65,
 	0x6c, 0x73, 0x2f, 0x6a, 0x6f, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x6a, 0x6f,
-	0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x22, 0x90, 0x01, 0x0a, 0x12, 0x4a, 0x6f, 0x62, 0x43,
+	0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x22, 0x87, 0x06, 0x0a, 0x12, 0x4a, 0x6f, 0x62, 0x43,
 	0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x0e,
 	0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x20,
 	0x0a, 0x0b, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20,
@@ -295,20 +690,97 @@ var file_models_jobmodels_job_proto_rawDesc = []byte{
 	0x4d, 0x65, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x18, 0x04, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x05, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x43, 0x6f,
 	0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
-	0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x41, 0x0a, 0x0f, 0x4a, 0x6f,
-	0x62, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x73, 0x12, 0x0e, 0x0a,
-	0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1e, 0x0a,
-	0x0a, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0a, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x07, 0x0a,
-	0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x0f, 0x0a, 0x0d, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x2a, 0x0a, 0x0e, 0x48, 0x65, 0x61, 0x6c, 0x74,
-	0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x48, 0x65, 0x61,
-	0x6c, 0x74, 0x68, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x48, 0x65, 0x61, 0x6c,
-	0x74, 0x68, 0x79, 0x42, 0x3e, 0x5a, 0x3c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x61, 0x61, 0x72, 0x74, 0x68, 0x69, 0x6b, 0x72, 0x61, 0x6f, 0x2f, 0x74, 0x69, 0x6d,
-	0x65, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2f,
-	0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x3b, 0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64,
-	0x65, 0x6c, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x0a, 0x52, 0x65,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18,
+	0x2e, 0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2e, 0x4a, 0x6f, 0x62, 0x52, 0x65,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x0a, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x22, 0x0a, 0x0c,
+	0x57, 0x72, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x65, 0x72, 0x6e, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x57, 0x72, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x65, 0x72, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74,
+	0x63, 0x68, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e,
+	0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x08, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x4e, 0x65,
+	0x78, 0x74, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0f, 0x4e, 0x65, 0x78, 0x74, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x4c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x4c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x12, 0x36, 0x0a, 0x07, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x0e, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2e,
+	0x4a, 0x6f, 0x62, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x52, 0x07, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x09, 0x49, 0x66, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x10, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x09, 0x49, 0x66, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x26, 0x0a, 0x0e, 0x49, 0x64, 0x65, 0x6d, 0x70,
+	0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0e, 0x49, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4b, 0x65, 0x79, 0x12,
+	0x53, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18,
+	0x12, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x73, 0x2e, 0x4a, 0x6f, 0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x74,
+	0x61, 0x69, 0x6c, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e,
+	0x74, 0x65, 0x78, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x42,
+	0x79, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64,
+	0x42, 0x79, 0x1a, 0x3f, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65,
+	0x78, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x49, 0x66, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0x77, 0x0a, 0x11, 0x4a, 0x6f, 0x62, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x69, 0x6f,
+	0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x54, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x22, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x43, 0x6f, 0x64, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x43, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xa3, 0x01, 0x0a, 0x0d, 0x4a,
+	0x6f, 0x62, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x43, 0x72, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x43, 0x72, 0x6f, 0x6e,
+	0x12, 0x1e, 0x0a, 0x0a, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x53, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x4d, 0x53,
+	0x12, 0x14, 0x0a, 0x05, 0x45, 0x6e, 0x64, 0x4d, 0x53, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x45, 0x6e, 0x64, 0x4d, 0x53, 0x12, 0x26, 0x0a, 0x0e, 0x4d, 0x61, 0x78, 0x4f, 0x63, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e,
+	0x4d, 0x61, 0x78, 0x4f, 0x63, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x20,
+	0x0a, 0x0b, 0x4f, 0x63, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x4f, 0x63, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x73,
+	0x22, 0xae, 0x01, 0x0a, 0x0f, 0x4a, 0x6f, 0x62, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x74,
+	0x61, 0x69, 0x6c, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x0a, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x22, 0x0a, 0x0c,
+	0x57, 0x72, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x65, 0x72, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x57, 0x72, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x65, 0x72, 0x6e,
+	0x12, 0x21, 0x0a, 0x09, 0x49, 0x66, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x09, 0x49, 0x66, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x49, 0x66, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0xc1, 0x01, 0x0a, 0x0d, 0x57, 0x72, 0x69, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x57,
+	0x72, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x65, 0x72, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x57, 0x72, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x65, 0x72, 0x6e, 0x12,
+	0x22, 0x0a, 0x0c, 0x41, 0x63, 0x6b, 0x6e, 0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x41, 0x63, 0x6b, 0x6e, 0x6f, 0x77, 0x6c, 0x65, 0x64,
+	0x67, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x52, 0x65, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x52, 0x65, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x64, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x0f,
+	0x0a, 0x0d, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22,
+	0x2a, 0x0a, 0x0e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x42, 0x3e, 0x5a, 0x3c, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x61, 0x72, 0x74, 0x68, 0x69,
+	0x6b, 0x72, 0x61, 0x6f, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65,
+	0x2f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x2f, 0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x73, 0x3b, 0x6a, 0x6f, 0x62, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -323,20 +795,27 @@ func file_models_jobmodels_job_proto_rawDescGZIP() []byte {
 	return file_models_jobmodels_job_proto_rawDescData
 }
 
-var file_models_jobmodels_job_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_models_jobmodels_job_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_models_jobmodels_job_proto_goTypes = []interface{}{
 	(*JobCreationDetails)(nil), // 0: jobmodels.JobCreationDetails
-	(*JobFetchDetails)(nil),    // 1: jobmodels.JobFetchDetails
-	(*Empty)(nil),              // 2: jobmodels.Empty
-	(*HealthRequest)(nil),      // 3: jobmodels.HealthRequest
-	(*HealthResponse)(nil),     // 4: jobmodels.HealthResponse
+	(*JobExecutionEvent)(nil),  // 1: jobmodels.JobExecutionEvent
+	(*JobRecurrence)(nil),      // 2: jobmodels.JobRecurrence
+	(*JobFetchDetails)(nil),    // 3: jobmodels.JobFetchDetails
+	(*WriteResponse)(nil),      // 4: jobmodels.WriteResponse
+	(*Empty)(nil),              // 5: jobmodels.Empty
+	(*HealthRequest)(nil),      // 6: jobmodels.HealthRequest
+	(*HealthResponse)(nil),     // 7: jobmodels.HealthResponse
+	nil,                        // 8: jobmodels.JobCreationDetails.TraceContextEntry
 }
 var file_models_jobmodels_job_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2, // 0: jobmodels.JobCreationDetails.Recurrence:type_name -> jobmodels.JobRecurrence
+	1, // 1: jobmodels.JobCreationDetails.History:type_name -> jobmodels.JobExecutionEvent
+	8, // 2: jobmodels.JobCreationDetails.TraceContext:type_name -> jobmodels.JobCreationDetails.TraceContextEntry
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_models_jobmodels_job_proto_init() }
@@ -358,7 +837,7 @@ func file_models_jobmodels_job_proto_init() {
 			}
 		}
 		file_models_jobmodels_job_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JobFetchDetails); i {
+			switch v := v.(*JobExecutionEvent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -370,7 +849,7 @@ func file_models_jobmodels_job_proto_init() {
 			}
 		}
 		file_models_jobmodels_job_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Empty); i {
+			switch v := v.(*JobRecurrence); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -382,7 +861,7 @@ func file_models_jobmodels_job_proto_init() {
 			}
 		}
 		file_models_jobmodels_job_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HealthRequest); i {
+			switch v := v.(*JobFetchDetails); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -394,6 +873,42 @@ func file_models_jobmodels_job_proto_init() {
 			}
 		}
 		file_models_jobmodels_job_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WriteResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_models_jobmodels_job_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Empty); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_models_jobmodels_job_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_models_jobmodels_job_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*HealthResponse); i {
 			case 0:
 				return &v.state
@@ -406,13 +921,15 @@ func file_models_jobmodels_job_proto_init() {
 			}
 		}
 	}
+	file_models_jobmodels_job_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_models_jobmodels_job_proto_msgTypes[3].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_models_jobmodels_job_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
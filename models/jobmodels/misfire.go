@@ -0,0 +1,34 @@
+package jobmodels
+
+import "errors"
+
+// MisfirePolicy decides what happens to a job that is picked up later than the misfire threshold,
+// for example after the whole cluster or the replicas of a shard were down past its trigger time
+type MisfirePolicy string
+
+const (
+	// The job is published as soon as it is picked up
+	MisfireFireLate MisfirePolicy = "fire_late"
+
+	// The job is not published. Recurring jobs are rescheduled to their next occurrence
+	MisfireSkip MisfirePolicy = "skip"
+
+	// The job is not published and is moved to the dead letter state
+	MisfireDeadLetter MisfirePolicy = "dead_letter"
+
+	// DefaultMisfirePolicy is used when neither the node nor the collection specify a misfire policy
+	DefaultMisfirePolicy = MisfireFireLate
+)
+
+var (
+	ErrInvalidMisfirePolicy = errors.New("invalid misfire policy. Allowed values are fire_late, skip and dead_letter")
+)
+
+func (mp MisfirePolicy) Valid() error {
+	switch mp {
+	case "", MisfireFireLate, MisfireSkip, MisfireDeadLetter:
+		return nil
+	}
+
+	return ErrInvalidMisfirePolicy
+}
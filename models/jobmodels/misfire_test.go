@@ -0,0 +1,22 @@
+package jobmodels
+
+import "testing"
+
+func TestMisfirePolicy_Valid(t *testing.T) {
+	tests := []struct {
+		mp      MisfirePolicy
+		wantErr error
+	}{
+		{mp: "", wantErr: nil},
+		{mp: MisfireFireLate, wantErr: nil},
+		{mp: MisfireSkip, wantErr: nil},
+		{mp: MisfireDeadLetter, wantErr: nil},
+		{mp: "retry", wantErr: ErrInvalidMisfirePolicy},
+	}
+
+	for _, tt := range tests {
+		if err := tt.mp.Valid(); err != tt.wantErr {
+			t.Errorf("%q.Valid() error = %v, wantErr %v", tt.mp, err, tt.wantErr)
+		}
+	}
+}
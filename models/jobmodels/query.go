@@ -0,0 +1,144 @@
+package jobmodels
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Number of jobs listed in a page if the limit is not set
+	DefaultListLimit = 100
+
+	// Maximum number of jobs listed in a page
+	MaxListLimit = 1000
+)
+
+var (
+	// The cursor was not returned by a previous list of the same query
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// The end of the time range is before its start, or the limit is out of bounds
+	ErrInvalidJobQuery = errors.New("invalid job query")
+)
+
+// JobQuery filters the jobs of a collection while listing them.
+//
+// If a time range is set, only the pending jobs scheduled in the range are listed, in the order of their
+// scheduled time. The jobs being retried are scheduled at their next attempt. Otherwise, all the jobs of
+// the collection are listed in the order of their IDs.
+type JobQuery struct {
+	// Range of the scheduled time in milliseconds, both inclusive. 0 leaves the range open on that end
+	FromMS int
+	ToMS   int
+
+	// Lists only the jobs of the route if set
+	Route string
+
+	// Maximum number of jobs listed
+	Limit int
+
+	// Position of the last job of the previous page. It is set from the cursor of the previous page
+	AfterMS int
+	AfterID string
+}
+
+// JobPage is a page of the jobs listed. NextCursor is empty on the last page
+type JobPage struct {
+	Jobs       []*Job `json:"jobs"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// IsTimeRange returns true if the jobs are listed in the order of their scheduled time
+func (q JobQuery) IsTimeRange() bool {
+	return q.FromMS > 0 || q.ToMS > 0
+}
+
+// Valid checks the query and sets the default limit
+func (q *JobQuery) Valid() error {
+	if q.FromMS < 0 || q.ToMS < 0 || (q.ToMS > 0 && q.ToMS < q.FromMS) {
+		return ErrInvalidJobQuery
+	}
+
+	if q.Limit == 0 {
+		q.Limit = DefaultListLimit
+	}
+	if q.Limit < 0 || q.Limit > MaxListLimit {
+		return ErrInvalidJobQuery
+	}
+
+	return nil
+}
+
+// Matches returns true if the job satisfies the filters of the query and lies after its cursor
+func (q JobQuery) Matches(j *Job) bool {
+	if q.Route != "" && j.Route != q.Route {
+		return false
+	}
+
+	if !q.IsTimeRange() {
+		return q.AfterID == "" || j.ID > q.AfterID
+	}
+
+	scheduledMS := j.getScheduledMS()
+	if scheduledMS < q.FromMS || (q.ToMS > 0 && scheduledMS > q.ToMS) || !j.State.IsPending() {
+		return false
+	}
+
+	if q.AfterID == "" {
+		return true
+	}
+
+	return scheduledMS > q.AfterMS || (scheduledMS == q.AfterMS && j.ID > q.AfterID)
+}
+
+// Less returns true if the job a is listed before the job b
+func (q JobQuery) Less(a, b *Job) bool {
+	if q.IsTimeRange() && a.getScheduledMS() != b.getScheduledMS() {
+		return a.getScheduledMS() < b.getScheduledMS()
+	}
+
+	return a.ID < b.ID
+}
+
+// GetCursor returns the cursor to list the jobs after the given job
+func (q JobQuery) GetCursor(j *Job) string {
+	position := j.ID
+	if q.IsTimeRange() {
+		position = strconv.Itoa(j.getScheduledMS()) + ":" + j.ID
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+// SetCursor sets the position after which the jobs are listed from the cursor of the previous page
+func (q *JobQuery) SetCursor(cursor string) error {
+	if cursor == "" {
+		return nil
+	}
+
+	by, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(by) == 0 {
+		return ErrInvalidCursor
+	}
+	position := string(by)
+
+	if !q.IsTimeRange() {
+		q.AfterID = position
+		return nil
+	}
+
+	ms, id, ok := strings.Cut(position, ":")
+	if !ok || id == "" {
+		return ErrInvalidCursor
+	}
+
+	afterMS, err := strconv.Atoi(ms)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	q.AfterMS, q.AfterID = afterMS, id
+	return nil
+}
@@ -0,0 +1,54 @@
+package jobmodels
+
+import "testing"
+
+func TestJobQuery_Cursor(t *testing.T) {
+	job := &Job{ID: "order:123", TriggerMS: 5000}
+
+	tests := []struct {
+		name  string
+		query JobQuery
+		want  JobQuery
+	}{
+		{name: "by id", query: JobQuery{}, want: JobQuery{AfterID: "order:123"}},
+		{name: "by time", query: JobQuery{FromMS: 1000}, want: JobQuery{FromMS: 1000, AfterMS: 5000, AfterID: "order:123"}},
+	}
+
+	for _, tt := range tests {
+		q := tt.query
+		if err := q.SetCursor(tt.query.GetCursor(job)); err != nil {
+			t.Fatalf("%s: SetCursor() error = %v", tt.name, err)
+		}
+		if q != tt.want {
+			t.Errorf("%s: SetCursor() = %+v, want %+v", tt.name, q, tt.want)
+		}
+	}
+
+	q := JobQuery{FromMS: 1000}
+	if err := q.SetCursor("not a cursor"); err != ErrInvalidCursor {
+		t.Errorf("SetCursor() error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func TestJobQuery_Valid(t *testing.T) {
+	tests := []struct {
+		query     JobQuery
+		wantErr   error
+		wantLimit int
+	}{
+		{query: JobQuery{}, wantLimit: DefaultListLimit},
+		{query: JobQuery{FromMS: 2000, ToMS: 1000}, wantErr: ErrInvalidJobQuery},
+		{query: JobQuery{Limit: MaxListLimit + 1}, wantErr: ErrInvalidJobQuery},
+		{query: JobQuery{FromMS: 1000, Limit: 5}, wantLimit: 5},
+	}
+
+	for _, tt := range tests {
+		q := tt.query
+		if err := q.Valid(); err != tt.wantErr {
+			t.Errorf("%+v.Valid() error = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+		if tt.wantErr == nil && q.Limit != tt.wantLimit {
+			t.Errorf("%+v.Valid() limit = %d, want %d", tt.query, q.Limit, tt.wantLimit)
+		}
+	}
+}
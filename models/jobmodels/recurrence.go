@@ -0,0 +1,124 @@
+package jobmodels
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrInvalidRecurrence = errors.New("recurrence must have either a cron expression or an interval")
+	ErrInvalidInterval   = errors.New("interval_ms must be greater than zero")
+	ErrInvalidCron       = errors.New("invalid cron expression")
+)
+
+// cronParser parses the standard 5 field cron expressions along with
+// descriptors like @hourly, @daily etc.
+var cronParser = cron.NewParser(
+	cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// Recurrence describes how a job is rescheduled after it is triggered.
+// Either Cron or IntervalMS must be provided. If both are provided, Cron takes precedence.
+type Recurrence struct {
+	// Cron expression in the standard 5 field format. Evaluated in UTC
+	Cron string `json:"cron,omitempty" bson:"cron,omitempty"`
+
+	// Fixed interval between two triggers in milliseconds
+	IntervalMS int `json:"interval_ms,omitempty" bson:"interval_ms,omitempty"`
+
+	// The job is not rescheduled after this time in milliseconds. 0 means no end time
+	EndMS int `json:"end_ms,omitempty" bson:"end_ms,omitempty"`
+
+	// Maximum number of times the job is triggered. 0 means no limit
+	MaxOccurrences int `json:"max_occurrences,omitempty" bson:"max_occurrences,omitempty"`
+
+	// Number of times the job has been triggered so far. This is maintained by time machine
+	Occurrences int `json:"occurrences,omitempty" bson:"occurrences,omitempty"`
+}
+
+func (r *Recurrence) Valid() error {
+	if r.Cron != "" {
+		if _, err := cronParser.Parse(r.Cron); err != nil {
+			return ErrInvalidCron
+		}
+		return nil
+	}
+
+	if r.IntervalMS < 0 {
+		return ErrInvalidInterval
+	}
+	if r.IntervalMS == 0 {
+		return ErrInvalidRecurrence
+	}
+
+	return nil
+}
+
+// next returns the trigger time that follows the previous trigger time and lies after now.
+// Occurrences missed while the job was not running are skipped.
+func (r *Recurrence) next(previousMS int, now time.Time) (int, error) {
+	nowMS := int(now.UnixMilli())
+
+	if r.Cron != "" {
+		schedule, err := cronParser.Parse(r.Cron)
+		if err != nil {
+			return 0, ErrInvalidCron
+		}
+
+		from := time.UnixMilli(int64(previousMS)).UTC()
+		if from.Before(now) {
+			from = now.UTC()
+		}
+
+		return int(schedule.Next(from).UnixMilli()), nil
+	}
+
+	if r.IntervalMS <= 0 {
+		return 0, ErrInvalidInterval
+	}
+
+	next := previousMS + r.IntervalMS
+	if next <= nowMS {
+		// Skip the occurrences that were missed
+		missed := (nowMS-next)/r.IntervalMS + 1
+		next += missed * r.IntervalMS
+	}
+
+	return next, nil
+}
+
+// NextOccurrence returns a copy of the job scheduled for its next trigger time.
+// It returns false if the job is not recurring or the recurrence has ended.
+func (j *Job) NextOccurrence(now time.Time) (*Job, bool, error) {
+	if j.Recurrence == nil {
+		return nil, false, nil
+	}
+
+	occurrences := j.Recurrence.Occurrences + 1
+	if j.Recurrence.MaxOccurrences > 0 && occurrences >= j.Recurrence.MaxOccurrences {
+		return nil, false, nil
+	}
+
+	nextMS, err := j.Recurrence.next(j.TriggerMS, now)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if j.Recurrence.EndMS > 0 && nextMS > j.Recurrence.EndMS {
+		return nil, false, nil
+	}
+
+	recurrence := *j.Recurrence
+	recurrence.Occurrences = occurrences
+
+	next := *j
+	next.TriggerMS = nextMS
+	next.Recurrence = &recurrence
+
+	// The idempotency key covers the set by the client, the next occurrence must not be taken as its retry
+	next.IdempotencyKey = ""
+
+	return &next, true, nil
+}
@@ -0,0 +1,144 @@
+package jobmodels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextOccurrenceInterval(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	j := Job{
+		ID:        "job1",
+		TriggerMS: int(now.UnixMilli()) - 1000,
+		Route:     "route1",
+		Recurrence: &Recurrence{
+			IntervalMS: 60000,
+		},
+	}
+
+	next, ok, err := j.NextOccurrence(now)
+	if err != nil || !ok {
+		t.Fatalf("Expected next occurrence, got ok: %v, err: %v", ok, err)
+	}
+
+	if want := j.TriggerMS + 60000; next.TriggerMS != want {
+		t.Errorf("Unexpected trigger time: got %d, want %d", next.TriggerMS, want)
+	}
+
+	if next.Recurrence.Occurrences != 1 {
+		t.Errorf("Unexpected occurrences: got %d, want %d", next.Recurrence.Occurrences, 1)
+	}
+
+	if j.Recurrence.Occurrences != 0 {
+		t.Errorf("Original job must not be modified")
+	}
+}
+
+func TestNextOccurrenceSkipsMissed(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	j := Job{
+		ID:        "job1",
+		TriggerMS: int(now.UnixMilli()) - 250000,
+		Route:     "route1",
+		Recurrence: &Recurrence{
+			IntervalMS: 60000,
+		},
+	}
+
+	next, ok, err := j.NextOccurrence(now)
+	if err != nil || !ok {
+		t.Fatalf("Expected next occurrence, got ok: %v, err: %v", ok, err)
+	}
+
+	if want := j.TriggerMS + 5*60000; next.TriggerMS != want {
+		t.Errorf("Unexpected trigger time: got %d, want %d", next.TriggerMS, want)
+	}
+}
+
+func TestNextOccurrenceCron(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)
+	j := Job{
+		ID:        "job1",
+		TriggerMS: int(now.UnixMilli()),
+		Route:     "route1",
+		Recurrence: &Recurrence{
+			Cron: "0 * * * *",
+		},
+	}
+
+	next, ok, err := j.NextOccurrence(now)
+	if err != nil || !ok {
+		t.Fatalf("Expected next occurrence, got ok: %v, err: %v", ok, err)
+	}
+
+	want := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+	if next.GetTriggerTime().UTC() != want {
+		t.Errorf("Unexpected trigger time: got %v, want %v", next.GetTriggerTime().UTC(), want)
+	}
+}
+
+func TestNextOccurrenceEnds(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	tests := []struct {
+		name       string
+		recurrence *Recurrence
+	}{
+		{
+			name: "not recurring",
+		},
+		{
+			name: "max occurrences reached",
+			recurrence: &Recurrence{
+				IntervalMS:     1000,
+				MaxOccurrences: 3,
+				Occurrences:    2,
+			},
+		},
+		{
+			name: "end time reached",
+			recurrence: &Recurrence{
+				IntervalMS: 60000,
+				EndMS:      int(now.UnixMilli()) + 1000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := Job{
+				ID:         "job1",
+				TriggerMS:  int(now.UnixMilli()),
+				Route:      "route1",
+				Recurrence: tt.recurrence,
+			}
+
+			_, ok, err := j.NextOccurrence(now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if ok {
+				t.Errorf("Expected recurrence to end")
+			}
+		})
+	}
+}
+
+func TestRecurrenceValid(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		want       error
+	}{
+		{name: "empty", recurrence: Recurrence{}, want: ErrInvalidRecurrence},
+		{name: "negative interval", recurrence: Recurrence{IntervalMS: -1}, want: ErrInvalidInterval},
+		{name: "invalid cron", recurrence: Recurrence{Cron: "* *"}, want: ErrInvalidCron},
+		{name: "interval", recurrence: Recurrence{IntervalMS: 1000}},
+		{name: "cron", recurrence: Recurrence{Cron: "@hourly"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.recurrence.Valid(); got != tt.want {
+				t.Errorf("Recurrence.Valid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package jobmodels
+
+import "errors"
+
+var (
+	// The current version of the job does not match the version in the precondition of the write
+	ErrVersionConflict = errors.New("job version conflict")
+)
+
+// CheckVersion returns ErrVersionConflict if the version precondition of the write is set and does not match
+// the current version of the job. The current version is 0 if the job does not exist.
+func CheckVersion(current int, ifVersion *int) error {
+	if ifVersion != nil && *ifVersion != current {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+// GetIfVersion returns the version precondition of the GRPC message
+func GetIfVersion(ifVersion *int64) *int {
+	if ifVersion == nil {
+		return nil
+	}
+
+	v := int(*ifVersion)
+	return &v
+}
+
+// ToIfVersion converts the version precondition to the GRPC message
+func ToIfVersion(ifVersion *int) *int64 {
+	if ifVersion == nil {
+		return nil
+	}
+
+	v := int64(*ifVersion)
+	return &v
+}
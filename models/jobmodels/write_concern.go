@@ -0,0 +1,87 @@
+package jobmodels
+
+import "errors"
+
+// WriteConcern is the number of replicas of a shard that must acknowledge
+// a write before it is reported as successful
+type WriteConcern string
+
+const (
+	// Only the leader shard must acknowledge the write.
+	// The followers are replicated in the background
+	WriteConcernOne WriteConcern = "one"
+
+	// Majority of the replicas including the leader must acknowledge the write
+	WriteConcernQuorum WriteConcern = "quorum"
+
+	// All the replicas must acknowledge the write
+	WriteConcernAll WriteConcern = "all"
+
+	// DefaultWriteConcern is used when neither the request nor the collection specify a write concern
+	DefaultWriteConcern = WriteConcernAll
+)
+
+var (
+	ErrInvalidWriteConcern = errors.New("invalid write concern. Allowed values are one, quorum and all")
+
+	// The write was applied on the leader, but not enough followers acknowledged it.
+	// The followers will catch up with the leader later.
+	ErrWriteConcernNotSatisfied = errors.New("write concern not satisfied")
+)
+
+func (wc WriteConcern) Valid() error {
+	switch wc {
+	case "", WriteConcernOne, WriteConcernQuorum, WriteConcernAll:
+		return nil
+	}
+
+	return ErrInvalidWriteConcern
+}
+
+// RequiredAcks returns the number of acknowledgements required out of the given replicas.
+// replicas includes the leader.
+func (wc WriteConcern) RequiredAcks(replicas int) int {
+	switch wc {
+	case WriteConcernOne:
+		return 1
+	case WriteConcernQuorum:
+		return replicas/2 + 1
+	default:
+		return replicas
+	}
+}
+
+// WriteOptions are the optional parameters of a write request
+type WriteOptions struct {
+	// Empty value means the default write concern of the collection is used
+	WriteConcern WriteConcern `json:"write_concern,omitempty"`
+
+	// The write is applied only if the current version of the job matches. 0 means the job must not exist
+	IfVersion *int `json:"if_version,omitempty"`
+}
+
+// WriteResult describes the outcome of a write on the replicas of a shard
+type WriteResult struct {
+	// Wal offset of the write on the leader shard
+	Offset int64 `json:"offset"`
+
+	WriteConcern WriteConcern `json:"write_concern,omitempty"`
+
+	// Number of replicas including the leader that acknowledged the write
+	Acknowledged int `json:"acknowledged"`
+
+	// Total number of replicas of the shard including the leader
+	Replicas int `json:"replicas"`
+
+	// Version of the job after a set
+	Version int `json:"version,omitempty"`
+
+	// True if the set was not applied as the job was already set with its idempotency key.
+	// The offset and the version are of the earlier set
+	Replayed bool `json:"replayed,omitempty"`
+}
+
+// Satisfied returns true if enough replicas have acknowledged the write
+func (wr WriteResult) Satisfied() bool {
+	return wr.Acknowledged >= wr.WriteConcern.RequiredAcks(wr.Replicas)
+}
@@ -0,0 +1,72 @@
+package jobmodels
+
+import "testing"
+
+func TestWriteConcernRequiredAcks(t *testing.T) {
+	tests := []struct {
+		wc       WriteConcern
+		replicas int
+		want     int
+	}{
+		{wc: WriteConcernOne, replicas: 3, want: 1},
+		{wc: WriteConcernQuorum, replicas: 1, want: 1},
+		{wc: WriteConcernQuorum, replicas: 2, want: 2},
+		{wc: WriteConcernQuorum, replicas: 3, want: 2},
+		{wc: WriteConcernQuorum, replicas: 5, want: 3},
+		{wc: WriteConcernAll, replicas: 3, want: 3},
+		{wc: "", replicas: 3, want: 3},
+	}
+	for _, tt := range tests {
+		if got := tt.wc.RequiredAcks(tt.replicas); got != tt.want {
+			t.Errorf("WriteConcern(%q).RequiredAcks(%d) = %d, want %d", tt.wc, tt.replicas, got, tt.want)
+		}
+	}
+}
+
+func TestWriteResultSatisfied(t *testing.T) {
+	tests := []struct {
+		name   string
+		result WriteResult
+		want   bool
+	}{
+		{
+			name:   "one with only leader",
+			result: WriteResult{WriteConcern: WriteConcernOne, Acknowledged: 1, Replicas: 3},
+			want:   true,
+		},
+		{
+			name:   "quorum not reached",
+			result: WriteResult{WriteConcern: WriteConcernQuorum, Acknowledged: 1, Replicas: 3},
+			want:   false,
+		},
+		{
+			name:   "quorum reached",
+			result: WriteResult{WriteConcern: WriteConcernQuorum, Acknowledged: 2, Replicas: 3},
+			want:   true,
+		},
+		{
+			name:   "all not reached",
+			result: WriteResult{WriteConcern: WriteConcernAll, Acknowledged: 2, Replicas: 3},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Satisfied(); got != tt.want {
+				t.Errorf("WriteResult.Satisfied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteConcernValid(t *testing.T) {
+	for _, wc := range []WriteConcern{"", WriteConcernOne, WriteConcernQuorum, WriteConcernAll} {
+		if err := wc.Valid(); err != nil {
+			t.Errorf("WriteConcern(%q).Valid() = %v, want nil", wc, err)
+		}
+	}
+
+	if err := WriteConcern("majority").Valid(); err != ErrInvalidWriteConcern {
+		t.Errorf("Expected %v, got %v", ErrInvalidWriteConcern, err)
+	}
+}
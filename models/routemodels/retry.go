@@ -0,0 +1,65 @@
+package routemodels
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy decides how a job is retried when it cannot be published to the route.
+// The backoff doubles after every attempt till the max backoff, and a random jitter is
+// added so that the retries of the jobs that failed together are spread out.
+type RetryPolicy struct {
+	// Total number of attempts including the first one. 1 disables the retries
+	MaxAttempts int `json:"max_attempts,omitempty" bson:"max_attempts,omitempty" msgpack:",omitempty"`
+
+	// Backoff after the first attempt in milliseconds
+	InitialBackoffMS int `json:"initial_backoff_ms,omitempty" bson:"initial_backoff_ms,omitempty" msgpack:",omitempty"`
+
+	// Maximum backoff between two attempts in milliseconds
+	MaxBackoffMS int `json:"max_backoff_ms,omitempty" bson:"max_backoff_ms,omitempty" msgpack:",omitempty"`
+}
+
+// DefaultRetryPolicy is used for the routes without a retry policy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:      5,
+	InitialBackoffMS: 1000,
+	MaxBackoffMS:     60000,
+}
+
+var (
+	ErrInvalidRetryPolicy = errors.New("invalid retry policy. max_attempts, initial_backoff_ms and max_backoff_ms must be positive")
+)
+
+func (rp RetryPolicy) Valid() error {
+	if rp.MaxAttempts <= 0 || rp.InitialBackoffMS <= 0 || rp.MaxBackoffMS < rp.InitialBackoffMS {
+		return ErrInvalidRetryPolicy
+	}
+
+	return nil
+}
+
+// Backoff returns the time to wait before the next attempt after the given number of attempts.
+// Half of the exponential backoff is fixed and the other half is random.
+func (rp RetryPolicy) Backoff(attempts int) time.Duration {
+	backoff := rp.InitialBackoffMS
+	for i := 1; i < attempts && backoff < rp.MaxBackoffMS; i++ {
+		backoff *= 2
+	}
+	if backoff > rp.MaxBackoffMS {
+		backoff = rp.MaxBackoffMS
+	}
+
+	half := backoff / 2
+	jitter := rand.Intn(backoff - half + 1)
+	return time.Duration(half+jitter) * time.Millisecond
+}
+
+// GetRetryPolicy returns the retry policy of the route, or the default retry policy if it is not set
+func (r *Route) GetRetryPolicy() RetryPolicy {
+	if r == nil || r.RetryPolicy == nil {
+		return DefaultRetryPolicy
+	}
+
+	return *r.RetryPolicy
+}
@@ -0,0 +1,52 @@
+package routemodels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Valid(t *testing.T) {
+	tests := []struct {
+		name    string
+		rp      RetryPolicy
+		wantErr error
+	}{
+		{name: "default", rp: DefaultRetryPolicy, wantErr: nil},
+		{name: "no retries", rp: RetryPolicy{MaxAttempts: 1, InitialBackoffMS: 1, MaxBackoffMS: 1}, wantErr: nil},
+		{name: "no attempts", rp: RetryPolicy{MaxAttempts: 0, InitialBackoffMS: 1000, MaxBackoffMS: 1000}, wantErr: ErrInvalidRetryPolicy},
+		{name: "no backoff", rp: RetryPolicy{MaxAttempts: 3, InitialBackoffMS: 0, MaxBackoffMS: 1000}, wantErr: ErrInvalidRetryPolicy},
+		{name: "max less than initial", rp: RetryPolicy{MaxAttempts: 3, InitialBackoffMS: 2000, MaxBackoffMS: 1000}, wantErr: ErrInvalidRetryPolicy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rp.Valid(); err != tt.wantErr {
+				t.Errorf("Valid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	rp := RetryPolicy{MaxAttempts: 10, InitialBackoffMS: 1000, MaxBackoffMS: 5000}
+
+	tests := []struct {
+		attempts int
+		min, max time.Duration
+	}{
+		{attempts: 1, min: 500 * time.Millisecond, max: 1000 * time.Millisecond},
+		{attempts: 2, min: 1000 * time.Millisecond, max: 2000 * time.Millisecond},
+		{attempts: 3, min: 2000 * time.Millisecond, max: 4000 * time.Millisecond},
+		{attempts: 4, min: 2500 * time.Millisecond, max: 5000 * time.Millisecond},
+		{attempts: 50, min: 2500 * time.Millisecond, max: 5000 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := rp.Backoff(tt.attempts); got < tt.min || got > tt.max {
+				t.Errorf("Backoff(%d) = %v, want between %v and %v", tt.attempts, got, tt.min, tt.max)
+				break
+			}
+		}
+	}
+}
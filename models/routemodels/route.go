@@ -24,6 +24,9 @@ type Route struct {
 	// Incase of Kafka Route
 	Topic string `json:"topic,omitempty" bson:"topic,omitempty" msgpack:",omitempty"`
 	Host  string `json:"host,omitempty" bson:"host,omitempty" msgpack:",omitempty"`
+
+	// Retry policy of the jobs that cannot be published. DefaultRetryPolicy is used if it is not set
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty" bson:"retry_policy,omitempty" msgpack:",omitempty"`
 }
 
 var (
@@ -50,6 +53,10 @@ func (r Route) Valid() error {
 		return ErrInvalidRouteType
 	}
 
+	if r.RetryPolicy != nil {
+		return r.RetryPolicy.Valid()
+	}
+
 	return nil
 }
 
@@ -1,36 +1,73 @@
 package clusterhealth
 
 import (
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/aarthikrao/timeMachine/components/consensus"
 	dhtComponent "github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/failuredetector"
 	"github.com/aarthikrao/timeMachine/process/connectionmanager"
+	"github.com/aarthikrao/timeMachine/process/nodemanager"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
 	"go.uber.org/zap"
 )
 
 type NodeHealth struct {
 	LastContact time.Time
 
-	// The number of times this node was unreachable
-	UnreachableCount int
+	// Result of the last health check
+	Reachable bool
 
 	// If the node has been marked unreachable, it means that we have already
-	// reassigned the master-slots that belong to this node.
+	// replaced this node in the shard map.
 	// We are maintaining this variable to make sure we dont end up doing reassignment
-	// multiple times
+	// multiple times. The failure is handled again on every poll while some of its shards are unavailable
 	MarkedUnreachable bool
 }
 
-type clusterHealth struct {
+// NodeStatus is the suspicion level of a node as seen by the raft leader
+type NodeStatus struct {
+	failuredetector.NodeState
+
+	// Result of the last health check
+	Reachable bool `json:"reachable"`
+
+	// True if the node has been replaced in the shard map
+	Replaced bool `json:"replaced"`
+}
+
+type ClusterHealth struct {
 	dht     dhtComponent.DHT
 	cp      consensus.Consensus
 	connMgr *connectionmanager.ConnectionManager
+	nodeMgr *nodemanager.NodeManager
+
+	// The health checks are the heartbeats of the nodes
+	detector *failuredetector.Detector
 
+	// healthStatus checks the health of all the nodes, and now is the clock of the failure detector
+	healthStatus func(timeout time.Duration) map[dhtComponent.NodeID]bool
+	now          func() time.Time
+
+	mu          sync.Mutex
 	clusterInfo map[dhtComponent.NodeID]NodeHealth
 
-	pollInterval         time.Duration
-	UnreachableThreshold int
+	pollInterval time.Duration
+
+	// Timeout of the health check of every node
+	probeTimeout time.Duration
+
+	// Maximum number of entries the promoted follower can be behind the high water mark of a failed leader
+	maxFailoverLag int64
+
+	// Time given to the spare nodes to copy the shards of a failed node, and to the node to copy them again once it comes back
+	catchUpTimeout time.Duration
+
+	// The leadership of at most balanceShards shards is handed over every balanceInterval
+	balanceInterval time.Duration
+	balanceShards   int
 
 	log *zap.Logger
 }
@@ -39,101 +76,255 @@ func CreateClusterHealthChecker(
 	dht dhtComponent.DHT,
 	cp consensus.Consensus,
 	connMgr *connectionmanager.ConnectionManager,
+	nodeMgr *nodemanager.NodeManager,
 
 	pollInterval time.Duration,
-	UnreachableThreshold int,
+	probeTimeout time.Duration,
+	detectorConfig failuredetector.Config,
+	maxFailoverLag int64,
+	catchUpTimeout time.Duration,
+	balanceInterval time.Duration,
+	balanceShards int,
 
 	log *zap.Logger,
-) *clusterHealth {
-	ch := &clusterHealth{
-		dht:                  dht,
-		cp:                   cp,
-		connMgr:              connMgr,
-		pollInterval:         pollInterval,
-		clusterInfo:          make(map[dhtComponent.NodeID]NodeHealth),
-		UnreachableThreshold: UnreachableThreshold,
-		log:                  log,
+) (*ClusterHealth, error) {
+	detector, err := failuredetector.CreateDetector(detectorConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := &ClusterHealth{
+		dht:             dht,
+		cp:              cp,
+		connMgr:         connMgr,
+		nodeMgr:         nodeMgr,
+		detector:        detector,
+		healthStatus:    connMgr.GetHealthStatus,
+		now:             time.Now,
+		pollInterval:    pollInterval,
+		probeTimeout:    probeTimeout,
+		clusterInfo:     make(map[dhtComponent.NodeID]NodeHealth),
+		maxFailoverLag:  maxFailoverLag,
+		catchUpTimeout:  catchUpTimeout,
+		balanceInterval: balanceInterval,
+		balanceShards:   balanceShards,
+		log:             log,
 	}
 
 	go ch.GetClusterHealth()
+	if balanceShards > 0 {
+		go ch.BalanceLeaders()
+	}
 
-	return ch
+	return ch, nil
 }
 
 // GetClusterHealth checks for health of the cluster only on the master node.
-// It maintains a map of nodeID vs node health details. It reassigns master slots
-// when nodes become unreachable beyond a threshold.
-func (ch *clusterHealth) GetClusterHealth() {
+// Every successful health check is a heartbeat of the node to the phi accrual failure detector.
+// It replaces the nodes whose phi crosses the failure threshold, and restores the replicas of their shards.
+// The replaced nodes rejoin their shards once they are healthy again.
+func (ch *ClusterHealth) GetClusterHealth() {
 	ticker := time.NewTicker(ch.pollInterval)
 
 	for range ticker.C {
+		ch.poll()
+	}
+}
 
-		// Check if this node is the leader
-		if !ch.cp.IsLeader() {
-			continue
+// poll checks the health of the nodes and handles their failures, only if this node is the raft leader
+func (ch *ClusterHealth) poll() {
+	if !ch.cp.IsLeader() {
+		// The heartbeats are missed while this node is not the leader, and must not count against the nodes
+		ch.reset()
+		return
+	}
+
+	report := ch.healthStatus(ch.probeTimeout)
+	ch.log.Debug("Health check", zap.Any("report", report))
+	ch.checkShardAvailability(report)
+
+	for _, ni := range ch.detectFailures(report, ch.now()) {
+		handled := ch.handleNodeFailure(ni, report)
+
+		ch.mu.Lock()
+		n := ch.clusterInfo[ni]
+		n.MarkedUnreachable = handled
+		ch.clusterInfo[ni] = n
+		ch.mu.Unlock()
+	}
+
+	ch.rejoinNodes()
+}
+
+// rejoinNodes adds the healthy nodes that were replaced after a failure back to their shards
+func (ch *ClusterHealth) rejoinNodes() {
+	for _, ni := range ch.getHealthyNodes() {
+		if err := ch.nodeMgr.RejoinNode(ni, ch.catchUpTimeout); err != nil {
+			ch.log.Error("Unable to rejoin node", zap.String("node", string(ni)), zap.Error(err))
 		}
+	}
+}
+
+// detectFailures records the heartbeats of the reachable nodes and returns the failed nodes that are not yet replaced
+func (ch *ClusterHealth) detectFailures(report map[dhtComponent.NodeID]bool, now time.Time) []dhtComponent.NodeID {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
 
-		report := ch.connMgr.GetHealthStatus()
-		ch.log.Info("Health check", zap.Any("report", report))
+	failed := []dhtComponent.NodeID{}
+	for ni, reachable := range report {
+		n, known := ch.clusterInfo[ni]
+
+		// A node is assumed to have sent a heartbeat when it is first seen,
+		// so that a node that never responds is also detected
+		if reachable || !known {
+			ch.detector.Heartbeat(ni, now)
+		}
+		if reachable {
+			n.LastContact = now
+			n.MarkedUnreachable = false
+		}
+		n.Reachable = reachable
+		ch.clusterInfo[ni] = n
 
-		for ni, reachable := range report {
-			n := ch.clusterInfo[ni]
+		state := ch.detector.State(ni, now)
+		metrics.NodePhi.WithLabelValues(string(ni)).Set(state.Phi)
 
-			if reachable {
-				n.LastContact = time.Now()
-				n.UnreachableCount = 0
-				n.MarkedUnreachable = false
+		switch state.Status {
+		case failuredetector.StatusSuspect:
+			ch.log.Warn("Suspected node",
+				zap.String("node", string(ni)),
+				zap.Float64("phi", state.Phi),
+				zap.Time("lastContact", n.LastContact))
 
-				ch.clusterInfo[ni] = n
+		case failuredetector.StatusFailed:
+			if n.MarkedUnreachable {
 				continue
 			}
 
-			ch.log.Info("Unreachable node",
-				zap.String("unreachable", string(ni)),
-				zap.Int("retryCount", n.UnreachableCount),
-				zap.Bool("marked", n.MarkedUnreachable))
-
-			n.UnreachableCount++
-			if n.UnreachableCount >= ch.UnreachableThreshold && !n.MarkedUnreachable {
-				ch.log.Info("Handling node failure",
-					zap.Int("threshold", n.UnreachableCount),
-					zap.Time("lastContact", n.LastContact),
-					zap.String("unreachableNode", string(ni)),
-				)
-
-				ch.handleNodeFailure(ni)
-				n.MarkedUnreachable = true
-			}
+			ch.log.Info("Handling node failure",
+				zap.Float64("phi", state.Phi),
+				zap.Time("lastContact", n.LastContact),
+				zap.String("unreachableNode", string(ni)),
+			)
+			failed = append(failed, ni)
+		}
+	}
 
-			ch.clusterInfo[ni] = n
+	return failed
+}
+
+// BalanceLeaders hands the leadership of the shards over to the healthy nodes that lead fewer shards, only on the
+// master node. It is throttled to balanceShards shards every balanceInterval, so that the leadership of a node that
+// comes back after a failure is restored gradually.
+func (ch *ClusterHealth) BalanceLeaders() {
+	ticker := time.NewTicker(ch.balanceInterval)
+
+	for range ticker.C {
+		if !ch.cp.IsLeader() {
+			continue
+		}
+
+		healthy := ch.getHealthyNodes()
+		if len(healthy) == 0 {
+			continue
+		}
+
+		if _, err := ch.nodeMgr.BalanceLeaders(healthy, ch.balanceShards); err != nil {
+			ch.log.Error("Unable to balance shard leaders", zap.Error(err))
 		}
 	}
 }
 
-func (ch *clusterHealth) handleNodeFailure(ni dhtComponent.NodeID) {
-	// Raft will take care of split brain
+// getHealthyNodes returns the reachable nodes that are not suspected
+func (ch *ClusterHealth) getHealthyNodes() []dhtComponent.NodeID {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
 
-	snapshot := ch.dht.Snapshot()
-	for si, loc := range snapshot {
-		if loc.Leader.ID == ni {
+	now := ch.now()
+	healthy := []dhtComponent.NodeID{}
+	for ni, n := range ch.clusterInfo {
+		if n.Reachable && ch.detector.State(ni, now).Status == failuredetector.StatusHealthy {
+			healthy = append(healthy, ni)
+		}
+	}
 
-			// Here we are assiging the next follower as a leader
-			// TODO: In real tife usecases, we will have to take into consideration
-			// of a lot of factors before assigning the leader
-			nextFollower := snapshot[si].Followers[0]
-			shard := snapshot[si]
-			shard.Leader = nextFollower
+	return healthy
+}
+
+// GetNodeHealth returns the suspicion level of every node. It is empty if this node is not the raft leader
+func (ch *ClusterHealth) GetNodeHealth() []NodeStatus {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	now := ch.now()
+	nodes := make([]NodeStatus, 0, len(ch.clusterInfo))
+	for ni, n := range ch.clusterInfo {
+		nodes = append(nodes, NodeStatus{
+			NodeState: ch.detector.State(ni, now),
+			Reachable: n.Reachable,
+			Replaced:  n.MarkedUnreachable,
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeID < nodes[j].NodeID })
 
-			snapshot[si] = shard
+	return nodes
+}
+
+// reset forgets the heartbeats of all the nodes
+func (ch *ClusterHealth) reset() {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if len(ch.clusterInfo) == 0 {
+		return
+	}
+
+	ch.detector.Reset()
+	ch.clusterInfo = make(map[dhtComponent.NodeID]NodeHealth)
+}
+
+// handleNodeFailure replaces the failed node in the shard map with the healthy nodes.
+// It returns false if the failure has to be handled again, as some shards of the node are unavailable
+func (ch *ClusterHealth) handleNodeFailure(ni dhtComponent.NodeID, report map[dhtComponent.NodeID]bool) bool {
+	// Raft will take care of split brain
+	healthy := []dhtComponent.NodeID{}
+	for node, reachable := range report {
+		if reachable && node != ni {
+			healthy = append(healthy, node)
 		}
 	}
 
-	by, err := consensus.ConvertConfigSnapshot(snapshot)
+	unavailable, err := ch.nodeMgr.ReplaceFailedNode(ni, healthy, ch.maxFailoverLag, ch.catchUpTimeout)
 	if err != nil {
-		ch.log.Error("Error in converting config snapshot", zap.Error(err))
+		ch.log.Error("Unable to replace failed node", zap.String("failedNode", string(ni)), zap.Error(err))
+	}
+	if len(unavailable) > 0 {
+		ch.log.Error("Shards are unavailable", zap.String("failedNode", string(ni)), zap.Any("shards", unavailable))
+	}
+
+	return err == nil && len(unavailable) == 0
+}
+
+// checkShardAvailability records the shards that have no reachable replica
+func (ch *ClusterHealth) checkShardAvailability(report map[dhtComponent.NodeID]bool) {
+	unavailable := []dhtComponent.ShardID{}
+	for shardID, shard := range ch.dht.Snapshot() {
+		available := false
+		for _, node := range shard.GetOwners() {
+			if reachable, ok := report[node]; reachable || !ok {
+				available = true
+				break
+			}
+		}
+
+		if !available {
+			unavailable = append(unavailable, shardID)
+		}
 	}
+	sort.Slice(unavailable, func(i, j int) bool { return unavailable[i] < unavailable[j] })
 
-	if err = ch.cp.Apply(by); err != nil {
-		ch.log.Error("Error in applying config snapshot to cp", zap.Error(err), zap.String("msg", string(by)))
+	metrics.ShardsUnavailable.Set(float64(len(unavailable)))
+	if len(unavailable) > 0 {
+		ch.log.Error("Shards without a reachable replica", zap.Any("shards", unavailable))
 	}
 }
@@ -0,0 +1,283 @@
+package clusterhealth
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/failuredetector"
+	"github.com/aarthikrao/timeMachine/process/nodemanager"
+	"github.com/hashicorp/raft"
+	"go.uber.org/zap"
+)
+
+// testConsensus applies the raft commands to the config FSM right away
+type testConsensus struct {
+	fsm     *fsm.ConfigFSM
+	servers []raft.Server
+	leader  atomic.Bool
+}
+
+func (c *testConsensus) Join(nodeID, raftAddress string) error { return nil }
+func (c *testConsensus) Remove(nodeID string) error            { return nil }
+func (c *testConsensus) Stats() map[string]string              { return nil }
+func (c *testConsensus) IsLeader() bool                        { return c.leader.Load() }
+func (c *testConsensus) GetLeaderAddress() string              { return "" }
+
+func (c *testConsensus) GetConfigurations() ([]raft.Server, error) {
+	return c.servers, nil
+}
+
+func (c *testConsensus) Apply(cmd []byte) error {
+	if err, ok := c.fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmd}).(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// testCluster is the health report of the nodes, and the clock of the failure detector
+type testCluster struct {
+	mu        sync.Mutex
+	reachable map[dht.NodeID]bool
+	now       time.Time
+}
+
+func (c *testCluster) setReachable(nodeID dht.NodeID, reachable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reachable[nodeID] = reachable
+}
+
+func (c *testCluster) healthStatus(timeout time.Duration) map[dht.NodeID]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := make(map[dht.NodeID]bool, len(c.reachable))
+	for nodeID, reachable := range c.reachable {
+		report[nodeID] = reachable
+	}
+
+	return report
+}
+
+func (c *testCluster) getNow() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// poll advances the clock by the interval and checks the health of the nodes
+func (c *testCluster) poll(ch *ClusterHealth, interval time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(interval)
+	c.mu.Unlock()
+
+	ch.poll()
+}
+
+// createTestClusterHealth returns the health checker of the raft leader with the shard map applied.
+// The raft leader does not own any shard, and all the nodes are reachable. The wal offsets of the
+// shards are unavailable, hence the failover of a leader shard is refused.
+func createTestClusterHealth(t *testing.T, shards map[dht.ShardID]dht.ShardLocation, nodes ...dht.NodeID) (*ClusterHealth, *testConsensus, *testCluster) {
+	appDht := dht.Create()
+	appDht.Load(shards, 1)
+
+	cp := &testConsensus{fsm: fsm.NewConfigFSM(appDht, nil, nil, zap.NewNop())}
+	cp.leader.Store(true)
+	for _, node := range append([]dht.NodeID{"raftLeader"}, nodes...) {
+		cp.servers = append(cp.servers, raft.Server{ID: raft.ServerID(node)})
+	}
+
+	detector, err := failuredetector.CreateDetector(failuredetector.DefaultConfig(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &testCluster{reachable: make(map[dht.NodeID]bool), now: time.Now()}
+	for _, node := range nodes {
+		cluster.reachable[node] = true
+	}
+
+	ch := &ClusterHealth{
+		dht:          appDht,
+		cp:           cp,
+		nodeMgr:      nodemanager.CreateNodeManager("raftLeader", nil, nil, appDht, cp, cp.fsm, nil, 0, 0, zap.NewNop()),
+		detector:     detector,
+		healthStatus: cluster.healthStatus,
+		now:          cluster.getNow,
+		clusterInfo:  make(map[dht.NodeID]NodeHealth),
+		log:          zap.NewNop(),
+	}
+
+	return ch, cp, cluster
+}
+
+// createShard returns the shard led by the leader
+func createShard(shardID dht.ShardID, leader dht.NodeID, followers ...dht.NodeID) dht.ShardLocation {
+	shard := dht.ShardLocation{ID: shardID, Leader: dht.NodeDetails{ID: leader}}
+	for _, follower := range followers {
+		shard.Followers = append(shard.Followers, dht.NodeDetails{ID: follower})
+	}
+
+	return shard
+}
+
+// getNodeStatus returns the status of the node on the raft leader
+func getNodeStatus(ch *ClusterHealth, nodeID dht.NodeID) (NodeStatus, bool) {
+	for _, status := range ch.GetNodeHealth() {
+		if status.NodeID == nodeID {
+			return status, true
+		}
+	}
+
+	return NodeStatus{}, false
+}
+
+func TestDetectFailure(t *testing.T) {
+	ch, cp, cluster := createTestClusterHealth(t, map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node2", "node1"),
+	}, "node1", "node2")
+
+	for i := 0; i < 10; i++ {
+		cluster.poll(ch, time.Second)
+	}
+
+	// The node is suspected before it is replaced, and is replaced only once its phi crosses the failure threshold
+	cluster.setReachable("node1", false)
+	suspected := false
+	for i := 0; i < 300; i++ {
+		cluster.poll(ch, 100*time.Millisecond)
+
+		status, _ := getNodeStatus(ch, "node1")
+		if status.Reachable {
+			t.Fatalf("node1 is reachable after missing the heartbeats")
+		}
+		if status.Status == failuredetector.StatusSuspect {
+			suspected = true
+		}
+		if status.Replaced {
+			break
+		}
+		if owners := ch.dht.Snapshot()[0].GetOwners(); len(owners) != 2 {
+			t.Fatalf("node1 was removed from shard 0 before it failed, owners %v", owners)
+		}
+	}
+
+	status, _ := getNodeStatus(ch, "node1")
+	if !suspected || status.Status != failuredetector.StatusFailed || !status.Replaced {
+		t.Fatalf("node1 status = %+v, suspected %v, want a suspected node that failed and was replaced", status, suspected)
+	}
+	if owners := ch.dht.Snapshot()[0].GetOwners(); !reflect.DeepEqual(owners, []dht.NodeID{"node2"}) {
+		t.Errorf("shard 0 owners = %v, want [node2]", owners)
+	}
+	if replaced := cp.fsm.GetReplacedShards("node1"); !reflect.DeepEqual(replaced, map[dht.ShardID]dht.NodeID{0: ""}) {
+		t.Errorf("replaced shards = %v, want shard 0 without a spare", replaced)
+	}
+	if status, _ := getNodeStatus(ch, "node2"); status.Status != failuredetector.StatusHealthy {
+		t.Errorf("node2 status = %+v, want healthy", status)
+	}
+}
+
+func TestRetryUnavailableShards(t *testing.T) {
+	ch, _, cluster := createTestClusterHealth(t, map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node1", "node2"),
+	}, "node1", "node2")
+
+	for i := 0; i < 10; i++ {
+		cluster.poll(ch, time.Second)
+	}
+
+	// The failover of shard 0 is refused, as the offset of node2 is unknown
+	cluster.setReachable("node1", false)
+	for i := 0; i < 30; i++ {
+		cluster.poll(ch, time.Second)
+	}
+
+	status, _ := getNodeStatus(ch, "node1")
+	if status.Status != failuredetector.StatusFailed || status.Replaced {
+		t.Fatalf("node1 status = %+v, want a failed node that is not replaced", status)
+	}
+	if leader := ch.dht.Snapshot()[0].Leader.ID; leader != "node1" {
+		t.Fatalf("shard 0 leader = %s, want node1", leader)
+	}
+
+	// The failure is handled again on the next poll, once node2 leads the shard
+	ch.dht.Load(map[dht.ShardID]dht.ShardLocation{0: createShard(0, "node2", "node1")}, ch.dht.Epoch()+1)
+	cluster.poll(ch, time.Second)
+
+	if status, _ = getNodeStatus(ch, "node1"); !status.Replaced {
+		t.Errorf("node1 status = %+v, want a replaced node", status)
+	}
+	if owners := ch.dht.Snapshot()[0].GetOwners(); !reflect.DeepEqual(owners, []dht.NodeID{"node2"}) {
+		t.Errorf("shard 0 owners = %v, want [node2]", owners)
+	}
+}
+
+func TestResetOnLeadershipLoss(t *testing.T) {
+	ch, cp, cluster := createTestClusterHealth(t, map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node2", "node1"),
+	}, "node1", "node2")
+
+	for i := 0; i < 10; i++ {
+		cluster.poll(ch, time.Second)
+	}
+
+	cp.leader.Store(false)
+	cluster.poll(ch, time.Second)
+	if nodes := ch.GetNodeHealth(); len(nodes) != 0 {
+		t.Errorf("GetNodeHealth() = %+v, want no nodes on a follower", nodes)
+	}
+
+	// The time this node was not the leader does not count against node1, that is unreachable on the first poll
+	cluster.setReachable("node1", false)
+	cp.leader.Store(true)
+	cluster.poll(ch, time.Minute)
+
+	status, ok := getNodeStatus(ch, "node1")
+	if !ok || status.Status != failuredetector.StatusHealthy || status.Replaced {
+		t.Errorf("node1 status = %+v, want a healthy node", status)
+	}
+	if owners := ch.dht.Snapshot()[0].GetOwners(); len(owners) != 2 {
+		t.Errorf("shard 0 owners = %v, want node1 and node2", owners)
+	}
+}
+
+func TestRejoinNodes(t *testing.T) {
+	ch, cp, cluster := createTestClusterHealth(t, map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node2", "node1"),
+	}, "node1", "node2")
+
+	for i := 0; i < 10; i++ {
+		cluster.poll(ch, time.Second)
+	}
+
+	cluster.setReachable("node1", false)
+	for i := 0; i < 30; i++ {
+		cluster.poll(ch, time.Second)
+	}
+	if status, _ := getNodeStatus(ch, "node1"); !status.Replaced {
+		t.Fatalf("node1 status = %+v, want a replaced node", status)
+	}
+
+	// node1 rejoins shard 0 as soon as it is healthy again
+	cluster.setReachable("node1", true)
+	cluster.poll(ch, time.Second)
+
+	status, _ := getNodeStatus(ch, "node1")
+	if status.Status != failuredetector.StatusHealthy || status.Replaced {
+		t.Errorf("node1 status = %+v, want a healthy node", status)
+	}
+	if shard := ch.dht.Snapshot()[0]; !shard.IsJoining("node1") {
+		t.Errorf("shard 0 = %+v, want node1 joining", shard)
+	}
+	if replaced := cp.fsm.GetReplacedShards("node1"); len(replaced) != 0 {
+		t.Errorf("replaced shards = %v, want none", replaced)
+	}
+}
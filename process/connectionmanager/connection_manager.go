@@ -8,6 +8,8 @@ import (
 	"github.com/aarthikrao/timeMachine/components/dht"
 	js "github.com/aarthikrao/timeMachine/components/jobstore"
 	"github.com/aarthikrao/timeMachine/components/network"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -60,6 +62,7 @@ func (cm *ConnectionManager) connect(nodeID dht.NodeID, addr string) error {
 		ctx,
 		addr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		tracing.DialOption(),
 		grpc.WithBlock())
 
 	defer cancelFunc()
@@ -105,26 +108,58 @@ func (cm *ConnectionManager) GetJobStore(nodeID dht.NodeID) (js.JobStoreWithRepl
 	return nil, ErrNodeNotPresent
 }
 
-func (cm *ConnectionManager) GetHealthStatus() map[dht.NodeID]bool {
+// GetHealthStatus checks the health of all the nodes concurrently. Every check is bound by the timeout,
+// and the nodes that do not respond in time are reported unhealthy.
+func (cm *ConnectionManager) GetHealthStatus(timeout time.Duration) map[dht.NodeID]bool {
+	// The lock is not held during the checks, so that the connections can be added meanwhile
 	cm.mu.RLock()
-	defer cm.mu.RUnlock()
-
-	m := make(map[dht.NodeID]bool)
-
+	stores := make(map[dht.NodeID]js.JobStoreWithReplicator, len(cm.tmcMap))
 	for nodeID, tmc := range cm.tmcMap {
-		healthy, err := tmc.jobStore.HealthCheck()
-		if err != nil {
-			cm.log.Error("Health check failed", zap.String("failedNode", string(nodeID)))
-			m[nodeID] = false
-			continue
-		}
-
-		m[nodeID] = healthy
+		stores[nodeID] = tmc.jobStore
+	}
+	cm.mu.RUnlock()
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		m  = make(map[dht.NodeID]bool, len(stores))
+	)
+	for nodeID, store := range stores {
+		wg.Add(1)
+		go func(nodeID dht.NodeID, store js.JobStoreWithReplicator) {
+			defer wg.Done()
+
+			ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+			defer cancelFunc()
+
+			healthy, err := store.HealthCheck(ctx)
+			if err != nil {
+				cm.log.Error("Health check failed", zap.String("failedNode", string(nodeID)), zap.Error(err))
+				healthy = false
+			}
+			recordHealth(nodeID, healthy)
+
+			mu.Lock()
+			m[nodeID] = healthy
+			mu.Unlock()
+		}(nodeID, store)
 	}
+	wg.Wait()
 
 	return m
 }
 
+// recordHealth records the result of the health check of the node in the metrics
+func recordHealth(nodeID dht.NodeID, healthy bool) {
+	result, value := "reachable", 1.0
+	if !healthy {
+		result, value = "unreachable", 0
+	}
+
+	metrics.NodeReachable.WithLabelValues(string(nodeID)).Set(value)
+	metrics.HealthChecks.WithLabelValues(string(nodeID), result).Inc()
+}
+
 // Closes all the connections maintained by the connection manager
 func (cm *ConnectionManager) Close() {
 	cm.mu.Lock()
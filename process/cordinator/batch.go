@@ -0,0 +1,327 @@
+package cordinator
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/executor"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// batchGroup contains the jobs of a batch that belong to a shard
+type batchGroup struct {
+	shardLoc dht.ShardLocation
+
+	// Positions of the jobs in the batch
+	indexes []int
+	jobs    []*jm.Job
+	jobIDs  []string
+}
+
+// SetJobs sets a batch of jobs. The jobs are grouped by their shards and every group is sent to the leader of
+// its shard in parallel. The leader writes the group as a single wal entry in a single transaction, and replicates
+// it to the followers as a single write. It returns the result of every job in the order of the jobs.
+// As with SetJobWithOptions, the execution details of the jobs are stored as is. The jobs with an idempotency key
+// are not set, as the key is only honoured while setting a single job.
+func (cp *CordinatorProcess) SetJobs(ctx context.Context, collection string, jobs []*jm.Job, opts jm.WriteOptions) (results []jm.BatchResult, err error) {
+	ctx, span := tracing.Start(ctx, "cordinator.SetJobs", tracing.CollectionKey.String(collection), tracing.BatchSizeKey.Int(len(jobs)))
+	defer func() { tracing.End(span, err) }()
+
+	if collection == "" {
+		return nil, ErrInvalidDetails
+	}
+
+	if err := jm.ValidBatchSize(len(jobs)); err != nil {
+		return nil, err
+	}
+
+	if err := opts.WriteConcern.Valid(); err != nil {
+		return nil, err
+	}
+
+	ctx, err = cp.fence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results = make([]jm.BatchResult, len(jobs))
+	groups := make(map[dht.ShardID]*batchGroup)
+	for i, job := range jobs {
+		results[i].ID = job.ID
+		if err := job.Valid(); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if job.IdempotencyKey != "" {
+			results[i].Error = jm.ErrIdempotencyKeyInBatch.Error()
+			continue
+		}
+		job.Collection = collection
+
+		group, err := cp.getBatchGroup(groups, job.ID)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		group.indexes = append(group.indexes, i)
+		group.jobs = append(group.jobs, job)
+		group.jobIDs = append(group.jobIDs, job.ID)
+	}
+
+	cp.writeGroups(ctx, groups, results,
+		func(group *batchGroup) []jm.BatchResult {
+			return cp.setLocalJobs(ctx, group, collection, opts)
+		},
+		func(ctx context.Context, group *batchGroup, leader jobstore.JobStoreWithReplicator) ([]jm.BatchResult, error) {
+			return leader.SetJobs(ctx, collection, group.jobs, opts)
+		},
+	)
+
+	return results, nil
+}
+
+// DeleteJobs deletes a batch of jobs. Like SetJobs, the deletes of the jobs of a shard are written together.
+// The jobs that do not exist are reported in the results.
+func (cp *CordinatorProcess) DeleteJobs(ctx context.Context, collection string, jobIDs []string, opts jm.WriteOptions) (results []jm.BatchResult, err error) {
+	ctx, span := tracing.Start(ctx, "cordinator.DeleteJobs", tracing.CollectionKey.String(collection), tracing.BatchSizeKey.Int(len(jobIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	if collection == "" {
+		return nil, ErrInvalidDetails
+	}
+
+	if err := jm.ValidBatchSize(len(jobIDs)); err != nil {
+		return nil, err
+	}
+
+	if err := opts.WriteConcern.Valid(); err != nil {
+		return nil, err
+	}
+
+	ctx, err = cp.fence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results = make([]jm.BatchResult, len(jobIDs))
+	groups := make(map[dht.ShardID]*batchGroup)
+	for i, jobID := range jobIDs {
+		results[i].ID = jobID
+		if jobID == "" {
+			results[i].Error = ErrInvalidDetails.Error()
+			continue
+		}
+
+		group, err := cp.getBatchGroup(groups, jobID)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		group.indexes = append(group.indexes, i)
+		group.jobIDs = append(group.jobIDs, jobID)
+	}
+
+	cp.writeGroups(ctx, groups, results,
+		func(group *batchGroup) []jm.BatchResult {
+			return cp.deleteLocalJobs(ctx, group, collection, opts)
+		},
+		func(ctx context.Context, group *batchGroup, leader jobstore.JobStoreWithReplicator) ([]jm.BatchResult, error) {
+			return leader.DeleteJobs(ctx, collection, group.jobIDs, opts)
+		},
+	)
+
+	return results, nil
+}
+
+// ReplicateBatch can be only called from the leader of the shard
+func (cp *CordinatorProcess) ReplicateBatch(ctx context.Context, shardID dht.ShardID, le wal.LogEntry) (offset int64, err error) {
+	_, span := tracing.Start(ctx, "cordinator.ReplicateBatch", tracing.ShardIDKey.Int(int(shardID)))
+	defer func() { tracing.End(span, err) }()
+
+	if le.Operation != wal.BatchLog {
+		return 0, wal.ErrNotBatchEntry
+	}
+
+	epoch := cp.dhtMgr.Epoch()
+	shardLoc, err := cp.dhtMgr.GetShardLocation(shardID)
+	if err != nil {
+		return 0, err
+	}
+	if err = jobstore.CheckReplicated(ctx, shardLoc, epoch); err != nil {
+		return 0, err
+	}
+
+	offset, err = cp.replicator.Replicate(shardID, le)
+	if err != nil {
+		return offset, errors.Wrap(err, "follower slot: ")
+	}
+
+	return offset, nil
+}
+
+// getBatchGroup returns the group of the shard of the job, creating it if required
+func (cp *CordinatorProcess) getBatchGroup(groups map[dht.ShardID]*batchGroup, jobID string) (*batchGroup, error) {
+	shardLoc, err := cp.dhtMgr.GetShard(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	group, ok := groups[shardLoc.ID]
+	if !ok {
+		group = &batchGroup{shardLoc: shardLoc}
+		groups[shardLoc.ID] = group
+	}
+
+	return group, nil
+}
+
+// writeGroups writes the groups in parallel, locally if this node leads the shard of the group, else on the
+// remote leader. The results of every group are copied to the positions of its jobs in the batch.
+func (cp *CordinatorProcess) writeGroups(
+	ctx context.Context,
+	groups map[dht.ShardID]*batchGroup,
+	results []jm.BatchResult,
+	writeLocal func(group *batchGroup) []jm.BatchResult,
+	writeRemote func(ctx context.Context, group *batchGroup, leader jobstore.JobStoreWithReplicator) ([]jm.BatchResult, error),
+) {
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		wg.Add(1)
+		go func(group *batchGroup) {
+			defer wg.Done()
+
+			var groupResults []jm.BatchResult
+			var err error
+			if group.shardLoc.Leader.ID == cp.selfNodeID {
+				groupResults = writeLocal(group)
+			} else {
+				// Forward the group to the leader of the shard
+				var leader jobstore.JobStoreWithReplicator
+				var forwardCtx context.Context
+				if forwardCtx, leader, err = cp.getRemoteLeader(ctx, group.shardLoc.Leader.ID); err == nil {
+					groupResults, err = writeRemote(forwardCtx, group, leader)
+				}
+			}
+			if err == nil && len(groupResults) != len(group.indexes) {
+				err = ErrBatchResultMismatch
+			}
+
+			// Every group writes to its own positions in the results
+			for i, index := range group.indexes {
+				if err != nil {
+					results[index].Error = err.Error()
+					continue
+				}
+				results[index] = groupResults[i]
+			}
+		}(group)
+	}
+	wg.Wait()
+}
+
+// setLocalJobs sets the jobs of the group in the local leader shard and replicates them as a single write
+func (cp *CordinatorProcess) setLocalJobs(ctx context.Context, group *batchGroup, collection string, opts jm.WriteOptions) []jm.BatchResult {
+	shard, err := cp.nodeMgr.GetLocalShard(group.shardLoc.ID)
+	if err == nil && shard == nil {
+		err = ErrShardNotFound
+	}
+	if err != nil {
+		return getBatchResults(group.jobIDs, jm.WriteResult{}, err)
+	}
+
+	_, shardSpan := tracing.Start(ctx, "datashard.SetJobs", tracing.ShardIDKey.Int(int(group.shardLoc.ID)))
+	le, err := shard.SetJobs(collection, group.jobs)
+	tracing.End(shardSpan, err)
+	if err != nil {
+		return getBatchResults(group.jobIDs, jm.WriteResult{}, err)
+	}
+
+	// The jobs that are not within the grace period of the executor are queued by the poller
+	for _, job := range group.jobs {
+		if err := cp.jobExecutor.Queue(*job); err != nil && err != executor.ErrNotWithinExecutorGracePeriod {
+			cp.log.Warn("Unable to queue job", zap.String("jobID", job.ID), zap.Error(err))
+		}
+	}
+
+	result := cp.replicateBatch(ctx, group.shardLoc, collection, opts, le)
+	results := getBatchResults(group.jobIDs, result, nil)
+	for i, job := range group.jobs {
+		results[i].Version = job.Version
+	}
+
+	return results
+}
+
+// deleteLocalJobs deletes the jobs of the group from the local leader shard and replicates them as a single write
+func (cp *CordinatorProcess) deleteLocalJobs(ctx context.Context, group *batchGroup, collection string, opts jm.WriteOptions) []jm.BatchResult {
+	shard, err := cp.nodeMgr.GetLocalShard(group.shardLoc.ID)
+	if err == nil && shard == nil {
+		err = ErrShardNotFound
+	}
+	if err != nil {
+		return getBatchResults(group.jobIDs, jm.WriteResult{}, err)
+	}
+
+	_, shardSpan := tracing.Start(ctx, "datashard.DeleteJobs", tracing.ShardIDKey.Int(int(group.shardLoc.ID)))
+	le, errs, err := shard.DeleteJobs(collection, group.jobIDs)
+	tracing.End(shardSpan, err)
+	if err != nil {
+		return getBatchResults(group.jobIDs, jm.WriteResult{}, err)
+	}
+
+	var result jm.WriteResult
+	if le != nil {
+		for i, jobID := range group.jobIDs {
+			if errs[i] != nil {
+				continue
+			}
+			if err := cp.jobExecutor.Delete(jobID); err != nil && err != executor.ErrJobNotFound {
+				cp.log.Warn("Unable to remove deleted job from executor", zap.String("jobID", jobID), zap.Error(err))
+			}
+		}
+
+		result = cp.replicateBatch(ctx, group.shardLoc, collection, opts, *le)
+	}
+
+	results := getBatchResults(group.jobIDs, result, nil)
+	for i := range results {
+		if errs[i] != nil {
+			results[i] = jm.BatchResult{ID: group.jobIDs[i], Error: errs[i].Error()}
+		}
+	}
+
+	return results
+}
+
+// replicateBatch replicates the batch entry of the leader shard to the followers
+func (cp *CordinatorProcess) replicateBatch(ctx context.Context, shardLoc dht.ShardLocation, collection string, opts jm.WriteOptions, le wal.LogEntry) jm.WriteResult {
+	return cp.replicate(ctx, shardLoc, cp.getWriteConcern(collection, opts), le.Offset,
+		func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error) {
+			return follower.ReplicateBatch(ctx, shardLoc.ID, le)
+		},
+	)
+}
+
+// getBatchResults returns the same result for all the jobs. The write concern error is set if it is not satisfied
+func getBatchResults(jobIDs []string, result jm.WriteResult, err error) []jm.BatchResult {
+	if err == nil && !result.Satisfied() {
+		err = jm.ErrWriteConcernNotSatisfied
+	}
+
+	results := make([]jm.BatchResult, 0, len(jobIDs))
+	for _, jobID := range jobIDs {
+		r := jm.BatchResult{ID: jobID, WriteResult: result}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
@@ -2,14 +2,24 @@
 package cordinator
 
 import (
+	"context"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/collectionstore"
 	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
 	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/components/executor"
 	"github.com/aarthikrao/timeMachine/components/jobstore"
 	"github.com/aarthikrao/timeMachine/components/routestore"
+	cm "github.com/aarthikrao/timeMachine/models/collectionmodels"
 	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
 	rm "github.com/aarthikrao/timeMachine/models/routemodels"
 	"github.com/aarthikrao/timeMachine/process/nodemanager"
+	"github.com/aarthikrao/timeMachine/process/replicator"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	timeutil "github.com/aarthikrao/timeMachine/utils/time"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -17,11 +27,28 @@ import (
 type CordinatorProcess struct {
 	nodeMgr     *nodemanager.NodeManager
 	rStore      *routestore.RouteStore
+	cStore      *collectionstore.CollectionStore
 	dhtMgr      dht.DHT
 	cp          consensus.Consensus
 	selfNodeID  dht.NodeID
 	jobExecutor executor.Executor
-	log         *zap.Logger
+	replicator  *replicator.Replicator
+
+	// Jobs picked up later than the misfire threshold after their trigger time are handled
+	// as per the misfire policy of their collection, or the misfire policy of this node
+	misfirePolicy    jm.MisfirePolicy
+	misfireThreshold time.Duration
+
+	// A set with the idempotency key of a job set within this window returns the result of the earlier set
+	idempotencyWindow time.Duration
+
+	// Time this process was started at. Along with the epoch of the shard map, it identifies the jobs claimed by this process
+	startedMS int64
+
+	// getConnection returns the job store of another node
+	getConnection func(nodeID dht.NodeID) (jobstore.JobStoreWithReplicator, error)
+
+	log *zap.Logger
 }
 
 // compile time validation
@@ -32,19 +59,31 @@ func CreateCordinatorProcess(
 	selfNodeID string,
 	nodeMgr *nodemanager.NodeManager,
 	rStore *routestore.RouteStore,
+	cStore *collectionstore.CollectionStore,
 	cp consensus.Consensus,
 	dhtMgr dht.DHT,
 	jobExecutor executor.Executor,
+	replicator *replicator.Replicator,
+	misfirePolicy jm.MisfirePolicy,
+	misfireThreshold time.Duration,
+	idempotencyWindow time.Duration,
 	log *zap.Logger,
 ) *CordinatorProcess {
 	return &CordinatorProcess{
-		nodeMgr:     nodeMgr,
-		rStore:      rStore,
-		cp:          cp,
-		dhtMgr:      dhtMgr,
-		selfNodeID:  dht.NodeID(selfNodeID),
-		jobExecutor: jobExecutor,
-		log:         log,
+		nodeMgr:           nodeMgr,
+		rStore:            rStore,
+		cStore:            cStore,
+		cp:                cp,
+		dhtMgr:            dhtMgr,
+		selfNodeID:        dht.NodeID(selfNodeID),
+		jobExecutor:       jobExecutor,
+		replicator:        replicator,
+		misfirePolicy:     misfirePolicy,
+		misfireThreshold:  misfireThreshold,
+		idempotencyWindow: idempotencyWindow,
+		startedMS:         time.Now().UnixMilli(),
+		getConnection:     nodeMgr.GetRemoteConnection,
+		log:               log,
 	}
 }
 
@@ -65,7 +104,7 @@ func (cp *CordinatorProcess) GetJob(collection, jobID string) (*jm.Job, error) {
 
 	// Local shard doesnt exist, fetch remote shard
 	// TODO: Add read preferences from API
-	conn, err := cp.nodeMgr.GetRemoteConnection(shardLoc.Leader.ID)
+	conn, err := cp.getConnection(shardLoc.Leader.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -74,151 +113,500 @@ func (cp *CordinatorProcess) GetJob(collection, jobID string) (*jm.Job, error) {
 }
 
 func (cp *CordinatorProcess) SetJob(collection string, job *jm.Job) (offset int64, err error) {
+	result, err := cp.SetJobWithOptions(context.Background(), collection, job, jm.WriteOptions{})
+	return result.Offset, err
+}
+
+// SetJobWithOptions sets the job in the leader shard and replicates it to the followers in parallel.
+// It returns once the replicas required by the write concern have acknowledged the write.
+// The write fails with jm.ErrVersionConflict if the version precondition of the options is not met.
+// If the job was already set with its idempotency key within the idempotency window, nothing is written
+// and the result of the earlier set is returned along with the replicas that have it.
+// The execution details of the job are stored as is. Jobs set by the clients must be reset first.
+func (cp *CordinatorProcess) SetJobWithOptions(ctx context.Context, collection string, job *jm.Job, opts jm.WriteOptions) (result jm.WriteResult, err error) {
+	ctx, span := tracing.Start(ctx, "cordinator.SetJob", tracing.CollectionKey.String(collection), tracing.JobIDKey.String(job.ID))
+	defer func() { tracing.End(span, err) }()
+
 	if collection == "" {
-		return 0, ErrInvalidDetails
+		return jm.WriteResult{}, ErrInvalidDetails
 	}
 
 	if err := job.Valid(); err != nil {
-		return 0, err
+		return jm.WriteResult{}, err
+	}
+
+	if err := opts.WriteConcern.Valid(); err != nil {
+		return jm.WriteResult{}, err
+	}
+	job.Collection = collection
+
+	ctx, err = cp.fence(ctx)
+	if err != nil {
+		return jm.WriteResult{}, err
 	}
 
 	shardLoc, err := cp.dhtMgr.GetShard(job.ID)
 	if err != nil {
-		return 0, err
+		return jm.WriteResult{}, err
 	}
 
 	if shardLoc.Leader.ID != cp.selfNodeID {
 		// This node is not the leader for this shard, hence we cannot serve write requests
 		// Forward this request to the right owner
-		remoteLeader, err := cp.nodeMgr.GetRemoteConnection(shardLoc.Leader.ID)
+		ctx, remoteLeader, err := cp.getRemoteLeader(ctx, shardLoc.Leader.ID)
 		if err != nil {
-			return 0, err
+			return jm.WriteResult{}, err
 		}
 
-		return remoteLeader.SetJob(collection, job)
+		return remoteLeader.SetJobWithOptions(ctx, collection, job, opts)
 	}
 
 	// This means this node is the leader for this shard, we need to process the write request
 	shard, err := cp.nodeMgr.GetLocalShard(shardLoc.ID)
 	if err != nil {
-		return 0, err
+		return jm.WriteResult{}, err
+	}
+	if shard == nil {
+		return jm.WriteResult{}, ErrShardNotFound
 	}
-	offset, err = shard.SetJob(collection, job)
+	sinceMS := timeutil.GetCurrentMillis() - int(cp.idempotencyWindow.Milliseconds())
+	_, shardSpan := tracing.Start(ctx, "datashard.SetJob", tracing.ShardIDKey.Int(int(shardLoc.ID)))
+	offset, record, err := shard.SetJobOnce(collection, job, opts.IfVersion, sinceMS)
+	tracing.End(shardSpan, err)
 	if err != nil {
-		return 0, err
+		return jm.WriteResult{}, err
 	}
-
-	// Add the job to the executor queue
-	if err = cp.jobExecutor.Queue(*job); err != nil {
-		return 0, err
+	if record != nil {
+		return cp.replay(ctx, shardLoc, cp.getWriteConcern(collection, opts), record)
 	}
 
-	// Now we set the job in all the follower shards
-	for _, follower := range shardLoc.Followers {
-		remoteFollower, err := cp.nodeMgr.GetRemoteConnection(follower.ID)
-		if err != nil {
-			return 0, err
-		}
+	// Add the job to the executor queue. If the job is not within the grace period
+	// of the executor, it will be queued by the poller when its minute bucket is fetched
+	if err = cp.jobExecutor.Queue(*job); err != nil && err != executor.ErrNotWithinExecutorGracePeriod {
+		return jm.WriteResult{}, err
+	}
 
-		_, err = remoteFollower.ReplicateSetJob(collection, job)
-		if err != nil {
-			return 0, err
-		}
+	// Now we set the job in all the follower shards. The followers may still be
+	// replicating in the background after this method returns, hence we pass a copy
+	replicated := *job
+	result = cp.replicate(ctx, shardLoc, cp.getWriteConcern(collection, opts), offset,
+		func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error) {
+			return follower.ReplicateSetJob(ctx, collection, &replicated, offset)
+		},
+	)
+	result.Version = job.Version
+	if !result.Satisfied() {
+		return result, jm.ErrWriteConcernNotSatisfied
 	}
 
-	return offset, nil
+	return result, nil
 }
 
 func (cp *CordinatorProcess) DeleteJob(collection, jobID string) (offset int64, err error) {
+	result, err := cp.DeleteJobWithOptions(context.Background(), collection, jobID, jm.WriteOptions{})
+	return result.Offset, err
+}
+
+// DeleteJobWithOptions deletes the job from the leader shard and replicates the delete to the followers in parallel.
+// It returns once the replicas required by the write concern have acknowledged the delete.
+// As with SetJobWithOptions, the delete fails with jm.ErrVersionConflict if the version precondition is not met.
+func (cp *CordinatorProcess) DeleteJobWithOptions(ctx context.Context, collection, jobID string, opts jm.WriteOptions) (result jm.WriteResult, err error) {
+	ctx, span := tracing.Start(ctx, "cordinator.DeleteJob", tracing.CollectionKey.String(collection), tracing.JobIDKey.String(jobID))
+	defer func() { tracing.End(span, err) }()
+
 	if collection == "" || jobID == "" {
-		return 0, ErrInvalidDetails
+		return jm.WriteResult{}, ErrInvalidDetails
+	}
+
+	if err := opts.WriteConcern.Valid(); err != nil {
+		return jm.WriteResult{}, err
+	}
+
+	ctx, err = cp.fence(ctx)
+	if err != nil {
+		return jm.WriteResult{}, err
 	}
 
 	shardLoc, err := cp.dhtMgr.GetShard(jobID)
 	if err != nil {
-		return 0, err
+		return jm.WriteResult{}, err
 	}
 
 	if shardLoc.Leader.ID != cp.selfNodeID {
 		// This node is not the leader for this shard, hence we cannot serve write requests
 		// Forward this request to the right owner
-		remoteLeader, err := cp.nodeMgr.GetRemoteConnection(shardLoc.Leader.ID)
+		ctx, remoteLeader, err := cp.getRemoteLeader(ctx, shardLoc.Leader.ID)
 		if err != nil {
-			return 0, err
+			return jm.WriteResult{}, err
 		}
 
-		offset, err = remoteLeader.DeleteJob(collection, jobID)
+		// No more processing in this node
+		return remoteLeader.DeleteJobWithOptions(ctx, collection, jobID, opts)
+	}
+
+	// This means this node is the leader for this shard, we need to process the write request
+	shard, err := cp.nodeMgr.GetLocalShard(shardLoc.ID)
+	if err != nil {
+		return jm.WriteResult{}, err
+	}
+	if shard == nil {
+		return jm.WriteResult{}, ErrShardNotFound
+	}
+	_, shardSpan := tracing.Start(ctx, "datashard.DeleteJob", tracing.ShardIDKey.Int(int(shardLoc.ID)))
+	offset, err := shard.DeleteJobIfVersion(collection, jobID, opts.IfVersion)
+	tracing.End(shardSpan, err)
+	if err != nil {
+		return jm.WriteResult{}, err
+	}
+
+	// Now we delete the job in all the follower shards
+	result = cp.replicate(ctx, shardLoc, cp.getWriteConcern(collection, opts), offset,
+		func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error) {
+			return follower.ReplicateDeleteJob(ctx, collection, jobID, offset)
+		},
+	)
+	if !result.Satisfied() {
+		return result, jm.ErrWriteConcernNotSatisfied
+	}
+
+	return result, nil
+}
+
+// CancelJobWithOptions moves the job to the cancelled state on the leader shard, so that it is not published,
+// and replicates it to the followers in parallel. Unlike a delete, the job is retained along with its history.
+// Only the jobs that are scheduled or waiting for a retry can be cancelled.
+func (cp *CordinatorProcess) CancelJobWithOptions(ctx context.Context, collection, jobID string, opts jm.WriteOptions) (result jm.WriteResult, err error) {
+	ctx, span := tracing.Start(ctx, "cordinator.CancelJob", tracing.CollectionKey.String(collection), tracing.JobIDKey.String(jobID))
+	defer func() { tracing.End(span, err) }()
+
+	if collection == "" || jobID == "" {
+		return jm.WriteResult{}, ErrInvalidDetails
+	}
+
+	if err := opts.WriteConcern.Valid(); err != nil {
+		return jm.WriteResult{}, err
+	}
+
+	ctx, err = cp.fence(ctx)
+	if err != nil {
+		return jm.WriteResult{}, err
+	}
+
+	shardLoc, err := cp.dhtMgr.GetShard(jobID)
+	if err != nil {
+		return jm.WriteResult{}, err
+	}
+
+	if shardLoc.Leader.ID != cp.selfNodeID {
+		// Forward this request to the leader of the shard
+		ctx, remoteLeader, err := cp.getRemoteLeader(ctx, shardLoc.Leader.ID)
 		if err != nil {
-			return 0, err
+			return jm.WriteResult{}, err
 		}
 
-		// No more processing in this node
-		return offset, nil
+		return remoteLeader.CancelJobWithOptions(ctx, collection, jobID, opts)
 	}
 
-	// This means this node is the leader for this shard, we need to process the write request
 	shard, err := cp.nodeMgr.GetLocalShard(shardLoc.ID)
 	if err != nil {
-		return 0, err
+		return jm.WriteResult{}, err
+	}
+	if shard == nil {
+		return jm.WriteResult{}, ErrShardNotFound
 	}
-	offset, err = shard.DeleteJob(collection, jobID)
+
+	job, err := shard.GetJob(collection, jobID)
 	if err != nil {
-		return 0, err
+		return jm.WriteResult{}, err
+	}
+
+	_, shardSpan := tracing.Start(ctx, "datashard.SetExecutionState", tracing.ShardIDKey.Int(int(shardLoc.ID)))
+	offset, stored, err := shard.SetExecutionState(collection, jobID, job.TriggerMS, jm.ExecutionUpdate{State: jm.ExecutionCancelled})
+	tracing.End(shardSpan, err)
+	if err != nil {
+		return jm.WriteResult{}, err
+	}
+
+	// The job is not published even if it remains in the executor, as it can no longer be claimed
+	if err = cp.jobExecutor.Delete(jobID); err != nil && err != executor.ErrJobNotFound {
+		cp.log.Warn("Unable to remove cancelled job from executor", zap.String("jobID", jobID), zap.Error(err))
+	}
+
+	result = cp.replicate(ctx, shardLoc, cp.getWriteConcern(collection, opts), offset,
+		func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error) {
+			return follower.ReplicateSetJob(ctx, collection, stored, offset)
+		},
+	)
+	if !result.Satisfied() {
+		return result, jm.ErrWriteConcernNotSatisfied
 	}
 
-	// Now we set the job in all the follower shards
+	return result, nil
+}
+
+// getRemoteLeader returns the connection to the leader of a shard to forward a write to, along with ctx that records
+// this node on the forwarding path of the write. It fails with jobstore.ShardMapMismatchError if the write is being
+// forwarded in a loop, as this node and the nodes before it do not agree on the leader of the shard.
+func (cp *CordinatorProcess) getRemoteLeader(ctx context.Context, leaderID dht.NodeID) (context.Context, jobstore.JobStoreWithReplicator, error) {
+	ctx, err := jobstore.Forward(ctx, cp.selfNodeID, leaderID, cp.dhtMgr.Epoch())
+	if err != nil {
+		cp.log.Warn("Rejected forwarded write", zap.String("leader", string(leaderID)), zap.Error(err))
+		return ctx, nil, err
+	}
+
+	remoteLeader, err := cp.getConnection(leaderID)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	return ctx, remoteLeader, nil
+}
+
+// fence rejects a write forwarded by a node on a newer epoch of the shard map, as this node may no longer lead its shard.
+// It returns ctx with this node as the sender of the write. The epoch is read before the shard of the write is looked up,
+// so that the followers reject the write if the shard map changes in between.
+func (cp *CordinatorProcess) fence(ctx context.Context) (context.Context, error) {
+	epoch := cp.dhtMgr.Epoch()
+	if err := jobstore.CheckForwarded(ctx, cp.selfNodeID, epoch); err != nil {
+		cp.log.Warn("Rejected write forwarded from a newer epoch", zap.Error(err))
+		return ctx, err
+	}
+
+	return jobstore.WithSender(ctx, jobstore.Sender{NodeID: cp.selfNodeID, Epoch: epoch}), nil
+}
+
+// replicate sends the write to all the followers of the shard in parallel. It returns as soon as
+// the acknowledgements required by the write concern are received, or when they can no longer be received.
+// The remaining followers keep replicating in the background. The write is also sent to the joining nodes
+// of the shard, but they are not counted in the write concern.
+func (cp *CordinatorProcess) replicate(
+	ctx context.Context,
+	shardLoc dht.ShardLocation,
+	wc jm.WriteConcern,
+	offset int64,
+	replicateFn func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error),
+) jm.WriteResult {
+	result := jm.WriteResult{
+		Offset:       offset,
+		WriteConcern: wc,
+		Acknowledged: 1, // Leader
+		Replicas:     len(shardLoc.Followers) + 1,
+	}
+	required := wc.RequiredAcks(result.Replicas)
+
+	// Buffered so that the followers responding after we return do not block
+	acks := make(chan bool, len(shardLoc.Followers))
 	for _, follower := range shardLoc.Followers {
-		remoteFollower, err := cp.nodeMgr.GetRemoteConnection(follower.ID)
-		if err != nil {
-			return 0, err
+		go func(followerID dht.NodeID) {
+			acks <- cp.replicateToFollower(ctx, shardLoc.ID, followerID, offset, replicateFn)
+		}(follower.ID)
+	}
+	for _, node := range shardLoc.Joining {
+		go cp.replicateToFollower(ctx, shardLoc.ID, node.ID, offset, replicateFn)
+	}
+
+	for pending := len(shardLoc.Followers); pending > 0; pending-- {
+		if result.Acknowledged >= required || result.Acknowledged+pending < required {
+			break
 		}
 
-		_, err = remoteFollower.ReplicateDeleteJob(collection, jobID)
-		if err != nil {
-			return 0, err
+		if <-acks {
+			result.Acknowledged++
 		}
 	}
 
-	return offset, nil
+	return result
+}
+
+// replicateToFollower returns true if the follower has acknowledged the write at the offset
+func (cp *CordinatorProcess) replicateToFollower(
+	ctx context.Context,
+	shardID dht.ShardID,
+	followerID dht.NodeID,
+	offset int64,
+	replicateFn func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error),
+) bool {
+	ctx, span := tracing.Start(ctx, "cordinator.ReplicateToFollower",
+		tracing.ShardIDKey.Int(int(shardID)),
+		tracing.NodeIDKey.String(string(followerID)),
+	)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	remoteFollower, err := cp.getConnection(followerID)
+	if err != nil {
+		cp.log.Error("Unable to get follower connection", zap.String("follower", string(followerID)), zap.Error(err))
+		return false
+	}
+
+	followerOffset, err := replicateFn(ctx, remoteFollower)
+	if err != nil {
+		metrics.ReplicationErrors.WithLabelValues(metrics.ReplicationWrite).Inc()
+		cp.log.Error("Unable to replicate to follower",
+			zap.Int("shardID", int(shardID)),
+			zap.String("follower", string(followerID)),
+			zap.Int64("offset", offset),
+			zap.Error(err),
+		)
+		return false
+	}
+	cp.replicator.RecordFollowerOffset(shardID, followerID, followerOffset)
+
+	return followerOffset >= offset
+}
+
+// replay returns the result of the earlier set of the idempotency record. The followers that have
+// caught up with the offset of the earlier set are counted as acknowledged
+func (cp *CordinatorProcess) replay(ctx context.Context, shardLoc dht.ShardLocation, wc jm.WriteConcern, record *jm.IdempotencyRecord) (jm.WriteResult, error) {
+	result := cp.replicate(ctx, shardLoc, wc, record.Offset,
+		func(_ context.Context, follower jobstore.JobStoreWithReplicator) (int64, error) {
+			return follower.GetShardOffset(shardLoc.ID)
+		},
+	)
+	result.Version = record.Version
+	result.Replayed = true
+	if !result.Satisfied() {
+		return result, jm.ErrWriteConcernNotSatisfied
+	}
+
+	return result, nil
+}
+
+// getWriteConcern returns the write concern of the request if present, else the default of the collection
+func (cp *CordinatorProcess) getWriteConcern(collection string, opts jm.WriteOptions) jm.WriteConcern {
+	if opts.WriteConcern != "" {
+		return opts.WriteConcern
+	}
+
+	if c := cp.cStore.GetCollection(collection); c != nil && c.WriteConcern != "" {
+		return c.WriteConcern
+	}
+
+	return jm.DefaultWriteConcern
 }
 
 // ReplicateSetJob can be only called from the master
-func (cp *CordinatorProcess) ReplicateSetJob(collection string, job *jm.Job) (offset int64, err error) {
+func (cp *CordinatorProcess) ReplicateSetJob(ctx context.Context, collection string, job *jm.Job, leaderOffset int64) (offset int64, err error) {
+	_, span := tracing.Start(ctx, "cordinator.ReplicateSetJob", tracing.CollectionKey.String(collection), tracing.JobIDKey.String(job.ID))
+	defer func() { tracing.End(span, err) }()
+
+	epoch := cp.dhtMgr.Epoch()
 	shardLoc, err := cp.dhtMgr.GetShard(job.ID)
 	if err != nil {
 		return 0, err
 	}
+	if err = jobstore.CheckReplicated(ctx, shardLoc, epoch); err != nil {
+		return 0, err
+	}
 
-	localFollowerSlot, err := cp.nodeMgr.GetLocalShard(shardLoc.ID)
+	by, err := job.ToBytes()
 	if err != nil {
-		return 0, errors.Wrap(err, "follower slot: ")
+		return 0, err
 	}
 
-	if offset, err := localFollowerSlot.SetJob(collection, job); err != nil {
+	offset, err = cp.replicator.Replicate(shardLoc.ID, wal.LogEntry{
+		Operation:  wal.SetLog,
+		Collection: collection,
+		Data:       by,
+		Offset:     leaderOffset,
+	})
+	if err != nil {
 		return offset, errors.Wrap(err, "follower slot: ")
 	}
 
 	return offset, nil
 }
 
-func (cp *CordinatorProcess) ReplicateDeleteJob(collection, jobID string) (offset int64, err error) {
+func (cp *CordinatorProcess) ReplicateDeleteJob(ctx context.Context, collection, jobID string, leaderOffset int64) (offset int64, err error) {
+	_, span := tracing.Start(ctx, "cordinator.ReplicateDeleteJob", tracing.CollectionKey.String(collection), tracing.JobIDKey.String(jobID))
+	defer func() { tracing.End(span, err) }()
+
+	epoch := cp.dhtMgr.Epoch()
 	shardLoc, err := cp.dhtMgr.GetShard(jobID)
 	if err != nil {
 		return 0, err
 	}
-
-	localFollowerSlot, err := cp.nodeMgr.GetLocalShard(shardLoc.ID)
-	if err != nil {
-		return 0, errors.Wrap(err, "follower slot: ")
+	if err = jobstore.CheckReplicated(ctx, shardLoc, epoch); err != nil {
+		return 0, err
 	}
 
-	if offset, err := localFollowerSlot.DeleteJob(collection, jobID); err != nil {
+	offset, err = cp.replicator.Replicate(shardLoc.ID, wal.LogEntry{
+		Operation:  wal.DeleteLog,
+		Collection: collection,
+		Data:       []byte(jobID),
+		Offset:     leaderOffset,
+	})
+	if err != nil {
 		return offset, errors.Wrap(err, "follower slot: ")
 	}
 
 	return offset, nil
 }
 
+// StreamLogEntries streams the wal entries of the local shard to the follower
+func (cp *CordinatorProcess) StreamLogEntries(follower dht.NodeID, shardID dht.ShardID, offset int64, f func(wal.LogEntry) error) error {
+	shard, err := cp.nodeMgr.GetLocalShard(shardID)
+	if err != nil {
+		return err
+	}
+	if shard == nil {
+		return ErrShardNotFound
+	}
+
+	// The follower has all the entries till the offset
+	cp.replicator.RecordFollowerOffset(shardID, follower, offset)
+
+	return shard.StreamLogEntries(offset, f)
+}
+
+// StreamShardSnapshot streams a snapshot of the local shard to the follower
+func (cp *CordinatorProcess) StreamShardSnapshot(follower dht.NodeID, shardID dht.ShardID, snapshotOffset, byteOffset int64, f func(jobstore.SnapshotChunk) error) error {
+	shard, err := cp.nodeMgr.GetLocalShard(shardID)
+	if err != nil {
+		return err
+	}
+	if shard == nil {
+		return ErrShardNotFound
+	}
+
+	cp.log.Info("Streaming shard snapshot",
+		zap.Int("shardID", int(shardID)),
+		zap.String("follower", string(follower)),
+		zap.Int64("byteOffset", byteOffset),
+	)
+
+	return shard.StreamSnapshot(snapshotOffset, byteOffset, f)
+}
+
+// GetShardOffset returns the latest wal offset of the shard on this node
+func (cp *CordinatorProcess) GetShardOffset(shardID dht.ShardID) (int64, error) {
+	shard, err := cp.nodeMgr.GetLocalShard(shardID)
+	if err != nil {
+		return 0, err
+	}
+	if shard == nil {
+		return 0, ErrShardNotFound
+	}
+
+	return shard.GetLatestOffset(), nil
+}
+
+// GetShardOffsets returns the latest wal offset and the high water mark of the shard on this node
+func (cp *CordinatorProcess) GetShardOffsets(shardID dht.ShardID) (jobstore.ShardOffsets, error) {
+	shard, err := cp.nodeMgr.GetLocalShard(shardID)
+	if err != nil {
+		return jobstore.ShardOffsets{}, err
+	}
+	if shard == nil {
+		return jobstore.ShardOffsets{}, ErrShardNotFound
+	}
+
+	return jobstore.ShardOffsets{
+		Applied:       shard.GetLatestOffset(),
+		HighWaterMark: shard.GetHighWaterMark(),
+	}, nil
+}
+
 func (cp *CordinatorProcess) Type() jobstore.JobStoreType {
 	return jobstore.Cordinator
 }
@@ -273,7 +661,47 @@ func (cp *CordinatorProcess) DeleteRoute(routeID string) error {
 	return cp.cp.Apply(by)
 }
 
-func (cp *CordinatorProcess) HealthCheck() (bool, error) {
+func (cp *CordinatorProcess) GetCollection(name string) (*cm.Collection, error) {
+	if name == "" {
+		return nil, ErrInvalidDetails
+	}
+
+	collection := cp.cStore.GetCollection(name)
+	if collection == nil {
+		return nil, ErrCollectionNotFound
+	}
+
+	return collection, nil
+}
+
+// SetCollection sets the defaults of the collection across the cluster
+func (cp *CordinatorProcess) SetCollection(collection *cm.Collection) error {
+	if err := collection.Valid(); err != nil {
+		return err
+	}
+
+	by, err := consensus.ConvertAddCollection(collection)
+	if err != nil {
+		return err
+	}
+
+	return cp.cp.Apply(by)
+}
+
+func (cp *CordinatorProcess) DeleteCollection(name string) error {
+	if name == "" {
+		return ErrInvalidDetails
+	}
+
+	by, err := consensus.ConvertRemoveCollection(name)
+	if err != nil {
+		return err
+	}
+
+	return cp.cp.Apply(by)
+}
+
+func (cp *CordinatorProcess) HealthCheck(ctx context.Context) (bool, error) {
 	return true, nil // We are ready to accept new requests. So we always return true
 }
 
@@ -0,0 +1,260 @@
+package cordinator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/executor"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	dsm "github.com/aarthikrao/timeMachine/process/datastoremanager"
+	"github.com/aarthikrao/timeMachine/process/nodemanager"
+	"github.com/aarthikrao/timeMachine/process/replicator"
+	"go.uber.org/zap"
+)
+
+var errFollowerDown = errors.New("follower is down")
+
+// testFollower acknowledges the replicated writes of the leader
+type testFollower struct {
+	jobstore.JobStoreWithReplicator
+
+	// The follower returns the error after the delay, or the offset lag entries behind the write
+	err   error
+	delay time.Duration
+	lag   int64
+
+	// The write is acknowledged only once block is closed
+	block chan struct{}
+
+	mu       sync.Mutex
+	received []int64
+}
+
+func (f *testFollower) acknowledge(leaderOffset int64) (int64, error) {
+	f.mu.Lock()
+	f.received = append(f.received, leaderOffset)
+	f.mu.Unlock()
+
+	if f.block != nil {
+		<-f.block
+	}
+	time.Sleep(f.delay)
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	return leaderOffset - f.lag, nil
+}
+
+func (f *testFollower) ReplicateSetJob(ctx context.Context, collection string, job *jm.Job, leaderOffset int64) (int64, error) {
+	return f.acknowledge(leaderOffset)
+}
+
+func (f *testFollower) ReplicateDeleteJob(ctx context.Context, collection, jobID string, leaderOffset int64) (int64, error) {
+	return f.acknowledge(leaderOffset)
+}
+
+// getReceived returns the number of writes received by the follower
+func (f *testFollower) getReceived() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.received)
+}
+
+// createTestCordinator returns the cordinator of node1, that leads shard 0 replicated to the followers and the joining nodes.
+// The leader shard is opened only if open is true
+func createTestCordinator(t *testing.T, nodes map[dht.NodeID]*testFollower, followers, joining []dht.NodeID, open bool) *CordinatorProcess {
+	shard := dht.ShardLocation{ID: 0, Leader: dht.NodeDetails{ID: "node1"}}
+	for _, node := range followers {
+		shard.Followers = append(shard.Followers, dht.NodeDetails{ID: node})
+	}
+	for _, node := range joining {
+		shard.Joining = append(shard.Joining, dht.NodeDetails{ID: node})
+	}
+
+	appDht := dht.Create()
+	appDht.Load(map[dht.ShardID]dht.ShardLocation{0: shard}, 1)
+
+	dataStoreMgr := dsm.CreateDataStore(t.TempDir(), zap.NewNop())
+	shards := []dht.ShardID{}
+	if open {
+		shards = append(shards, 0)
+	}
+	if err := dataStoreMgr.InitialiseDataStores(shards); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(dataStoreMgr.Close)
+
+	jobCh := make(chan *jm.Job, 10)
+	exe := executor.NewExecutor(jobCh, time.Minute, 50*time.Millisecond)
+
+	nodeMgr := nodemanager.CreateNodeManager("node1", dataStoreMgr, nil, appDht, nil, nil, exe, 0, 0, zap.NewNop())
+	rep := replicator.CreateReplicator("node1", appDht, dataStoreMgr, nil, time.Hour, zap.NewNop())
+
+	cp := CreateCordinatorProcess("node1", nodeMgr, nil, nil, nil, appDht, exe, rep, jm.DefaultMisfirePolicy, time.Minute, time.Hour, zap.NewNop())
+	cp.getConnection = func(nodeID dht.NodeID) (jobstore.JobStoreWithReplicator, error) {
+		follower, ok := nodes[nodeID]
+		if !ok {
+			return nil, errFollowerDown
+		}
+		return follower, nil
+	}
+
+	return cp
+}
+
+func TestReplicate(t *testing.T) {
+	tests := []struct {
+		name      string
+		wc        jm.WriteConcern
+		followers map[dht.NodeID]*testFollower
+		joining   *testFollower
+		wantAcks  int
+		satisfied bool
+	}{
+		{
+			name:      "all followers",
+			wc:        jm.WriteConcernAll,
+			followers: map[dht.NodeID]*testFollower{"node2": {}, "node3": {}},
+			wantAcks:  3,
+			satisfied: true,
+		},
+		{
+			name:      "all with a follower down",
+			wc:        jm.WriteConcernAll,
+			followers: map[dht.NodeID]*testFollower{"node2": {}, "node3": {err: errFollowerDown, delay: 100 * time.Millisecond}},
+			wantAcks:  2,
+		},
+		{
+			name:      "quorum with a follower down",
+			wc:        jm.WriteConcernQuorum,
+			followers: map[dht.NodeID]*testFollower{"node2": {}, "node3": {err: errFollowerDown}},
+			wantAcks:  2,
+			satisfied: true,
+		},
+		{
+			name:      "quorum with a lagging follower",
+			wc:        jm.WriteConcernQuorum,
+			followers: map[dht.NodeID]*testFollower{"node2": {lag: 1}, "node3": {err: errFollowerDown}},
+			wantAcks:  1,
+		},
+		{
+			name:      "quorum does not count the joining node",
+			wc:        jm.WriteConcernQuorum,
+			followers: map[dht.NodeID]*testFollower{"node2": {err: errFollowerDown}, "node3": {err: errFollowerDown}},
+			joining:   &testFollower{},
+			wantAcks:  1,
+		},
+		{
+			name:      "one does not wait for the followers",
+			wc:        jm.WriteConcernOne,
+			followers: map[dht.NodeID]*testFollower{"node2": {block: make(chan struct{})}, "node3": {block: make(chan struct{})}},
+			wantAcks:  1,
+			satisfied: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := make(map[dht.NodeID]*testFollower)
+			for node, follower := range tt.followers {
+				nodes[node] = follower
+			}
+			joining := []dht.NodeID{}
+			if tt.joining != nil {
+				nodes["node4"] = tt.joining
+				joining = append(joining, "node4")
+			}
+			cp := createTestCordinator(t, nodes, []dht.NodeID{"node2", "node3"}, joining, true)
+
+			shardLoc, err := cp.dhtMgr.GetShardLocation(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := cp.replicate(context.Background(), shardLoc, tt.wc, 5,
+				func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error) {
+					return follower.ReplicateDeleteJob(ctx, "collection1", "job1", 5)
+				},
+			)
+			for _, follower := range tt.followers {
+				if follower.block != nil {
+					close(follower.block)
+				}
+			}
+
+			want := jm.WriteResult{Offset: 5, WriteConcern: tt.wc, Acknowledged: tt.wantAcks, Replicas: 3}
+			if result != want {
+				t.Errorf("replicate() = %+v, want %+v", result, want)
+			}
+			if result.Satisfied() != tt.satisfied {
+				t.Errorf("replicate() satisfied = %v, want %v", result.Satisfied(), tt.satisfied)
+			}
+
+			// The joining node receives the write in the background
+			if tt.joining != nil {
+				deadline := time.Now().Add(time.Second)
+				for tt.joining.getReceived() == 0 && time.Now().Before(deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+				if tt.joining.getReceived() != 1 {
+					t.Errorf("joining node received %d writes, want 1", tt.joining.getReceived())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteConcernNotSatisfied(t *testing.T) {
+	// The write concern can no longer be satisfied once the follower that is down responds, hence it responds last
+	nodes := map[dht.NodeID]*testFollower{"node2": {}, "node3": {err: errFollowerDown, delay: 100 * time.Millisecond}}
+	cp := createTestCordinator(t, nodes, []dht.NodeID{"node2", "node3"}, nil, true)
+	opts := jm.WriteOptions{WriteConcern: jm.WriteConcernAll}
+
+	// The set is committed on the leader and the follower that acknowledged it
+	job := &jm.Job{ID: "job1", TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()), Route: "route1"}
+	result, err := cp.SetJobWithOptions(context.Background(), "collection1", job, opts)
+	if err != jm.ErrWriteConcernNotSatisfied {
+		t.Fatalf("SetJobWithOptions() error = %v, want %v", err, jm.ErrWriteConcernNotSatisfied)
+	}
+	want := jm.WriteResult{Offset: 0, WriteConcern: jm.WriteConcernAll, Acknowledged: 2, Replicas: 3, Version: job.Version}
+	if result != want {
+		t.Errorf("SetJobWithOptions() = %+v, want %+v", result, want)
+	}
+
+	stored, err := cp.GetJob("collection1", "job1")
+	if err != nil || stored == nil {
+		t.Fatalf("GetJob() = %v, %v, want the job set on the leader", stored, err)
+	}
+
+	result, err = cp.DeleteJobWithOptions(context.Background(), "collection1", "job1", opts)
+	if err != jm.ErrWriteConcernNotSatisfied {
+		t.Fatalf("DeleteJobWithOptions() error = %v, want %v", err, jm.ErrWriteConcernNotSatisfied)
+	}
+	want = jm.WriteResult{Offset: 1, WriteConcern: jm.WriteConcernAll, Acknowledged: 2, Replicas: 3}
+	if result != want {
+		t.Errorf("DeleteJobWithOptions() = %+v, want %+v", result, want)
+	}
+	if received := nodes["node2"].getReceived(); received != 2 {
+		t.Errorf("follower received %d writes, want 2", received)
+	}
+}
+
+func TestWriteBeforeShardIsOpen(t *testing.T) {
+	cp := createTestCordinator(t, map[dht.NodeID]*testFollower{}, nil, nil, false)
+	opts := jm.WriteOptions{WriteConcern: jm.WriteConcernOne}
+
+	job := &jm.Job{ID: "job1", TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()), Route: "route1"}
+	if _, err := cp.SetJobWithOptions(context.Background(), "collection1", job, opts); err != ErrShardNotFound {
+		t.Errorf("SetJobWithOptions() error = %v, want %v", err, ErrShardNotFound)
+	}
+	if _, err := cp.DeleteJobWithOptions(context.Background(), "collection1", "job1", opts); err != ErrShardNotFound {
+		t.Errorf("DeleteJobWithOptions() error = %v, want %v", err, ErrShardNotFound)
+	}
+}
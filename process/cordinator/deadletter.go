@@ -0,0 +1,69 @@
+package cordinator
+
+import (
+	"context"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	timeutil "github.com/aarthikrao/timeMachine/utils/time"
+	"go.uber.org/zap"
+)
+
+// Delay after which a redriven job is published
+const redriveDelay = time.Second
+
+// ListDeadLetterJobs returns the dead lettered jobs of the collection from the leaders of all the shards
+func (cp *CordinatorProcess) ListDeadLetterJobs(collection string) ([]*jm.Job, error) {
+	if collection == "" {
+		return nil, ErrInvalidDetails
+	}
+
+	return cp.gatherFromLeaders(func(shardID dht.ShardID, js jobstore.JobStoreWithReplicator) ([]*jm.Job, error) {
+		return js.GetDeadLetterJobs(shardID, collection)
+	})
+}
+
+// GetDeadLetterJobs returns the dead lettered jobs of the collection in the local shard
+func (cp *CordinatorProcess) GetDeadLetterJobs(shardID dht.ShardID, collection string) ([]*jm.Job, error) {
+	shard, err := cp.nodeMgr.GetLocalShard(shardID)
+	if err != nil {
+		return nil, err
+	}
+	if shard == nil {
+		return nil, ErrShardNotFound
+	}
+
+	return shard.FetchDeadLetterJobs(collection)
+}
+
+// RedriveJob schedules the dead lettered job again. The job is published at its trigger time
+// if it is still in the future, else right away. The attempts of the job are reset, and its history is retained.
+func (cp *CordinatorProcess) RedriveJob(ctx context.Context, collection, jobID string, opts jm.WriteOptions) (jm.WriteResult, error) {
+	job, err := cp.GetJob(collection, jobID)
+	if err != nil {
+		return jm.WriteResult{}, err
+	}
+
+	if job.State != jm.ExecutionDeadLettered {
+		return jm.WriteResult{}, ErrJobNotDeadLettered
+	}
+
+	now := timeutil.GetCurrentMillis()
+	if earliest := now + int(redriveDelay.Milliseconds()); job.TriggerMS < earliest {
+		job.TriggerMS = earliest
+	}
+
+	cp.log.Info("Redriving job",
+		zap.String("collection", collection),
+		zap.String("jobID", jobID),
+		zap.Int("attempts", job.Attempts),
+		zap.String("lastError", job.LastError),
+	)
+
+	// The redrive must not be taken as a retry of the set with the idempotency key of the job
+	job.IdempotencyKey = ""
+	job.Reschedule(now)
+	return cp.SetJobWithOptions(ctx, collection, job, opts)
+}
@@ -6,4 +6,17 @@ var (
 	ErrInvalidDetails = errors.New("invalid details")
 
 	ErrRouteNotFound = errors.New("route not found")
+
+	ErrCollectionNotFound = errors.New("collection not found")
+
+	ErrShardNotFound = errors.New("shard not found on this node")
+
+	// The job was picked up after the misfire threshold and was not published as per its misfire policy
+	ErrJobMisfired = errors.New("job misfired")
+
+	// Only the dead lettered jobs can be redriven
+	ErrJobNotDeadLettered = errors.New("job is not dead lettered")
+
+	// The leader of a shard did not return the result of every job in the batch
+	ErrBatchResultMismatch = errors.New("batch results do not match the jobs")
 )
@@ -0,0 +1,240 @@
+package cordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/executor"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	"github.com/aarthikrao/timeMachine/process/nodemanager"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// ClaimJob marks the job as dispatched on the leader shard and replicates the claim to the followers
+// before the job is published. It returns an error if the job must not be published, i.e. if this node
+// no longer leads the shard, if the job has been deleted, updated, already claimed or delivered, or if
+// the claim is not acknowledged as per the write concern of the collection. A job whose claim is not
+// acknowledged is retried after a backoff. The job is updated with the execution details of the stored
+// job, like the number of attempts.
+//
+// A job is published once, unless the shard leader fails after it published the job and before the
+// delivery was recorded. The new leader then publishes the job again with a higher number of attempts.
+//
+// A job claimed later than the misfire threshold after its trigger time is handled as per its misfire
+// policy, and ErrJobMisfired is returned if it must not be published.
+func (cp *CordinatorProcess) ClaimJob(job *jm.Job) error {
+	if !cp.isMisfired(job) {
+		return cp.dispatchJob(job)
+	}
+
+	policy := cp.getMisfirePolicy(job.Collection)
+	cp.log.Warn("Job misfired",
+		zap.String("collection", job.Collection),
+		zap.String("jobID", job.ID),
+		zap.Int("triggerMS", job.TriggerMS),
+		zap.String("policy", string(policy)),
+	)
+
+	switch policy {
+	case jm.MisfireSkip:
+		if err := cp.finishJob(job, jm.ExecutionUpdate{State: jm.ExecutionSkipped}); err != nil {
+			return err
+		}
+		return ErrJobMisfired
+
+	case jm.MisfireDeadLetter:
+		// Recurring jobs are not rescheduled, so that the job can be inspected before it is set again
+		if _, err := cp.setExecutionState(job, jm.ExecutionUpdate{State: jm.ExecutionDeadLettered, Error: ErrJobMisfired.Error()}); err != nil {
+			return err
+		}
+		return ErrJobMisfired
+	}
+
+	return cp.dispatchJob(job)
+}
+
+// dispatchJob marks the job as dispatched and copies the stored execution details to the job
+func (cp *CordinatorProcess) dispatchJob(job *jm.Job) error {
+	claim := fmt.Sprintf("%s/%d/%d", cp.selfNodeID, cp.dhtMgr.Epoch(), cp.startedMS)
+	stored, err := cp.setExecutionState(job, jm.ExecutionUpdate{State: jm.ExecutionDispatched, ClaimedBy: claim})
+	if err == jm.ErrWriteConcernNotSatisfied {
+		// A new shard leader would not know of the claim and publish the job again
+		if rerr := cp.retryJob(stored, 0, err); rerr != nil {
+			cp.log.Error("Unable to retry unacknowledged claim", zap.String("jobID", job.ID), zap.Error(rerr))
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	*job = *stored
+	return nil
+}
+
+// CompleteJob records the delivery of the job along with the response code of the route once it has been published.
+// Recurring jobs are rescheduled instead, which replaces the delivered occurrence. The delivery is retained in its history.
+func (cp *CordinatorProcess) CompleteJob(job *jm.Job, responseCode int) error {
+	return cp.finishJob(job, jm.ExecutionUpdate{State: jm.ExecutionDelivered, ResponseCode: responseCode})
+}
+
+// FailJob is called when the job could not be published. The job is retried after a backoff as per the
+// retry policy of its route. After the last attempt, it is moved to the dead letter collection of its shard.
+// The response code of the route is recorded in the history of the job.
+func (cp *CordinatorProcess) FailJob(job *jm.Job, responseCode int, publishErr error) error {
+	policy := cp.rStore.GetRoute(job.Route).GetRetryPolicy()
+
+	if job.Attempts >= policy.MaxAttempts {
+		_, err := cp.setExecutionState(job, jm.ExecutionUpdate{
+			State:        jm.ExecutionDeadLettered,
+			Error:        publishErr.Error(),
+			ResponseCode: responseCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		cp.log.Warn("Moved job to dead letter",
+			zap.String("collection", job.Collection),
+			zap.String("jobID", job.ID),
+			zap.Int("attempts", job.Attempts),
+			zap.Error(publishErr),
+		)
+		return nil
+	}
+
+	return cp.retryJob(job, responseCode, publishErr)
+}
+
+// retryJob moves the job to retrying and queues it again after the backoff of its route
+func (cp *CordinatorProcess) retryJob(job *jm.Job, responseCode int, publishErr error) error {
+	policy := cp.rStore.GetRoute(job.Route).GetRetryPolicy()
+	nextAttempt := time.Now().Add(policy.Backoff(job.Attempts))
+	stored, err := cp.setExecutionState(job, jm.ExecutionUpdate{
+		State:         jm.ExecutionRetrying,
+		Error:         publishErr.Error(),
+		NextAttemptMS: int(nextAttempt.UnixMilli()),
+		ResponseCode:  responseCode,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The retries that are not within the grace period of the executor are queued by the poller
+	if err = cp.jobExecutor.Queue(*stored); err != nil && err != executor.ErrNotWithinExecutorGracePeriod {
+		return err
+	}
+
+	cp.log.Info("Retrying job",
+		zap.String("collection", job.Collection),
+		zap.String("jobID", job.ID),
+		zap.Int("attempts", job.Attempts),
+		zap.Time("nextAttempt", nextAttempt),
+	)
+	return nil
+}
+
+// finishJob moves the job to the final execution state, or reschedules it to its next occurrence.
+// The final state of the occurrence is recorded in the history of the rescheduled job.
+func (cp *CordinatorProcess) finishJob(job *jm.Job, update jm.ExecutionUpdate) error {
+	now := time.Now()
+	next, ok, err := job.NextOccurrence(now)
+	if err != nil || !ok {
+		if _, serr := cp.setExecutionState(job, update); serr != nil {
+			return serr
+		}
+		return err
+	}
+
+	nowMS := int(now.UnixMilli())
+	if err = next.ApplyExecution(update, nowMS); err != nil {
+		return err
+	}
+	next.Reschedule(nowMS)
+
+	if _, err = cp.SetJob(job.Collection, next); err != nil {
+		return errors.Wrap(err, "reschedule job: ")
+	}
+
+	cp.log.Debug("Rescheduled job",
+		zap.String("collection", next.Collection),
+		zap.String("jobID", next.ID),
+		zap.Int("triggerMS", next.TriggerMS),
+	)
+	return nil
+}
+
+// isMisfired returns true if the job is picked up later than the misfire threshold after its trigger time
+func (cp *CordinatorProcess) isMisfired(job *jm.Job) bool {
+	return time.Since(job.GetTriggerTime()) > cp.misfireThreshold
+}
+
+// getMisfirePolicy returns the misfire policy of the collection if present, else the misfire policy of this node
+func (cp *CordinatorProcess) getMisfirePolicy(collection string) jm.MisfirePolicy {
+	if c := cp.cStore.GetCollection(collection); c != nil && c.MisfirePolicy != "" {
+		return c.MisfirePolicy
+	}
+
+	if cp.misfirePolicy != "" {
+		return cp.misfirePolicy
+	}
+
+	return jm.DefaultMisfirePolicy
+}
+
+// setExecutionState moves the job to the execution state on the leader shard and replicates it to the followers.
+// It returns the updated job. A claim fails with jm.ErrWriteConcernNotSatisfied along with the updated job if the
+// write concern is not satisfied. The other states are not failed, as the job has already been published, and the
+// followers catch up with the leader shard in the background.
+func (cp *CordinatorProcess) setExecutionState(job *jm.Job, update jm.ExecutionUpdate) (*jm.Job, error) {
+	ctx, err := cp.fence(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	shardLoc, err := cp.dhtMgr.GetShard(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if shardLoc.Leader.ID != cp.selfNodeID {
+		// The new leader of the shard delivers the job
+		return nil, nodemanager.ErrNotShardLeader
+	}
+
+	shard, err := cp.nodeMgr.GetLocalShard(shardLoc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if shard == nil {
+		return nil, ErrShardNotFound
+	}
+
+	offset, stored, err := shard.SetExecutionState(job.Collection, job.ID, job.TriggerMS, update)
+	if err != nil {
+		return nil, err
+	}
+
+	result := cp.replicate(ctx, shardLoc, cp.getWriteConcern(job.Collection, jm.WriteOptions{}), offset,
+		func(ctx context.Context, follower jobstore.JobStoreWithReplicator) (int64, error) {
+			return follower.ReplicateSetJob(ctx, job.Collection, stored, offset)
+		},
+	)
+	if !result.Satisfied() {
+		cp.log.Warn("Execution state not acknowledged by the followers",
+			zap.String("jobID", job.ID),
+			zap.String("state", string(update.State)),
+			zap.Int("acknowledged", result.Acknowledged),
+			zap.Int("replicas", result.Replicas),
+		)
+
+		if update.State == jm.ExecutionDispatched {
+			return stored, jm.ErrWriteConcernNotSatisfied
+		}
+	}
+
+	return stored, nil
+}
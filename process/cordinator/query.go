@@ -0,0 +1,108 @@
+package cordinator
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+)
+
+// ListJobs returns a page of the jobs of the collection that match the query from the leaders of all the shards.
+// Every shard returns the jobs after the cursor of the query in the same order, hence the pages are merged and
+// the next cursor is the position of the last job in the merged page.
+func (cp *CordinatorProcess) ListJobs(collection string, query jm.JobQuery) (jm.JobPage, error) {
+	if collection == "" {
+		return jm.JobPage{}, ErrInvalidDetails
+	}
+
+	if err := query.Valid(); err != nil {
+		return jm.JobPage{}, err
+	}
+	limit := query.Limit
+
+	// One more job is fetched from every shard to know if there is a next page
+	query.Limit++
+	jobs, err := cp.gatherFromLeaders(func(shardID dht.ShardID, js jobstore.JobStoreWithReplicator) ([]*jm.Job, error) {
+		return js.GetJobs(shardID, collection, query)
+	})
+	if err != nil {
+		return jm.JobPage{}, err
+	}
+
+	sort.Slice(jobs, func(i, j int) bool { return query.Less(jobs[i], jobs[j]) })
+
+	page := jm.JobPage{Jobs: jobs}
+	if len(jobs) > limit {
+		page.Jobs = jobs[:limit]
+		page.NextCursor = query.GetCursor(jobs[limit-1])
+	}
+
+	return page, nil
+}
+
+// GetJobs returns the jobs of the collection that match the query in the local shard.
+// The query is validated by ListJobs, which may fetch one job more than the maximum limit
+func (cp *CordinatorProcess) GetJobs(shardID dht.ShardID, collection string, query jm.JobQuery) ([]*jm.Job, error) {
+	if query.Limit <= 0 {
+		query.Limit = jm.DefaultListLimit
+	}
+
+	shard, err := cp.nodeMgr.GetLocalShard(shardID)
+	if err != nil {
+		return nil, err
+	}
+	if shard == nil {
+		return nil, ErrShardNotFound
+	}
+
+	return shard.ListJobs(collection, query)
+}
+
+// gatherFromLeaders calls f on the leaders of all the shards in parallel and returns all the jobs returned by them.
+// This node is called directly for the shards that it leads. It returns the first error returned by f.
+func (cp *CordinatorProcess) gatherFromLeaders(f func(shardID dht.ShardID, js jobstore.JobStoreWithReplicator) ([]*jm.Job, error)) ([]*jm.Job, error) {
+	shards := cp.dhtMgr.Snapshot()
+	if len(shards) == 0 {
+		return nil, dht.ErrDHTNotInitialised
+	}
+
+	shardIDs := make([]dht.ShardID, 0, len(shards))
+	for shardID := range shards {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	results := make([][]*jm.Job, len(shardIDs))
+	errs := make([]error, len(shardIDs))
+
+	var wg sync.WaitGroup
+	for i, shardID := range shardIDs {
+		var js jobstore.JobStoreWithReplicator = cp
+		if leader := shards[shardID].Leader.ID; leader != cp.selfNodeID {
+			conn, err := cp.getConnection(leader)
+			if err != nil {
+				return nil, err
+			}
+			js = conn
+		}
+
+		wg.Add(1)
+		go func(i int, shardID dht.ShardID, js jobstore.JobStoreWithReplicator) {
+			defer wg.Done()
+			results[i], errs[i] = f(shardID, js)
+		}(i, shardID, js)
+	}
+	wg.Wait()
+
+	jobs := []*jm.Job{}
+	for i := range shardIDs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		jobs = append(jobs, results[i]...)
+	}
+
+	return jobs, nil
+}
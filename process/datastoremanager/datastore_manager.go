@@ -6,7 +6,6 @@ import (
 
 	"github.com/aarthikrao/timeMachine/components/datashard"
 	"github.com/aarthikrao/timeMachine/components/dht"
-	js "github.com/aarthikrao/timeMachine/components/jobstore"
 	"go.uber.org/zap"
 )
 
@@ -16,11 +15,14 @@ var (
 
 type DataStoreManager struct {
 	// This list will contain the nodes owned by this instance of the server
-	slotsOwned map[dht.ShardID]js.JobFetcher
+	slotsOwned map[dht.ShardID]*datashard.DataShard
 
 	// path to the parent directory containing all the data
 	parentDirectory string
 
+	// true once the shards owned by this node are opened
+	initialised bool
+
 	mu  sync.RWMutex
 	log *zap.Logger
 }
@@ -29,15 +31,28 @@ type DataStoreManager struct {
 func CreateDataStore(parentDirectory string, log *zap.Logger) *DataStoreManager {
 	dsm := &DataStoreManager{
 		parentDirectory: parentDirectory,
-		slotsOwned:      make(map[dht.ShardID]js.JobFetcher),
+		slotsOwned:      make(map[dht.ShardID]*datashard.DataShard),
 		log:             log,
 	}
 
 	return dsm
 }
 
+// InitialiseDataStores opens the shards newly owned by this node and closes the shards it no longer owns.
+// It is called every time the shard map of the cluster changes.
 func (dsm *DataStoreManager) InitialiseDataStores(slots []dht.ShardID) error {
+	dsm.mu.Lock()
+	defer dsm.mu.Unlock()
+
+	owned := make(map[dht.ShardID]bool, len(slots))
 	for _, slot := range slots {
+		owned[slot] = true
+
+		if _, ok := dsm.slotsOwned[slot]; ok {
+			// Already open
+			continue
+		}
+
 		ds, err := datashard.InitialiseDataShard(slot, dsm.parentDirectory, dsm.log)
 		if err != nil {
 			return err
@@ -46,14 +61,27 @@ func (dsm *DataStoreManager) InitialiseDataStores(slots []dht.ShardID) error {
 		dsm.slotsOwned[slot] = ds
 	}
 
+	for slot, ds := range dsm.slotsOwned {
+		if owned[slot] {
+			continue
+		}
+
+		// The data files are retained. If the shard is owned again, it catches up from its latest offset
+		if err := ds.Close(); err != nil {
+			dsm.log.Error("Unable to close shard", zap.Int("shardID", int(slot)), zap.Error(err))
+		}
+		delete(dsm.slotsOwned, slot)
+	}
+
+	dsm.initialised = true
 	return nil
 }
 
-func (dsm *DataStoreManager) GetDataNode(slotID dht.ShardID) (js.JobFetcher, error) {
+func (dsm *DataStoreManager) GetDataNode(slotID dht.ShardID) (*datashard.DataShard, error) {
 	dsm.mu.RLock()
 	defer dsm.mu.RUnlock()
 
-	if len(dsm.slotsOwned) <= 0 {
+	if !dsm.initialised {
 		return nil, ErrDataStoreNotInitialised
 	}
 
@@ -61,6 +89,9 @@ func (dsm *DataStoreManager) GetDataNode(slotID dht.ShardID) (js.JobFetcher, err
 }
 
 func (dsm *DataStoreManager) Close() {
+	dsm.mu.Lock()
+	defer dsm.mu.Unlock()
+
 	for _, db := range dsm.slotsOwned {
 		db.Close()
 	}
@@ -0,0 +1,69 @@
+package nodemanager
+
+import (
+	"sort"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	"go.uber.org/zap"
+)
+
+// BalanceLeaders hands the leadership of at most maxShards shards over from the nodes leading the most
+// shards to their healthy followers, so that a node leads shards again after it rejoined its shards or
+// its leadership moved away during a failure. A follower takes over a shard only once it has caught up
+// with the leader shard, and the drained nodes are not given leadership. It must be called only on the
+// raft leader, and returns the shards whose leader was changed. It does nothing during redistribution.
+func (nm *NodeManager) BalanceLeaders(healthy []dht.NodeID, maxShards int) ([]dht.ShardID, error) {
+	if !nm.redistributeMu.TryLock() {
+		return nil, nil
+	}
+	defer nm.redistributeMu.Unlock()
+
+	nm.failoverMu.Lock()
+	defer nm.failoverMu.Unlock()
+
+	// The epoch is read first, so that a change applied in between is fenced
+	epoch := nm.dhtMgr.Epoch()
+	current := nm.dhtMgr.Snapshot()
+	if len(current) <= 0 {
+		return nil, dht.ErrDHTNotInitialised
+	}
+
+	isHealthy := make(map[dht.NodeID]bool, len(healthy))
+	for _, node := range healthy {
+		isHealthy[node] = true
+	}
+
+	balanced, moved := dht.BalanceLeaders(current, maxShards, func(shardID dht.ShardID, node dht.NodeID) bool {
+		leader := current[shardID].Leader.ID
+		if !isHealthy[leader] || !isHealthy[node] || nm.nodeConfig.IsDrained(node) {
+			return false
+		}
+
+		leaderOffset, err := nm.getShardOffset(leader, shardID)
+		if err != nil {
+			nm.log.Warn("Unable to get leader offset", zap.Int("shardID", int(shardID)), zap.Error(err))
+			return false
+		}
+
+		offset, err := nm.getShardOffset(node, shardID)
+		return err == nil && offset >= leaderOffset
+	})
+	if len(moved) == 0 {
+		return nil, nil
+	}
+
+	if err := nm.applyShards(balanced, epoch+1); err != nil {
+		return nil, err
+	}
+	metrics.LeaderHandovers.Add(float64(len(moved)))
+	nm.log.Info("Handed over shard leadership", zap.Any("leaders", moved))
+
+	shardIDs := make([]dht.ShardID, 0, len(moved))
+	for shardID := range moved {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	return shardIDs, nil
+}
@@ -0,0 +1,68 @@
+package nodemanager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+func TestFailedNodeLeadsAgain(t *testing.T) {
+	original := map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node1", "node2"),
+		1: createShard(1, "node2", "node3"),
+		2: createShard(2, "node3", "node1"),
+	}
+	nm, _, offsets := createTestNodeManager(original, "node1", "node2", "node3")
+	for _, node := range []dht.NodeID{"node1", "node2", "node3"} {
+		offsets.set(node, 0, 10, 10)
+		offsets.set(node, 1, 7, 7)
+		offsets.set(node, 2, 5, 5)
+	}
+
+	// Fail: node2 leads shard 0, and the spares copy the shards node1 owned
+	if _, err := nm.ReplaceFailedNode("node1", []dht.NodeID{"node2", "node3"}, 0, 5*time.Second); err != nil {
+		t.Fatalf("ReplaceFailedNode() error = %v", err)
+	}
+	if got, want := nm.nodeConfig.GetReplacedShards("node1"), map[dht.ShardID]dht.NodeID{0: "node3", 2: "node2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replaced shards = %v, want %v", got, want)
+	}
+	waitFor(t, 3*time.Second, func() bool {
+		shards := nm.dhtMgr.Snapshot()
+		return len(shards[0].Joining) == 0 && len(shards[2].Joining) == 0
+	})
+
+	// Return: node1 rejoins its shards in place of the spares
+	if err := nm.RejoinNode("node1", 5*time.Second); err != nil {
+		t.Fatalf("RejoinNode() error = %v", err)
+	}
+	if got := nm.nodeConfig.GetReplacedShards("node1"); len(got) != 0 {
+		t.Errorf("replaced shards after rejoining = %v, want none", got)
+	}
+	waitFor(t, 3*time.Second, func() bool {
+		shards := nm.dhtMgr.Snapshot()
+		return len(shards[0].Joining) == 0 && len(shards[2].Joining) == 0
+	})
+
+	want := map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node2", "node1"),
+		1: createShard(1, "node2", "node3"),
+		2: createShard(2, "node3", "node1"),
+	}
+	if shards := nm.dhtMgr.Snapshot(); !reflect.DeepEqual(shards, want) {
+		t.Fatalf("shard map after rejoining = %v, want %v", shards, want)
+	}
+
+	// Rebalance: node1 leads shard 0 again
+	moved, err := nm.BalanceLeaders([]dht.NodeID{"node1", "node2", "node3"}, 1)
+	if err != nil {
+		t.Fatalf("BalanceLeaders() error = %v", err)
+	}
+	if want := []dht.ShardID{0}; !reflect.DeepEqual(moved, want) {
+		t.Errorf("BalanceLeaders() = %v, want %v", moved, want)
+	}
+	if shards := nm.dhtMgr.Snapshot(); !reflect.DeepEqual(shards, original) {
+		t.Errorf("shard map after balancing = %v, want %v", shards, original)
+	}
+}
@@ -0,0 +1,220 @@
+package nodemanager
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"go.uber.org/zap"
+)
+
+// The node is not a server in the raft cluster
+var ErrUnknownNode = errors.New("node is not a part of the cluster")
+
+// DrainStatus is the progress of the drain of a node, as per the shard map applied on this node
+type DrainStatus struct {
+	NodeID  dht.NodeID `json:"node_id"`
+	Drained bool       `json:"drained"`
+
+	// Shards still led by the node
+	LeaderShards []dht.ShardID `json:"leader_shards"`
+
+	// Shards followed by the node. They are not moved by the drain
+	FollowerShards []dht.ShardID `json:"follower_shards"`
+
+	// True once the drained node leads no shard
+	Done bool `json:"done"`
+}
+
+// Drain marks the node as drained for maintenance, and hands the leadership of its shards over to their followers
+// in the background till the timeout. A follower takes over a shard only once it has caught up with the leader shard,
+// so that no write is lost. A drained node is not assigned new shards or leaderships till it is undrained.
+// It must be called only on the raft leader, and can be called again to resume a drain.
+func (nm *NodeManager) Drain(nodeID dht.NodeID, timeout time.Duration) (DrainStatus, error) {
+	if err := nm.setDrained(nodeID, true); err != nil {
+		return DrainStatus{}, err
+	}
+
+	go nm.drainLeaders(nodeID, timeout)
+
+	return nm.GetDrainStatus(nodeID), nil
+}
+
+// Undrain restores the normal operation of a drained node. The leader balancer hands the leadership of the shards
+// back to it gradually. It must be called only on the raft leader.
+func (nm *NodeManager) Undrain(nodeID dht.NodeID) (DrainStatus, error) {
+	if err := nm.setDrained(nodeID, false); err != nil {
+		return DrainStatus{}, err
+	}
+
+	return nm.GetDrainStatus(nodeID), nil
+}
+
+// GetDrainStatus returns the progress of the drain of the node
+func (nm *NodeManager) GetDrainStatus(nodeID dht.NodeID) DrainStatus {
+	status := DrainStatus{
+		NodeID:         nodeID,
+		Drained:        nm.nodeConfig.IsDrained(nodeID),
+		LeaderShards:   []dht.ShardID{},
+		FollowerShards: []dht.ShardID{},
+	}
+
+	for shardID, shard := range nm.dhtMgr.Snapshot() {
+		if shard.Leader.ID == nodeID {
+			status.LeaderShards = append(status.LeaderShards, shardID)
+			continue
+		}
+		for _, follower := range shard.Followers {
+			if follower.ID == nodeID {
+				status.FollowerShards = append(status.FollowerShards, shardID)
+				break
+			}
+		}
+	}
+	sort.Slice(status.LeaderShards, func(i, j int) bool { return status.LeaderShards[i] < status.LeaderShards[j] })
+	sort.Slice(status.FollowerShards, func(i, j int) bool { return status.FollowerShards[i] < status.FollowerShards[j] })
+
+	status.Done = status.Drained && len(status.LeaderShards) == 0
+	return status
+}
+
+// setDrained commits the drain or undrain of the node to the raft log, if it is not already applied
+func (nm *NodeManager) setDrained(nodeID dht.NodeID, drained bool) error {
+	nodes, err := nm.getAllServerIDs()
+	if err != nil {
+		return err
+	}
+	if !containsServer(nodes, string(nodeID)) {
+		return ErrUnknownNode
+	}
+
+	if nm.nodeConfig.IsDrained(nodeID) == drained {
+		return nil
+	}
+
+	by, err := consensus.ConvertDrainNode(nodeID, drained)
+	if err != nil {
+		return err
+	}
+
+	if err = nm.cp.Apply(by); err != nil {
+		return err
+	}
+	nm.log.Info("Changed drain of node", zap.String("node", string(nodeID)), zap.Bool("drained", drained))
+
+	return nil
+}
+
+// drainLeaders hands the shards led by the node over to their followers as they catch up. It stops once the node
+// leads no shard, the node is undrained, this node is no longer the raft leader or the timeout expires.
+func (nm *NodeManager) drainLeaders(nodeID dht.NodeID, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if !nm.nodeConfig.IsDrained(nodeID) || !nm.cp.IsLeader() {
+			nm.log.Info("Stopped draining node", zap.String("node", string(nodeID)))
+			return
+		}
+
+		pending, err := nm.handOverLeaders(nodeID)
+		if err != nil {
+			nm.log.Warn("Unable to hand over shard leadership", zap.String("node", string(nodeID)), zap.Error(err))
+		}
+		if err == nil && pending == 0 {
+			nm.log.Info("Drained node", zap.String("node", string(nodeID)))
+			return
+		}
+
+		if time.Now().After(deadline) {
+			nm.log.Error("Drain timed out", zap.String("node", string(nodeID)), zap.Any("leaderShards", nm.GetDrainStatus(nodeID).LeaderShards))
+			return
+		}
+		time.Sleep(catchUpCheckInterval)
+	}
+}
+
+// handOverLeaders hands every shard led by the node over to its most up-to-date follower that has caught up
+// with the leader shard, and is not drained. It returns the number of shards the node still leads.
+func (nm *NodeManager) handOverLeaders(nodeID dht.NodeID) (int, error) {
+	if !nm.redistributeMu.TryLock() {
+		// The shard map is changed by the redistribution, the shards are handed over on the next attempt
+		return len(nm.GetDrainStatus(nodeID).LeaderShards), ErrRedistributionInProgress
+	}
+	defer nm.redistributeMu.Unlock()
+
+	nm.failoverMu.Lock()
+	defer nm.failoverMu.Unlock()
+
+	// The epoch is read first, so that a change applied in between is fenced
+	epoch := nm.dhtMgr.Epoch()
+	current := nm.dhtMgr.Snapshot()
+
+	pending := 0
+	leaders := make(map[dht.ShardID]dht.NodeID)
+	for shardID, shard := range current {
+		if shard.Leader.ID != nodeID {
+			continue
+		}
+		pending++
+
+		leaderOffset, err := nm.getShardOffset(nodeID, shardID)
+		if err != nil {
+			nm.log.Warn("Unable to get leader offset", zap.Int("shardID", int(shardID)), zap.Error(err))
+			continue
+		}
+
+		var best dht.NodeID
+		var bestOffset int64
+		for _, follower := range shard.Followers {
+			if nm.nodeConfig.IsDrained(follower.ID) {
+				continue
+			}
+
+			offset, err := nm.getShardOffset(follower.ID, shardID)
+			if err != nil || offset < leaderOffset {
+				continue
+			}
+			if best == "" || offset > bestOffset {
+				best, bestOffset = follower.ID, offset
+			}
+		}
+
+		if best != "" {
+			leaders[shardID] = best
+		}
+	}
+	if len(leaders) == 0 {
+		return pending, nil
+	}
+
+	if err := nm.applyShards(dht.HandOverLeadership(current, leaders), epoch+1); err != nil {
+		return pending, err
+	}
+	nm.log.Info("Handed over shard leadership of drained node", zap.String("node", string(nodeID)), zap.Any("leaders", leaders))
+
+	return pending - len(leaders), nil
+}
+
+// getDrainedNodes returns the drained nodes among the nodes
+func (nm *NodeManager) getDrainedNodes(nodes []dht.NodeID) []dht.NodeID {
+	drained := []dht.NodeID{}
+	for _, node := range nodes {
+		if nm.nodeConfig.IsDrained(node) {
+			drained = append(drained, node)
+		}
+	}
+
+	return drained
+}
+
+func containsServer(nodes []string, nodeID string) bool {
+	for _, node := range nodes {
+		if node == nodeID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,201 @@
+package nodemanager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+func TestHandOverLeaders(t *testing.T) {
+	type offset struct {
+		node    dht.NodeID
+		shardID dht.ShardID
+		applied int64
+	}
+
+	tests := []struct {
+		name        string
+		drained     []dht.NodeID
+		offsets     []offset
+		wantLeaders map[dht.ShardID]dht.NodeID
+		wantPending int
+	}{
+		{
+			name:        "most up-to-date follower",
+			offsets:     []offset{{"node1", 0, 10}, {"node2", 0, 10}, {"node3", 0, 11}, {"node1", 1, 5}, {"node2", 1, 5}},
+			wantLeaders: map[dht.ShardID]dht.NodeID{0: "node3", 1: "node2"},
+		},
+		{
+			name:        "lagging follower",
+			offsets:     []offset{{"node1", 0, 10}, {"node2", 0, 9}, {"node3", 0, 10}, {"node1", 1, 5}, {"node2", 1, 4}},
+			wantLeaders: map[dht.ShardID]dht.NodeID{0: "node3", 1: "node1"},
+			wantPending: 1,
+		},
+		{
+			name:        "drained follower",
+			drained:     []dht.NodeID{"node3"},
+			offsets:     []offset{{"node1", 0, 10}, {"node2", 0, 10}, {"node3", 0, 11}, {"node1", 1, 5}, {"node2", 1, 5}},
+			wantLeaders: map[dht.ShardID]dht.NodeID{0: "node2", 1: "node2"},
+		},
+		{
+			name:        "leader offset unknown",
+			offsets:     []offset{{"node2", 0, 10}, {"node3", 0, 10}, {"node1", 1, 5}, {"node2", 1, 5}},
+			wantLeaders: map[dht.ShardID]dht.NodeID{0: "node1", 1: "node2"},
+			wantPending: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nm, _, offsets := createTestNodeManager(map[dht.ShardID]dht.ShardLocation{
+				0: createShard(0, "node1", "node2", "node3"),
+				1: createShard(1, "node1", "node2"),
+				2: createShard(2, "node2", "node1"),
+			}, "node1", "node2", "node3")
+			for _, node := range append([]dht.NodeID{"node1"}, tt.drained...) {
+				if err := nm.setDrained(node, true); err != nil {
+					t.Fatalf("setDrained() error = %v", err)
+				}
+			}
+			for _, o := range tt.offsets {
+				offsets.set(o.node, o.shardID, o.applied, o.applied)
+			}
+
+			pending, err := nm.handOverLeaders("node1")
+			if err != nil {
+				t.Fatalf("handOverLeaders() error = %v", err)
+			}
+			if pending != tt.wantPending {
+				t.Errorf("handOverLeaders() pending = %d, want %d", pending, tt.wantPending)
+			}
+
+			leaders := make(map[dht.ShardID]dht.NodeID)
+			for shardID, shard := range nm.dhtMgr.Snapshot() {
+				if shardID != 2 {
+					leaders[shardID] = shard.Leader.ID
+				}
+			}
+			if !reflect.DeepEqual(leaders, tt.wantLeaders) {
+				t.Errorf("leaders = %v, want %v", leaders, tt.wantLeaders)
+			}
+
+			status := nm.GetDrainStatus("node1")
+			if status.Done != (tt.wantPending == 0) {
+				t.Errorf("GetDrainStatus() done = %v with %d pending shards", status.Done, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestHandOverLeadersDuringRedistribution(t *testing.T) {
+	nm, _, _ := createTestNodeManager(map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node1", "node2"),
+		1: createShard(1, "node1", "node2"),
+	}, "node1", "node2")
+
+	nm.redistributeMu.Lock()
+	defer nm.redistributeMu.Unlock()
+
+	pending, err := nm.handOverLeaders("node1")
+	if err != ErrRedistributionInProgress {
+		t.Errorf("handOverLeaders() error = %v, want %v", err, ErrRedistributionInProgress)
+	}
+	if pending != 2 {
+		t.Errorf("handOverLeaders() pending = %d, want 2", pending)
+	}
+}
+
+func TestDrainLeadersStops(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(nm *NodeManager, cp *testConsensus) error
+	}{
+		{
+			name: "undrained",
+			stop: func(nm *NodeManager, cp *testConsensus) error {
+				_, err := nm.Undrain("node1")
+				return err
+			},
+		},
+		{
+			name: "raft leadership lost",
+			stop: func(nm *NodeManager, cp *testConsensus) error {
+				cp.leader.Store(false)
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nm, cp, offsets := createTestNodeManager(map[dht.ShardID]dht.ShardLocation{
+				0: createShard(0, "node1", "node2"),
+			}, "node1", "node2")
+
+			// The follower never catches up, hence the drain runs till it is stopped
+			offsets.set("node1", 0, 10, 10)
+			offsets.set("node2", 0, 5, 5)
+			if err := nm.setDrained("node1", true); err != nil {
+				t.Fatalf("setDrained() error = %v", err)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				nm.drainLeaders("node1", time.Minute)
+				close(done)
+			}()
+
+			if err := tt.stop(nm, cp); err != nil {
+				t.Fatalf("stop error = %v", err)
+			}
+			select {
+			case <-done:
+			case <-time.After(3 * time.Second):
+				t.Fatalf("drainLeaders() did not stop")
+			}
+
+			if leader := nm.dhtMgr.Snapshot()[0].Leader.ID; leader != "node1" {
+				t.Errorf("leader = %s, want node1", leader)
+			}
+		})
+	}
+}
+
+func TestGetDrainStatus(t *testing.T) {
+	nm, _, offsets := createTestNodeManager(map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node1", "node2"),
+		1: createShard(1, "node2", "node1"),
+	}, "node1", "node2")
+	offsets.set("node1", 0, 10, 10)
+	offsets.set("node2", 0, 10, 10)
+
+	if status := nm.GetDrainStatus("node1"); status.Drained || status.Done {
+		t.Errorf("GetDrainStatus() of a node that is not drained = %+v", status)
+	}
+
+	status, err := nm.Drain("node1", time.Minute)
+	if err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	want := DrainStatus{NodeID: "node1", Drained: true, LeaderShards: []dht.ShardID{0}, FollowerShards: []dht.ShardID{1}}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("Drain() = %+v, want %+v", status, want)
+	}
+
+	waitFor(t, 3*time.Second, func() bool { return nm.GetDrainStatus("node1").Done })
+	want = DrainStatus{NodeID: "node1", Drained: true, LeaderShards: []dht.ShardID{}, FollowerShards: []dht.ShardID{0, 1}, Done: true}
+	if status := nm.GetDrainStatus("node1"); !reflect.DeepEqual(status, want) {
+		t.Errorf("GetDrainStatus() = %+v, want %+v", status, want)
+	}
+
+	// An undrained node is not done even though it leads no shard
+	if status, err = nm.Undrain("node1"); err != nil || status.Drained || status.Done {
+		t.Errorf("Undrain() = %+v, %v, want a node that is not drained", status, err)
+	}
+
+	if _, err = nm.Drain("node4", time.Minute); err != ErrUnknownNode {
+		t.Errorf("Drain() of an unknown node error = %v, want %v", err, ErrUnknownNode)
+	}
+}
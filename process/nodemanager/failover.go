@@ -0,0 +1,163 @@
+package nodemanager
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	js "github.com/aarthikrao/timeMachine/components/jobstore"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	"go.uber.org/zap"
+)
+
+// chooseLeaders chooses the new leaders of the shards led by the failed node, and returns the decision for every shard.
+//
+// The wal offsets of the healthy followers of a shard are queried, and the follower with the latest offset is promoted.
+// Drained followers are promoted only if none of the other followers is caught up.
+// The high water mark is the highest offset of the failed leader replicated to any of the followers. If the chosen
+// follower is more than maxLag entries behind it, the failover is refused, as the entries after its offset would be lost.
+// The shard stays unavailable till the failed leader comes back or a follower catches up.
+func (nm *NodeManager) chooseLeaders(shards map[dht.ShardID]dht.ShardLocation, failed dht.NodeID, healthy []dht.NodeID, maxLag int64) (map[dht.ShardID]dht.NodeID, []fsm.FailoverDecision) {
+	isHealthy := make(map[dht.NodeID]bool, len(healthy))
+	for _, node := range healthy {
+		isHealthy[node] = true
+	}
+
+	shardIDs := []dht.ShardID{}
+	for shardID, shard := range shards {
+		if shard.Leader.ID == failed {
+			shardIDs = append(shardIDs, shardID)
+		}
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	leaders := make(map[dht.ShardID]dht.NodeID, len(shardIDs))
+	decisions := make([]fsm.FailoverDecision, 0, len(shardIDs))
+	for _, shardID := range shardIDs {
+		d := fsm.FailoverDecision{
+			ShardID:      shardID,
+			FailedLeader: failed,
+			Offsets:      make(map[dht.NodeID]int64),
+			TimeMS:       time.Now().UnixMilli(),
+		}
+
+		// best is the most up-to-date follower, and active is the most up-to-date follower that is not drained
+		var best, active dht.NodeID
+		for _, follower := range shards[shardID].Followers {
+			if follower.ID == failed || !isHealthy[follower.ID] {
+				continue
+			}
+
+			offsets, err := nm.offsets(follower.ID, shardID)
+			if err != nil {
+				nm.log.Warn("Unable to get follower offset", zap.Int("shardID", int(shardID)), zap.String("follower", string(follower.ID)), zap.Error(err))
+				continue
+			}
+
+			d.Offsets[follower.ID] = offsets.Applied
+			if offsets.HighWaterMark > d.HighWaterMark {
+				d.HighWaterMark = offsets.HighWaterMark
+			}
+			// The first follower in the shard map wins a tie
+			if best == "" || offsets.Applied > d.Offsets[best] {
+				best = follower.ID
+			}
+			if !nm.nodeConfig.IsDrained(follower.ID) && (active == "" || offsets.Applied > d.Offsets[active]) {
+				active = follower.ID
+			}
+		}
+
+		// A drained follower is promoted only if no other follower is caught up
+		if active != "" && d.HighWaterMark-d.Offsets[active] <= maxLag {
+			best = active
+		}
+
+		switch {
+		case best == "":
+			d.Reason = "no healthy follower responded"
+
+		case d.HighWaterMark-d.Offsets[best] > maxLag:
+			d.Reason = fmt.Sprintf("most up-to-date follower %s is %d entries behind the high water mark, allowed %d",
+				best, d.HighWaterMark-d.Offsets[best], maxLag)
+
+		default:
+			d.Promoted = best
+			leaders[shardID] = best
+			d.Reason = fmt.Sprintf("most up-to-date follower, %d entries behind the high water mark", d.HighWaterMark-d.Offsets[best])
+		}
+
+		decisions = append(decisions, d)
+	}
+
+	return leaders, decisions
+}
+
+// recordFailovers commits the failover decisions that were not recorded yet to the raft log.
+// A refused failover is recorded again only when the offsets of the followers change.
+func (nm *NodeManager) recordFailovers(decisions []fsm.FailoverDecision) error {
+	pending := []fsm.FailoverDecision{}
+	for _, d := range decisions {
+		last, ok := nm.refusedFailovers[d.ShardID]
+		if d.Refused() && ok && last.FailedLeader == d.FailedLeader &&
+			last.HighWaterMark == d.HighWaterMark && reflect.DeepEqual(last.Offsets, d.Offsets) {
+			continue
+		}
+		pending = append(pending, d)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	by, err := consensus.ConvertFailoverDecisions(pending)
+	if err != nil {
+		return err
+	}
+	if err = nm.cp.Apply(by); err != nil {
+		return err
+	}
+
+	for _, d := range pending {
+		if !d.Refused() {
+			delete(nm.refusedFailovers, d.ShardID)
+			continue
+		}
+
+		nm.refusedFailovers[d.ShardID] = d
+		metrics.FailoversRefused.Inc()
+		nm.log.Error("Failover refused",
+			zap.Int("shardID", int(d.ShardID)),
+			zap.String("failedLeader", string(d.FailedLeader)),
+			zap.Any("offsets", d.Offsets),
+			zap.Int64("highWaterMark", d.HighWaterMark),
+			zap.String("reason", d.Reason),
+		)
+	}
+
+	return nil
+}
+
+// fetchShardOffsets returns the latest wal offset and the high water mark of the shard on the node
+func (nm *NodeManager) fetchShardOffsets(nodeID dht.NodeID, shardID dht.ShardID) (js.ShardOffsets, error) {
+	if nodeID == nm.selfNodeID {
+		shard, err := nm.GetLocalShard(shardID)
+		if err != nil {
+			return js.ShardOffsets{}, err
+		}
+		if shard == nil {
+			return js.ShardOffsets{}, ErrNotSlotOwner
+		}
+
+		return js.ShardOffsets{Applied: shard.GetLatestOffset(), HighWaterMark: shard.GetHighWaterMark()}, nil
+	}
+
+	conn, err := nm.GetRemoteConnection(nodeID)
+	if err != nil {
+		return js.ShardOffsets{}, err
+	}
+
+	return conn.GetShardOffsets(shardID)
+}
@@ -0,0 +1,171 @@
+package nodemanager
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+func TestChooseLeaders(t *testing.T) {
+	type offset struct {
+		node                   dht.NodeID
+		applied, highWaterMark int64
+	}
+
+	tests := []struct {
+		name         string
+		followers    []dht.NodeID
+		healthy      []dht.NodeID
+		drained      []dht.NodeID
+		offsets      []offset
+		maxLag       int64
+		wantPromoted dht.NodeID
+		wantOffsets  map[dht.NodeID]int64
+	}{
+		{
+			name:         "most up-to-date follower",
+			followers:    []dht.NodeID{"node2", "node3"},
+			healthy:      []dht.NodeID{"node2", "node3"},
+			offsets:      []offset{{"node2", 8, 10}, {"node3", 10, 10}},
+			wantPromoted: "node3",
+			wantOffsets:  map[dht.NodeID]int64{"node2": 8, "node3": 10},
+		},
+		{
+			name:        "follower beyond max lag",
+			followers:   []dht.NodeID{"node2", "node3"},
+			healthy:     []dht.NodeID{"node2", "node3"},
+			offsets:     []offset{{"node2", 8, 12}, {"node3", 9, 9}},
+			maxLag:      2,
+			wantOffsets: map[dht.NodeID]int64{"node2": 8, "node3": 9},
+		},
+		{
+			name:         "follower within max lag",
+			followers:    []dht.NodeID{"node2", "node3"},
+			healthy:      []dht.NodeID{"node2", "node3"},
+			offsets:      []offset{{"node2", 8, 12}, {"node3", 10, 10}},
+			maxLag:       2,
+			wantPromoted: "node3",
+			wantOffsets:  map[dht.NodeID]int64{"node2": 8, "node3": 10},
+		},
+		{
+			name:         "tie goes to the first follower",
+			followers:    []dht.NodeID{"node3", "node2"},
+			healthy:      []dht.NodeID{"node2", "node3"},
+			offsets:      []offset{{"node2", 10, 10}, {"node3", 10, 10}},
+			wantPromoted: "node3",
+			wantOffsets:  map[dht.NodeID]int64{"node2": 10, "node3": 10},
+		},
+		{
+			name:         "drained follower skipped",
+			followers:    []dht.NodeID{"node2", "node3"},
+			healthy:      []dht.NodeID{"node2", "node3"},
+			drained:      []dht.NodeID{"node3"},
+			offsets:      []offset{{"node2", 10, 10}, {"node3", 10, 10}},
+			wantPromoted: "node2",
+			wantOffsets:  map[dht.NodeID]int64{"node2": 10, "node3": 10},
+		},
+		{
+			name:         "drained follower promoted if the others lag",
+			followers:    []dht.NodeID{"node2", "node3"},
+			healthy:      []dht.NodeID{"node2", "node3"},
+			drained:      []dht.NodeID{"node3"},
+			offsets:      []offset{{"node2", 8, 10}, {"node3", 10, 10}},
+			wantPromoted: "node3",
+			wantOffsets:  map[dht.NodeID]int64{"node2": 8, "node3": 10},
+		},
+		{
+			name:         "unhealthy follower ignored",
+			followers:    []dht.NodeID{"node2", "node3"},
+			healthy:      []dht.NodeID{"node2"},
+			offsets:      []offset{{"node2", 8, 8}, {"node3", 10, 10}},
+			wantPromoted: "node2",
+			wantOffsets:  map[dht.NodeID]int64{"node2": 8},
+		},
+		{
+			name:        "no follower responded",
+			followers:   []dht.NodeID{"node2", "node3"},
+			healthy:     []dht.NodeID{"node2", "node3"},
+			wantOffsets: map[dht.NodeID]int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shards := map[dht.ShardID]dht.ShardLocation{
+				0: createShard(0, "node1", tt.followers...),
+				1: createShard(1, "node2", "node1"),
+			}
+			nm, _, offsets := createTestNodeManager(shards, "node1", "node2", "node3")
+			for _, node := range tt.drained {
+				if err := nm.setDrained(node, true); err != nil {
+					t.Fatalf("setDrained() error = %v", err)
+				}
+			}
+			for _, o := range tt.offsets {
+				offsets.set(o.node, 0, o.applied, o.highWaterMark)
+			}
+
+			leaders, decisions := nm.chooseLeaders(shards, "node1", tt.healthy, tt.maxLag)
+
+			// Only the shards led by the failed node are failed over
+			if len(decisions) != 1 {
+				t.Fatalf("chooseLeaders() returned %d decisions, want 1", len(decisions))
+			}
+			d := decisions[0]
+			if d.ShardID != 0 || d.FailedLeader != "node1" || d.Promoted != tt.wantPromoted {
+				t.Errorf("chooseLeaders() decision = %+v, want shard 0 of node1 promoting %q", d, tt.wantPromoted)
+			}
+			if !reflect.DeepEqual(d.Offsets, tt.wantOffsets) {
+				t.Errorf("chooseLeaders() offsets = %v, want %v", d.Offsets, tt.wantOffsets)
+			}
+			if d.Reason == "" {
+				t.Errorf("chooseLeaders() decision has no reason")
+			}
+
+			wantLeaders := map[dht.ShardID]dht.NodeID{}
+			if tt.wantPromoted != "" {
+				wantLeaders[0] = tt.wantPromoted
+			}
+			if !reflect.DeepEqual(leaders, wantLeaders) {
+				t.Errorf("chooseLeaders() leaders = %v, want %v", leaders, wantLeaders)
+			}
+		})
+	}
+}
+
+func TestRecordFailovers(t *testing.T) {
+	nm, cp, _ := createTestNodeManager(map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node1", "node2"),
+	}, "node1", "node2")
+
+	promoted := fsm.FailoverDecision{ShardID: 0, FailedLeader: "node1", Promoted: "node2", Offsets: map[dht.NodeID]int64{"node2": 10}, HighWaterMark: 10}
+	refused := fsm.FailoverDecision{ShardID: 1, FailedLeader: "node1", Offsets: map[dht.NodeID]int64{"node2": 8}, HighWaterMark: 10}
+	caughtUp := fsm.FailoverDecision{ShardID: 1, FailedLeader: "node1", Offsets: map[dht.NodeID]int64{"node2": 9}, HighWaterMark: 10}
+
+	steps := []struct {
+		name      string
+		decisions []fsm.FailoverDecision
+		want      []fsm.FailoverDecision
+	}{
+		{name: "new decisions", decisions: []fsm.FailoverDecision{promoted, refused}, want: []fsm.FailoverDecision{promoted, refused}},
+		{name: "same refusal", decisions: []fsm.FailoverDecision{refused}, want: []fsm.FailoverDecision{promoted, refused}},
+		{name: "changed offsets", decisions: []fsm.FailoverDecision{caughtUp}, want: []fsm.FailoverDecision{promoted, refused, caughtUp}},
+	}
+
+	for _, step := range steps {
+		if err := nm.recordFailovers(step.decisions); err != nil {
+			t.Fatalf("%s: recordFailovers() error = %v", step.name, err)
+		}
+
+		// The decisions are read back from the FSM the raft commands were applied to
+		if got := nm.nodeConfig.GetFailovers(); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: failovers = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+
+	if got := cp.getCommands(fsm.RecordFailover); got != 2 {
+		t.Errorf("RecordFailover commands = %d, want 2", got)
+	}
+}
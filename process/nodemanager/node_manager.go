@@ -2,12 +2,16 @@ package nodemanager
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
+	"github.com/aarthikrao/timeMachine/components/datashard"
 	"github.com/aarthikrao/timeMachine/components/dht"
 	"github.com/aarthikrao/timeMachine/components/executor"
 	js "github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
 	"github.com/aarthikrao/timeMachine/process/connectionmanager"
 	dsm "github.com/aarthikrao/timeMachine/process/datastoremanager"
 	"github.com/aarthikrao/timeMachine/utils/address"
@@ -35,8 +39,40 @@ type NodeManager struct {
 	connMgr      *connectionmanager.ConnectionManager
 	dhtMgr       dht.DHT
 	cp           consensus.Consensus
+	nodeConfig   fsm.NodeConfig
 	exe          executor.Executor
-	log          *zap.Logger
+
+	// mu serialises the node initialisation on every change in the shard map
+	mu sync.Mutex
+
+	// Shards led by this node as per the last shard map
+	leaderShards map[dht.ShardID]bool
+
+	// Makes sure that only one poller is started
+	pollerOnce sync.Once
+
+	// Makes sure that only one redistribution runs at a time
+	redistributeMu sync.Mutex
+
+	// failoverMu serialises the replacement of the failed nodes
+	failoverMu sync.Mutex
+
+	// Last refused failover of the shards, so that the same decision is not recorded on every retry
+	refusedFailovers map[dht.ShardID]fsm.FailoverDecision
+
+	// The undelivered jobs due within this duration are queued when this node becomes the leader of a shard
+	catchUpWindow time.Duration
+
+	// The idempotency records of the shards are removed after this duration
+	idempotencyWindow time.Duration
+
+	// Last minute bucket queued by the poller. The buckets missed by the poller are queued on the next poll
+	polledMinute int
+
+	// offsets returns the wal offsets of the shard on the node. It is replaced in the tests
+	offsets func(nodeID dht.NodeID, shardID dht.ShardID) (js.ShardOffsets, error)
+
+	log *zap.Logger
 }
 
 func CreateNodeManager(
@@ -45,40 +81,56 @@ func CreateNodeManager(
 	connMgr *connectionmanager.ConnectionManager,
 	dhtMgr dht.DHT,
 	cp consensus.Consensus,
+	nodeConfig fsm.NodeConfig,
 	exe executor.Executor,
+	catchUpWindow time.Duration,
+	idempotencyWindow time.Duration,
 	log *zap.Logger,
 ) *NodeManager {
-	return &NodeManager{
-		selfNodeID:   dht.NodeID(selfNodeID),
-		dataStoreMgr: dsmgr,
-		dhtMgr:       dhtMgr,
-		connMgr:      connMgr,
-		cp:           cp,
-		exe:          exe,
-		log:          log,
+	nm := &NodeManager{
+		selfNodeID:        dht.NodeID(selfNodeID),
+		dataStoreMgr:      dsmgr,
+		dhtMgr:            dhtMgr,
+		connMgr:           connMgr,
+		cp:                cp,
+		nodeConfig:        nodeConfig,
+		exe:               exe,
+		leaderShards:      make(map[dht.ShardID]bool),
+		refusedFailovers:  make(map[dht.ShardID]fsm.FailoverDecision),
+		catchUpWindow:     catchUpWindow,
+		idempotencyWindow: idempotencyWindow,
+		log:               log,
 	}
+	nm.offsets = nm.fetchShardOffsets
+
+	return nm
 }
 
 // Initialises the app DHT from the server list.
-// It also publishes the slot and node map to other nodes via consensus module
-func (nm *NodeManager) InitAppDHT(shards, replicas int) error {
-	servers, err := nm.cp.GetConfigurations()
+// It also publishes the slot and node map to other nodes via consensus module.
+// The weights of the nodes are used only if the DHT places the shards itself
+func (nm *NodeManager) InitAppDHT(shards, replicas int, weights map[string]int) error {
+	nodes, err := nm.getServerIDs()
 	if err != nil {
 		return err
 	}
-	var nodes []string
-	for _, server := range servers {
-		serverID := string(server.ID)
-		nodes = append(nodes, serverID)
-	}
 
-	sn, err := dht.InitialiseDHT(shards, nodes, replicas)
+	var sn map[dht.ShardID]dht.ShardLocation
+	if placer, ok := nm.dhtMgr.(dht.Placer); ok {
+		shardIDs := make([]dht.ShardID, 0, shards)
+		for i := 0; i < shards; i++ {
+			shardIDs = append(shardIDs, dht.ShardID(i))
+		}
+		sn, err = placer.Place(shardIDs, getNodes(nodes, weights), replicas)
+	} else {
+		sn, err = dht.InitialiseDHT(shards, nodes, replicas)
+	}
 	if err != nil {
 		nm.log.Error("Unable to initialise dht", zap.Error(err))
 		return err
 	}
 
-	by, err := consensus.ConvertConfigSnapshot(sn)
+	by, err := consensus.ConvertConfigSnapshot(sn, nm.dhtMgr.Epoch()+1)
 	if err != nil {
 		return err
 	}
@@ -95,13 +147,21 @@ func (nm *NodeManager) IsInitialised() error {
 	return nil
 }
 
-// InitialiseNode will be called once the dht is initialised.
+// InitialiseNode will be called once the dht is initialised and on every change in the shard map.
 // It will help setting up the connections and initialise the datastores
 func (nm *NodeManager) InitialiseNode() error {
-	shards := nm.dhtMgr.GetAllShardsForNode(nm.selfNodeID)
-	if len(shards) <= 0 {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	if len(nm.dhtMgr.Snapshot()) <= 0 {
 		return dht.ErrDHTNotInitialised
 	}
+	shards := nm.dhtMgr.GetAllShardsForNode(nm.selfNodeID)
+
+	// The jobs of the shards that this node no longer leads are removed from the executor.
+	// This has to be done before the shards that are no longer owned are closed
+	gained, lost := nm.getLeadershipChanges()
+	nm.dequeueJobs(lost)
 
 	if err := nm.dataStoreMgr.InitialiseDataStores(shards); err != nil {
 		return err
@@ -111,19 +171,161 @@ func (nm *NodeManager) InitialiseNode() error {
 		return err
 	}
 
+	// The poller will only queue the jobs from the next minute onwards.
+	// The jobs of the shards led by this node until now are already queued
+	nm.queueJobs(gained)
+	if nm.polledMinute == 0 {
+		nm.polledMinute = timeutil.GetCurrentMinutes() + 1
+	}
+
 	// In a seperate routine keep running a poller to fetch jobs for the next minute and schedule it
-	go func() {
-		for range time.Tick(1 * time.Minute) {
-			if err := nm.executeJobs(); err != nil {
-				nm.log.Error("Unable to execute jobs", zap.Error(err))
+	nm.pollerOnce.Do(func() {
+		go func() {
+			for range time.Tick(1 * time.Minute) {
+				if err := nm.executeJobs(); err != nil {
+					nm.log.Error("Unable to execute jobs", zap.Error(err))
+				}
+				nm.removeIdempotencyRecords()
 			}
-		}
-	}()
+		}()
+	})
 
-	nm.log.Info("Initialsed node")
+	nm.log.Info("Initialsed node", zap.Int("shards", len(shards)), zap.Int("leaderShards", len(nm.leaderShards)))
 	return nil
 }
 
+// getLeadershipChanges returns the shards whose leadership this node has gained and lost since the last shard map
+func (nm *NodeManager) getLeadershipChanges() (gained, lost []dht.ShardID) {
+	leaderShards := make(map[dht.ShardID]bool)
+	for _, shardID := range nm.dhtMgr.GetLeaderShardsForNode(nm.selfNodeID) {
+		leaderShards[shardID] = true
+		if !nm.leaderShards[shardID] {
+			gained = append(gained, shardID)
+		}
+	}
+
+	for shardID := range nm.leaderShards {
+		if !leaderShards[shardID] {
+			lost = append(lost, shardID)
+		}
+	}
+
+	nm.leaderShards = leaderShards
+	return gained, lost
+}
+
+// queueJobs adds the pending jobs of the shards from the catch up window till the next minute to the executor.
+// This covers the jobs that the previous leader of the shard did not deliver, and the jobs missed while the
+// replicas of the shard were down.
+func (nm *NodeManager) queueJobs(shardIDs []dht.ShardID) {
+	currentMinute := timeutil.GetCurrentMinutes()
+	from := currentMinute - int(nm.catchUpWindow/time.Minute)
+
+	for _, shardID := range shardIDs {
+		jobs, err := nm.getJobs(shardID, from, currentMinute+1)
+		if err != nil {
+			nm.log.Error("Unable to fetch jobs", zap.Int("shardID", int(shardID)), zap.Error(err))
+			continue
+		}
+
+		for _, j := range jobs {
+			nm.queue(j)
+		}
+	}
+}
+
+// queue adds the pending job to the executor. The jobs whose trigger time has passed are dispatched right away,
+// and the misfire policy is applied to them before they are published.
+func (nm *NodeManager) queue(j *jm.Job) {
+	if !j.State.IsPending() {
+		return
+	}
+
+	err := nm.exe.Queue(*j)
+	if err == executor.ErrTooLate {
+		nm.log.Info("Queueing overdue job", zap.String("jobID", j.ID), zap.Int("triggerMS", j.TriggerMS), zap.String("state", string(j.State)))
+		err = nm.exe.QueueOverdue(*j)
+	}
+
+	if err != nil && err != executor.ErrNotWithinExecutorGracePeriod {
+		nm.log.Error("Unable to queue job", zap.String("jobID", j.ID), zap.Error(err))
+	}
+}
+
+// dequeueJobs removes the jobs of the shards in the current and the next minute from the executor
+func (nm *NodeManager) dequeueJobs(shardIDs []dht.ShardID) {
+	currentMinute := timeutil.GetCurrentMinutes()
+	for _, shardID := range shardIDs {
+		jobs, err := nm.getJobs(shardID, currentMinute, currentMinute+1)
+		if err != nil {
+			nm.log.Error("Unable to fetch jobs", zap.Int("shardID", int(shardID)), zap.Error(err))
+			continue
+		}
+
+		for _, j := range jobs {
+			if err := nm.exe.Delete(j.ID); err != nil && err != executor.ErrJobNotFound {
+				nm.log.Error("Unable to remove job from executor", zap.String("jobID", j.ID), zap.Error(err))
+			}
+		}
+	}
+}
+
+// getJobs returns the jobs of the shard in the minute buckets between from and to, both inclusive
+func (nm *NodeManager) getJobs(shardID dht.ShardID, from, to int) ([]*jm.Job, error) {
+	shard, err := nm.dataStoreMgr.GetDataNode(shardID)
+	if err != nil {
+		return nil, err
+	}
+	if shard == nil {
+		return nil, nil
+	}
+
+	return shard.FetchJobsForBuckets(from, to)
+}
+
+// getServerIDs returns the IDs of the servers in the raft cluster that can be assigned shards.
+// The drained servers are excluded
+func (nm *NodeManager) getServerIDs() ([]string, error) {
+	servers, err := nm.getAllServerIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []string
+	for _, server := range servers {
+		if !nm.nodeConfig.IsDrained(dht.NodeID(server)) {
+			nodes = append(nodes, server)
+		}
+	}
+
+	return nodes, nil
+}
+
+// getAllServerIDs returns the IDs of all the servers in the raft cluster
+func (nm *NodeManager) getAllServerIDs() ([]string, error) {
+	servers, err := nm.cp.GetConfigurations()
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []string
+	for _, server := range servers {
+		nodes = append(nodes, string(server.ID))
+	}
+
+	return nodes, nil
+}
+
+// getNodes returns the nodes with their weights
+func getNodes(nodeIDs []string, weights map[string]int) []dht.Node {
+	nodes := make([]dht.Node, 0, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		nodes = append(nodes, dht.Node{ID: dht.NodeID(nodeID), Weight: weights[nodeID]})
+	}
+
+	return nodes
+}
+
 func (nm *NodeManager) createConnections() error {
 	servers, err := nm.cp.GetConfigurations()
 	if err != nil {
@@ -150,7 +352,27 @@ func (nm *NodeManager) createConnections() error {
 	return nil
 }
 
-func (nm *NodeManager) GetLocalShard(shardID dht.ShardID) (js.JobFetcher, error) {
+// removeIdempotencyRecords removes the expired idempotency records from all the shards on this node
+func (nm *NodeManager) removeIdempotencyRecords() {
+	beforeMS := timeutil.GetCurrentMillis() - int(nm.idempotencyWindow.Milliseconds())
+	for _, shardID := range nm.dhtMgr.GetAllShardsForNode(nm.selfNodeID) {
+		shard, err := nm.dataStoreMgr.GetDataNode(shardID)
+		if err != nil || shard == nil {
+			continue
+		}
+
+		removed, err := shard.RemoveIdempotencyRecords(beforeMS)
+		if err != nil {
+			nm.log.Error("Unable to remove idempotency records", zap.Int("shardID", int(shardID)), zap.Error(err))
+			continue
+		}
+		if removed > 0 {
+			nm.log.Debug("Removed idempotency records", zap.Int("shardID", int(shardID)), zap.Int("removed", removed))
+		}
+	}
+}
+
+func (nm *NodeManager) GetLocalShard(shardID dht.ShardID) (*datashard.DataShard, error) {
 	if nm.dataStoreMgr == nil {
 		return nil, ErrNotYetInitalised
 	}
@@ -166,27 +388,39 @@ func (nm *NodeManager) GetRemoteConnection(nodeID dht.NodeID) (js.JobStoreWithRe
 	return nm.connMgr.GetJobStore(nodeID)
 }
 
-// Fetches the jobs for the next minute and schedules it to the executor
+// Fetches the jobs for the next minute and schedules it to the executor.
+// The minute buckets after the last poll are also fetched, so that the buckets are not missed if the poller is delayed
 func (nm *NodeManager) executeJobs() error {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	nextMinute := timeutil.GetCurrentMinutes() + 1
+	from := nextMinute
+	if nm.polledMinute > 0 && nm.polledMinute < nextMinute {
+		from = nm.polledMinute + 1
+	}
+
 	for _, shardID := range nm.dhtMgr.GetLeaderShardsForNode(nm.selfNodeID) {
 		js, err := nm.dataStoreMgr.GetDataNode(shardID)
 		if err != nil {
 			return err
 		}
+		if js == nil {
+			// The shard map has changed and the shard is yet to be opened
+			continue
+		}
 
-		nextMinute := timeutil.GetCurrentMinutes() + 1
-
-		jobs, err := js.FetchJobForBucket(nextMinute)
+		jobs, err := js.FetchJobsForBuckets(from, nextMinute)
 		if err != nil {
 			return err
 		}
 
 		for _, j := range jobs {
 			nm.log.Debug("Fetched job", zap.Any("job", j), zap.Int("nextMinute in sec", nextMinute*60000))
-			nm.exe.Queue(*j)
+			nm.queue(j)
 		}
-
 	}
 
+	nm.polledMinute = nextMinute
 	return nil
 }
@@ -0,0 +1,141 @@
+package nodemanager
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/consensus/fsm"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	js "github.com/aarthikrao/timeMachine/components/jobstore"
+	"github.com/hashicorp/raft"
+	"go.uber.org/zap"
+)
+
+// Returned by testOffsets for the nodes that did not report the offsets of a shard
+var errNoOffsets = errors.New("no offsets")
+
+// testConsensus applies the raft commands to the config FSM right away, and records them
+type testConsensus struct {
+	fsm     *fsm.ConfigFSM
+	servers []raft.Server
+	leader  atomic.Bool
+
+	mu       sync.Mutex
+	commands []fsm.Command
+}
+
+func (c *testConsensus) Join(nodeID, raftAddress string) error { return nil }
+func (c *testConsensus) Remove(nodeID string) error            { return nil }
+func (c *testConsensus) Stats() map[string]string              { return nil }
+func (c *testConsensus) IsLeader() bool                        { return c.leader.Load() }
+func (c *testConsensus) GetLeaderAddress() string              { return "" }
+
+func (c *testConsensus) GetConfigurations() ([]raft.Server, error) {
+	return c.servers, nil
+}
+
+func (c *testConsensus) Apply(cmd []byte) error {
+	var command fsm.Command
+	if err := json.Unmarshal(cmd, &command); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.commands = append(c.commands, command)
+	c.mu.Unlock()
+
+	if err, ok := c.fsm.Apply(&raft.Log{Type: raft.LogCommand, Data: cmd}).(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// getCommands returns the number of commands applied with the operation
+func (c *testConsensus) getCommands(op fsm.OperationType) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for _, command := range c.commands {
+		if command.Operation == op {
+			count++
+		}
+	}
+
+	return count
+}
+
+// testOffsets is the wal offsets of the shards reported by the nodes
+type testOffsets struct {
+	mu      sync.Mutex
+	offsets map[dht.NodeID]map[dht.ShardID]js.ShardOffsets
+}
+
+func (o *testOffsets) set(nodeID dht.NodeID, shardID dht.ShardID, applied, highWaterMark int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.offsets[nodeID] == nil {
+		o.offsets[nodeID] = make(map[dht.ShardID]js.ShardOffsets)
+	}
+	o.offsets[nodeID][shardID] = js.ShardOffsets{Applied: applied, HighWaterMark: highWaterMark}
+}
+
+func (o *testOffsets) get(nodeID dht.NodeID, shardID dht.ShardID) (js.ShardOffsets, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	offsets, ok := o.offsets[nodeID][shardID]
+	if !ok {
+		return js.ShardOffsets{}, errNoOffsets
+	}
+
+	return offsets, nil
+}
+
+// createTestNodeManager returns the node manager of the raft leader with the shard map applied. The raft leader
+// does not own any shard, and the offsets of the shards on the nodes are reported by testOffsets
+func createTestNodeManager(shards map[dht.ShardID]dht.ShardLocation, nodes ...dht.NodeID) (*NodeManager, *testConsensus, *testOffsets) {
+	appDht := dht.Create()
+	appDht.Load(shards, 1)
+
+	cp := &testConsensus{fsm: fsm.NewConfigFSM(appDht, nil, nil, zap.NewNop())}
+	cp.leader.Store(true)
+	for _, node := range append([]dht.NodeID{"raftLeader"}, nodes...) {
+		cp.servers = append(cp.servers, raft.Server{ID: raft.ServerID(node)})
+	}
+
+	offsets := &testOffsets{offsets: make(map[dht.NodeID]map[dht.ShardID]js.ShardOffsets)}
+	nm := CreateNodeManager("raftLeader", nil, nil, appDht, cp, cp.fsm, nil, 0, 0, zap.NewNop())
+	nm.offsets = offsets.get
+
+	return nm, cp, offsets
+}
+
+// createShard returns the shard led by the leader
+func createShard(shardID dht.ShardID, leader dht.NodeID, followers ...dht.NodeID) dht.ShardLocation {
+	shard := dht.ShardLocation{ID: shardID, Leader: dht.NodeDetails{ID: leader}}
+	for _, follower := range followers {
+		shard.Followers = append(shard.Followers, dht.NodeDetails{ID: follower})
+	}
+
+	return shard
+}
+
+// waitFor waits till the condition is true, and fails the test after the timeout
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,172 @@
+package nodemanager
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"go.uber.org/zap"
+)
+
+var (
+	// Another redistribution is running on this node
+	ErrRedistributionInProgress = errors.New("redistribution already in progress")
+
+	// The new owners of the shards did not catch up with the leader shards within the timeout
+	ErrCatchUpTimeout = errors.New("timed out waiting for the new owners to catch up")
+)
+
+// Interval at which the offsets of the new owners are checked during redistribution
+const catchUpCheckInterval = time.Second
+
+// Redistribute moves the shards across the nodes in the raft cluster after nodes are added or removed.
+// It must be called only on the raft leader and follows the migration flow in docs/ShardMigration.md
+//  1. The new owners of a shard are added as its joining nodes. They catch up with the leader shard by
+//     streaming its wal, while the leader keeps replicating the new writes to them. The joining nodes
+//     do not count towards the write concern till then.
+//  2. Once all the new owners have caught up, the new shard map is committed and they become
+//     followers or leaders. The nodes that no longer own a shard close it.
+//
+// The new shard map is computed by the DHT if it places the shards itself, with the weights of the nodes.
+// It returns the new shard map.
+func (nm *NodeManager) Redistribute(timeout time.Duration, weights map[string]int) (map[dht.ShardID]dht.ShardLocation, error) {
+	if !nm.redistributeMu.TryLock() {
+		return nil, ErrRedistributionInProgress
+	}
+	defer nm.redistributeMu.Unlock()
+
+	// The epoch is read first, so that a change applied in between is fenced
+	epoch := nm.dhtMgr.Epoch()
+	current := nm.dhtMgr.Snapshot()
+	if len(current) <= 0 {
+		return nil, dht.ErrDHTNotInitialised
+	}
+
+	nodes, err := nm.getServerIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var redistributed map[dht.ShardID]dht.ShardLocation
+	if placer, ok := nm.dhtMgr.(dht.Placer); ok {
+		shardIDs := make([]dht.ShardID, 0, len(current))
+		for shardID := range current {
+			shardIDs = append(shardIDs, shardID)
+		}
+		redistributed, err = placer.Place(shardIDs, getNodes(nodes, weights), getReplicaCount(current))
+	} else {
+		redistributed, err = dht.Redistribute(current, nodes, getReplicaCount(current))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reflect.DeepEqual(current, redistributed) {
+		nm.log.Info("Shards are already distributed")
+		return redistributed, nil
+	}
+
+	added := dht.GetAddedOwners(current, redistributed)
+	if len(added) > 0 {
+		// Phase 1: Add the new owners as joining nodes of the current leaders
+		interim := addJoining(current, added)
+		epoch++
+		if err := nm.applyShards(interim, epoch); err != nil {
+			return nil, err
+		}
+		nm.log.Info("Added the new owners as joining nodes", zap.Any("added", added))
+
+		if err := nm.waitForCatchUp(interim, added, timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	// Phase 2: Commit the new shard map
+	if err := nm.applyShards(redistributed, epoch+1); err != nil {
+		return nil, err
+	}
+	nm.log.Info("Redistributed shards", zap.Any("shards", redistributed))
+
+	return redistributed, nil
+}
+
+// waitForCatchUp waits till the wal offsets of the new owners match the leader shards
+func (nm *NodeManager) waitForCatchUp(shards map[dht.ShardID]dht.ShardLocation, added map[dht.ShardID][]dht.NodeID, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		pending := 0
+		for shardID, newOwners := range added {
+			leaderOffset, err := nm.getShardOffset(shards[shardID].Leader.ID, shardID)
+			if err != nil {
+				nm.log.Warn("Unable to get leader offset", zap.Int("shardID", int(shardID)), zap.Error(err))
+				pending += len(newOwners)
+				continue
+			}
+
+			for _, node := range newOwners {
+				offset, err := nm.getShardOffset(node, shardID)
+				if err != nil || offset < leaderOffset {
+					pending++
+				}
+			}
+		}
+
+		if pending == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			nm.log.Error("New owners have not caught up", zap.Int("pending", pending))
+			return ErrCatchUpTimeout
+		}
+
+		time.Sleep(catchUpCheckInterval)
+	}
+}
+
+// getShardOffset returns the latest wal offset of the shard on the node
+func (nm *NodeManager) getShardOffset(nodeID dht.NodeID, shardID dht.ShardID) (int64, error) {
+	offsets, err := nm.offsets(nodeID, shardID)
+	return offsets.Applied, err
+}
+
+// applyShards commits the shard map at the epoch. It fails with fsm.ErrStaleEpoch if the shard map
+// was changed by another node in the meantime
+func (nm *NodeManager) applyShards(shards map[dht.ShardID]dht.ShardLocation, epoch uint64) error {
+	by, err := consensus.ConvertConfigSnapshot(shards, epoch)
+	if err != nil {
+		return err
+	}
+
+	return nm.cp.Apply(by)
+}
+
+// addJoining returns a copy of the shard map with the nodes added as joining nodes
+func addJoining(shards map[dht.ShardID]dht.ShardLocation, added map[dht.ShardID][]dht.NodeID) map[dht.ShardID]dht.ShardLocation {
+	m := make(map[dht.ShardID]dht.ShardLocation, len(shards))
+	for shardID, shard := range shards {
+		joining := append([]dht.NodeDetails{}, shard.Joining...)
+		for _, node := range added[shardID] {
+			if !shard.IsJoining(node) {
+				joining = append(joining, dht.NodeDetails{ID: node})
+			}
+		}
+
+		shard.Joining = joining
+		m[shardID] = shard
+	}
+
+	return m
+}
+
+// getReplicaCount returns the number of replicas of every shard including the leader
+func getReplicaCount(shards map[dht.ShardID]dht.ShardLocation) int {
+	for _, shard := range shards {
+		return len(shard.Followers) + 1
+	}
+
+	return 0
+}
@@ -0,0 +1,32 @@
+package nodemanager
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+func TestAddJoining(t *testing.T) {
+	shards := map[dht.ShardID]dht.ShardLocation{
+		0: {ID: 0, Leader: dht.NodeDetails{ID: "node1"}, Followers: []dht.NodeDetails{{ID: "node2"}}},
+		1: {ID: 1, Leader: dht.NodeDetails{ID: "node2"}, Followers: []dht.NodeDetails{{ID: "node1"}}, Joining: []dht.NodeDetails{{ID: "node3"}}},
+	}
+
+	got := addJoining(shards, map[dht.ShardID][]dht.NodeID{
+		0: {"node3"},
+		1: {"node3"},
+	})
+
+	// The new owners must not be followers, as the followers count towards the write concern
+	want := map[dht.ShardID]dht.ShardLocation{
+		0: {ID: 0, Leader: dht.NodeDetails{ID: "node1"}, Followers: []dht.NodeDetails{{ID: "node2"}}, Joining: []dht.NodeDetails{{ID: "node3"}}},
+		1: {ID: 1, Leader: dht.NodeDetails{ID: "node2"}, Followers: []dht.NodeDetails{{ID: "node1"}}, Joining: []dht.NodeDetails{{ID: "node3"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("addJoining() = %v, want %v", got, want)
+	}
+	if len(shards[0].Joining) != 0 {
+		t.Errorf("addJoining() changed the shard map passed to it")
+	}
+}
@@ -0,0 +1,204 @@
+package nodemanager
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/consensus"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"go.uber.org/zap"
+)
+
+// ReplaceFailedNode removes the failed node from the shard map and restores the replicas of its shards.
+// It must be called only on the raft leader and follows the re-replication flow in docs/ShardMigration.md
+//  1. The failed node is removed from the shards, and the shards it led are led by their most up-to-date
+//     healthy follower, see chooseLeaders. The failover decisions are recorded in the raft log.
+//  2. A healthy spare node is added to every shard that lost a replica as a joining node, and the shard map
+//     is committed. The spares copy the shards from their leaders through a shard transfer.
+//  3. Once a spare has caught up with the leader shard, it is made a follower of the shard in a new shard map.
+//     The spares are checked in the background till the timeout.
+//
+// The shards the failed node owned are recorded in the raft log, so that it rejoins them when it comes back, see RejoinNode.
+//
+// It returns the shards that have no healthy replica, or whose failover was refused as no follower is within maxLag
+// entries of the failed leader. They are left as is and are unavailable till a replica comes back or catches up.
+func (nm *NodeManager) ReplaceFailedNode(failed dht.NodeID, healthy []dht.NodeID, maxLag int64, timeout time.Duration) ([]dht.ShardID, error) {
+	nm.failoverMu.Lock()
+	defer nm.failoverMu.Unlock()
+
+	// The epoch is read first, so that a change applied in between is fenced
+	epoch := nm.dhtMgr.Epoch()
+	current := nm.dhtMgr.Snapshot()
+	if len(current) <= 0 {
+		return nil, dht.ErrDHTNotInitialised
+	}
+
+	leaders, decisions := nm.chooseLeaders(current, failed, healthy, maxLag)
+	if err := nm.recordFailovers(decisions); err != nil {
+		return nil, err
+	}
+
+	replaced, added, unavailable := dht.ReplaceNode(current, failed, healthy, leaders, nm.getDrainedNodes(healthy))
+	for _, shardID := range unavailable {
+		nm.log.Error("Shard is unavailable", zap.Int("shardID", int(shardID)), zap.String("failedNode", string(failed)))
+	}
+
+	if reflect.DeepEqual(current, replaced) {
+		return unavailable, nil
+	}
+
+	if err := nm.applyShards(replaced, epoch+1); err != nil {
+		return unavailable, err
+	}
+	nm.log.Info("Replaced failed node", zap.String("failedNode", string(failed)), zap.Any("spares", added))
+
+	if len(added) > 0 {
+		go nm.promoteJoining(added, nil, timeout)
+	}
+
+	return unavailable, nm.recordReplacement(failed, current, replaced, added)
+}
+
+// recordReplacement commits the shards the failed node was removed from to the raft log, along with the spares added
+func (nm *NodeManager) recordReplacement(failed dht.NodeID, current, replaced map[dht.ShardID]dht.ShardLocation, added map[dht.ShardID]dht.NodeID) error {
+	shards := make(map[dht.ShardID]dht.NodeID)
+	for shardID, shard := range current {
+		if ownsShard(shard, failed) && !ownsShard(replaced[shardID], failed) {
+			shards[shardID] = added[shardID]
+		}
+	}
+	if len(shards) == 0 {
+		return nil
+	}
+
+	by, err := consensus.ConvertReplacement(failed, shards)
+	if err != nil {
+		return err
+	}
+
+	return nm.cp.Apply(by)
+}
+
+// RejoinNode adds a node that came back after it was replaced to the shards it owned as a joining node.
+// Once the node has caught up with a shard, it becomes a follower in place of the spare that replaced it,
+// and the leader balancer hands the leadership of the shards back to it. It must be called only on the
+// raft leader, and does nothing if the node was not replaced.
+func (nm *NodeManager) RejoinNode(nodeID dht.NodeID, timeout time.Duration) error {
+	nm.failoverMu.Lock()
+	defer nm.failoverMu.Unlock()
+
+	spares := nm.nodeConfig.GetReplacedShards(nodeID)
+	if len(spares) == 0 {
+		return nil
+	}
+
+	shardIDs := make([]dht.ShardID, 0, len(spares))
+	for shardID := range spares {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+
+	// The epoch is read first, so that a change applied in between is fenced
+	epoch := nm.dhtMgr.Epoch()
+	rejoined, added := dht.RejoinNode(nm.dhtMgr.Snapshot(), nodeID, shardIDs)
+	if len(added) > 0 {
+		if err := nm.applyShards(rejoined, epoch+1); err != nil {
+			return err
+		}
+		nm.log.Info("Node rejoined its shards", zap.String("node", string(nodeID)), zap.Any("shards", added))
+	}
+
+	// The shards of the node are in the shard map from now on
+	by, err := consensus.ConvertReplacement(nodeID, nil)
+	if err != nil {
+		return err
+	}
+	if err = nm.cp.Apply(by); err != nil {
+		return err
+	}
+
+	joining := make(map[dht.ShardID]dht.NodeID, len(added))
+	replacedBy := make(map[dht.ShardID]dht.NodeID)
+	for _, shardID := range added {
+		joining[shardID] = nodeID
+		if spare := spares[shardID]; spare != "" {
+			replacedBy[shardID] = spare
+		}
+	}
+	if len(joining) > 0 {
+		go nm.promoteJoining(joining, replacedBy, timeout)
+	}
+
+	return nil
+}
+
+// promoteJoining makes the joining nodes followers of the shards as they catch up with the leader shards.
+// The spares in replaces are removed from the shards once the joining node they replaced has caught up.
+func (nm *NodeManager) promoteJoining(joining, replaces map[dht.ShardID]dht.NodeID, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+
+	for len(joining) > 0 {
+		if time.Now().After(deadline) {
+			nm.log.Error("Joining nodes have not caught up", zap.Any("pending", joining))
+			return
+		}
+		time.Sleep(catchUpCheckInterval)
+
+		epoch := nm.dhtMgr.Epoch()
+		current := nm.dhtMgr.Snapshot()
+
+		caughtUp := make(map[dht.ShardID]dht.NodeID)
+		for shardID, node := range joining {
+			shard, ok := current[shardID]
+			if !ok || !shard.IsJoining(node) {
+				// The shard map was changed by another node
+				delete(joining, shardID)
+				continue
+			}
+
+			leaderOffset, err := nm.getShardOffset(shard.Leader.ID, shardID)
+			if err != nil {
+				nm.log.Warn("Unable to get leader offset", zap.Int("shardID", int(shardID)), zap.Error(err))
+				continue
+			}
+
+			offset, err := nm.getShardOffset(node, shardID)
+			if err == nil && offset >= leaderOffset {
+				caughtUp[shardID] = node
+			}
+		}
+		if len(caughtUp) == 0 {
+			continue
+		}
+
+		spares := make(map[dht.ShardID]dht.NodeID)
+		for shardID := range caughtUp {
+			if spare, ok := replaces[shardID]; ok {
+				spares[shardID] = spare
+			}
+		}
+
+		promoted := dht.RemoveSpares(dht.PromoteJoining(current, caughtUp), spares)
+		if err := nm.applyShards(promoted, epoch+1); err != nil {
+			nm.log.Error("Unable to promote joining nodes", zap.Any("nodes", caughtUp), zap.Error(err))
+			continue
+		}
+		nm.log.Info("Joining nodes caught up", zap.Any("nodes", caughtUp), zap.Any("removedSpares", spares))
+
+		for shardID := range caughtUp {
+			delete(joining, shardID)
+		}
+	}
+}
+
+// ownsShard returns true if the node owns the shard or is copying it
+func ownsShard(shard dht.ShardLocation, nodeID dht.NodeID) bool {
+	for _, owner := range shard.GetOwners() {
+		if owner == nodeID {
+			return true
+		}
+	}
+
+	return shard.IsJoining(nodeID)
+}
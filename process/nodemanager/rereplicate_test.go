@@ -0,0 +1,61 @@
+package nodemanager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/dht"
+)
+
+func TestReplaceFailedNode(t *testing.T) {
+	nm, _, offsets := createTestNodeManager(map[dht.ShardID]dht.ShardLocation{
+		0: createShard(0, "node1", "node2"),
+		1: createShard(1, "node2", "node1"),
+		2: createShard(2, "node1", "node4"),
+	}, "node1", "node2", "node3", "node4")
+	offsets.set("node2", 0, 10, 10)
+	offsets.set("node2", 1, 10, 10)
+
+	// node4 is down as well, hence shard 2 has no replica that can lead it
+	unavailable, err := nm.ReplaceFailedNode("node1", []dht.NodeID{"node2", "node3"}, 0, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ReplaceFailedNode() error = %v", err)
+	}
+	if want := []dht.ShardID{2}; !reflect.DeepEqual(unavailable, want) {
+		t.Errorf("ReplaceFailedNode() unavailable = %v, want %v", unavailable, want)
+	}
+
+	shards := nm.dhtMgr.Snapshot()
+	want := map[dht.ShardID]dht.ShardLocation{
+		0: {ID: 0, Leader: dht.NodeDetails{ID: "node2"}, Followers: []dht.NodeDetails{}, Joining: []dht.NodeDetails{{ID: "node3"}}},
+		1: {ID: 1, Leader: dht.NodeDetails{ID: "node2"}, Followers: []dht.NodeDetails{}, Joining: []dht.NodeDetails{{ID: "node3"}}},
+		2: createShard(2, "node1", "node4"),
+	}
+	if !reflect.DeepEqual(shards, want) {
+		t.Fatalf("shard map = %v, want %v", shards, want)
+	}
+
+	// The spare is promoted only once it has caught up with the leader shard
+	offsets.set("node2", 0, 12, 12)
+	offsets.set("node3", 0, 11, 11)
+	offsets.set("node3", 1, 10, 10)
+	waitFor(t, 3*time.Second, func() bool {
+		shard := nm.dhtMgr.Snapshot()[1]
+		return len(shard.Joining) == 0
+	})
+
+	shards = nm.dhtMgr.Snapshot()
+	if !shards[0].IsJoining("node3") {
+		t.Errorf("shard 0 = %v, want node3 joining as it is behind the leader", shards[0])
+	}
+	if want := []dht.NodeDetails{{ID: "node3"}}; !reflect.DeepEqual(shards[1].Followers, want) {
+		t.Errorf("shard 1 followers = %v, want %v", shards[1].Followers, want)
+	}
+
+	offsets.set("node3", 0, 12, 12)
+	waitFor(t, 3*time.Second, func() bool {
+		shard := nm.dhtMgr.Snapshot()[0]
+		return len(shard.Joining) == 0 && len(shard.Followers) == 1 && shard.Followers[0].ID == "node3"
+	})
+}
@@ -2,6 +2,7 @@
 package publisher
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"sync"
@@ -11,6 +12,9 @@ import (
 	"github.com/aarthikrao/timeMachine/models/routemodels"
 	"github.com/aarthikrao/timeMachine/utils/httpclient"
 	"github.com/aarthikrao/timeMachine/utils/kafkaclient"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	timeutil "github.com/aarthikrao/timeMachine/utils/time"
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 	"go.uber.org/zap"
 )
 
@@ -26,6 +30,18 @@ type Publihser struct {
 	routeStore  *routestore.RouteStore
 	wg          sync.WaitGroup
 
+	// onDispatch is called before a job is published. The job is not published if it returns an error.
+	// It is used to record that the job is being delivered.
+	onDispatch func(job *jobmodels.Job) error
+
+	// onPublished is called with the response code of the route after a job is successfully published.
+	// It is used to record the delivery and to reschedule the recurring jobs.
+	onPublished func(job *jobmodels.Job, responseCode int) error
+
+	// onFailed is called with the response code of the route when a job could not be published.
+	// It is used to retry the job or move it to the dead letter collection.
+	onFailed func(job *jobmodels.Job, responseCode int, err error) error
+
 	log *zap.Logger
 }
 
@@ -49,10 +65,58 @@ func NewPublisher(
 		go func(wg *sync.WaitGroup) {
 			defer wg.Done()
 			for job := range jobch {
-				if err := pub.Publish(job); err != nil {
+				if pub.onDispatch != nil {
+					if err := pub.onDispatch(job); err != nil {
+						log.Info("skipped publishing job",
+							zap.String("job_id", job.ID),
+							zap.String("collection", job.Collection),
+							zap.Error(err))
+						metrics.Publishes.WithLabelValues(job.Route, metrics.OutcomeSkipped).Inc()
+						continue
+					}
+				}
+
+				// The retries are published after their backoff, hence only the first attempt is counted in the drift
+				if job.Attempts <= 1 {
+					metrics.TriggerDrift.Observe(float64(timeutil.GetCurrentMillis()-job.TriggerMS) / 1000)
+				}
+
+				// The publish starts a new trace that is linked to the write that scheduled the job
+				ctx, span := tracing.StartLinked("publisher.Publish", job.TraceContext,
+					tracing.CollectionKey.String(job.Collection),
+					tracing.JobIDKey.String(job.ID),
+					tracing.RouteKey.String(job.Route),
+				)
+				code, err := pub.Publish(ctx, job)
+				tracing.End(span, err)
+				if err != nil {
+					metrics.Publishes.WithLabelValues(job.Route, metrics.OutcomeFailed).Inc()
 					log.Error("failed to publish job",
 						zap.String("job_id", job.ID),
 						zap.String("route", job.Route),
+						zap.Int("attempts", job.Attempts),
+						zap.Error(err))
+
+					if pub.onFailed == nil {
+						continue
+					}
+					if err := pub.onFailed(job, code, err); err != nil {
+						log.Error("failed to handle failed job",
+							zap.String("job_id", job.ID),
+							zap.String("collection", job.Collection),
+							zap.Error(err))
+					}
+					continue
+				}
+				metrics.Publishes.WithLabelValues(job.Route, metrics.OutcomePublished).Inc()
+
+				if pub.onPublished == nil {
+					continue
+				}
+				if err := pub.onPublished(job, code); err != nil {
+					log.Error("failed to handle published job",
+						zap.String("job_id", job.ID),
+						zap.String("collection", job.Collection),
 						zap.Error(err))
 				}
 			}
@@ -68,20 +132,22 @@ func NewPublisher(
 // depending on the route type.
 // For HTTP routes, it sends a POST request to the webhook URL with the job metadata.
 // For Kafka routes, it publishes the job metadata and ID to the specified Kafka topic on the given host.
-// Returns an error if the publishing fails.
-func (p *Publihser) Publish(j *jobmodels.Job) error {
+// The trace context of ctx is sent in the headers of the HTTP request.
+// Returns the response code of the HTTP endpoint, and an error if the publishing fails.
+// The response code is 0 for Kafka routes, or if the request could not be sent.
+func (p *Publihser) Publish(ctx context.Context, j *jobmodels.Job) (int, error) {
 	// Get the routing information
 	route := p.routeStore.GetRoute(j.Route)
 	if route == nil {
-		return routemodels.ErrInvalidRouteID
+		return 0, routemodels.ErrInvalidRouteID
 	}
 
 	switch route.Type {
 	case routemodels.Http:
 		// Publish the job to the HTTP endpoint
-		by, code, err := p.httpClient.Post(route.WebhookURL, j.Meta)
+		by, code, err := p.httpClient.PostWithContext(ctx, route.WebhookURL, j.Meta)
 		if err != nil {
-			return err
+			return code, err
 		}
 		if code != http.StatusOK {
 			p.log.Error("HTTP response code is not 200",
@@ -89,15 +155,34 @@ func (p *Publihser) Publish(j *jobmodels.Job) error {
 				zap.String("job_id", j.ID),
 				zap.String("msg", string(by)),
 				zap.String("route", route.ID))
-			return ErrReturnedNon200
+			return code, ErrReturnedNon200
 		}
+		return code, nil
 
 	case routemodels.Kafka:
 		// Publish the job to the Kafka topic
-		return p.kafkaClient.Publish(route.Host, route.Topic, []byte(j.ID), j.Meta)
+		return 0, p.kafkaClient.Publish(route.Host, route.Topic, []byte(j.ID), j.Meta)
 	}
 
-	return nil
+	return 0, nil
+}
+
+// SetDispatchHandler sets the function that is called before a job is published.
+// It must be set before any jobs are sent to the publisher.
+func (p *Publihser) SetDispatchHandler(fn func(job *jobmodels.Job) error) {
+	p.onDispatch = fn
+}
+
+// SetPublishedHandler sets the function that is called after a job is successfully published.
+// It must be set before any jobs are sent to the publisher.
+func (p *Publihser) SetPublishedHandler(fn func(job *jobmodels.Job, responseCode int) error) {
+	p.onPublished = fn
+}
+
+// SetFailedHandler sets the function that is called when a job could not be published.
+// It must be set before any jobs are sent to the publisher.
+func (p *Publihser) SetFailedHandler(fn func(job *jobmodels.Job, responseCode int, err error) error) {
+	p.onFailed = fn
 }
 
 // Wait waits for all the publishers to finish.
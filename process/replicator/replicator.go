@@ -0,0 +1,443 @@
+// Replicator keeps the follower shards on this node in sync with their leader shards.
+//
+// The offsets of a shard are the same on all its replicas. The leader replicates every write
+// to the followers along with its wal offset. When a follower finds that it has missed some
+// entries, or when a follower comes back after being down, it streams the missing wal
+// entries from the leader shard and applies them in order.
+package replicator
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/datashard"
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	"github.com/aarthikrao/timeMachine/process/connectionmanager"
+	dsm "github.com/aarthikrao/timeMachine/process/datastoremanager"
+	"github.com/aarthikrao/timeMachine/utils/metrics"
+	"go.uber.org/zap"
+)
+
+var (
+	// The shard is not present on this node
+	ErrShardNotFound = errors.New("shard not found on this node")
+
+	// The shard is receiving a snapshot from the leader. The entry is buffered and applied after the transfer
+	ErrShardMigrating = errors.New("shard is receiving a snapshot from the leader")
+)
+
+// maxBufferedEntries is the maximum number of entries buffered for a shard while it is receiving a snapshot.
+// The entries after it are streamed from the leader once the snapshot is installed.
+const maxBufferedEntries = 10000
+
+// FollowerStatus is the replication status of a follower as seen by the leader shard
+type FollowerStatus struct {
+	NodeID dht.NodeID `json:"node_id"`
+
+	// Latest offset acknowledged by the follower. -1 if the follower has not acknowledged any entry
+	Offset int64 `json:"offset"`
+
+	// Number of entries the follower is behind the leader
+	Lag int64 `json:"lag"`
+
+	LastContact time.Time `json:"last_contact,omitempty"`
+}
+
+// ShardStatus is the replication status of a shard on this node
+type ShardStatus struct {
+	ShardID dht.ShardID `json:"shard_id"`
+	Leader  dht.NodeID  `json:"leader"`
+
+	// Latest wal offset of the shard on this node
+	Offset int64 `json:"offset"`
+
+	// Contains the status of every follower. Only for the leader shards
+	Followers []FollowerStatus `json:"followers,omitempty"`
+
+	// Time of the last successful catch up with the leader. Only for the follower shards
+	LastCatchUp time.Time `json:"last_catch_up,omitempty"`
+
+	// Error in the last catch up with the leader. Only for the follower shards
+	Error string `json:"error,omitempty"`
+
+	// True if the follower shard is receiving a snapshot from the leader
+	Migrating bool `json:"migrating,omitempty"`
+}
+
+// catchUpStatus is the result of the last catch up of a follower shard
+type catchUpStatus struct {
+	lastCatchUp time.Time
+	err         error
+}
+
+type Replicator struct {
+	selfNodeID   dht.NodeID
+	dhtMgr       dht.DHT
+	dataStoreMgr *dsm.DataStoreManager
+
+	mu sync.Mutex
+
+	// shardLocks make sure that only one catch up runs for a follower shard at a time
+	shardLocks map[dht.ShardID]*sync.Mutex
+
+	// Offsets acknowledged by the followers of the leader shards on this node
+	followerOffsets map[dht.ShardID]map[dht.NodeID]FollowerStatus
+
+	// Result of the last catch up of the follower shards on this node
+	catchUps map[dht.ShardID]catchUpStatus
+
+	// Entries replicated by the leader to the shards receiving a snapshot, at most bufferSize entries per shard
+	migrations map[dht.ShardID][]wal.LogEntry
+	bufferSize int
+
+	// getLeader returns the job store of the leader of a shard
+	getLeader func(nodeID dht.NodeID) (jobstore.JobStoreWithReplicator, error)
+
+	pollInterval time.Duration
+	log          *zap.Logger
+}
+
+// CreateReplicator returns the replicator and starts catching up the
+// follower shards on this node every poll interval
+func CreateReplicator(
+	selfNodeID string,
+	dhtMgr dht.DHT,
+	dataStoreMgr *dsm.DataStoreManager,
+	connMgr *connectionmanager.ConnectionManager,
+	pollInterval time.Duration,
+	log *zap.Logger,
+) *Replicator {
+	r := &Replicator{
+		selfNodeID:      dht.NodeID(selfNodeID),
+		dhtMgr:          dhtMgr,
+		dataStoreMgr:    dataStoreMgr,
+		shardLocks:      make(map[dht.ShardID]*sync.Mutex),
+		followerOffsets: make(map[dht.ShardID]map[dht.NodeID]FollowerStatus),
+		catchUps:        make(map[dht.ShardID]catchUpStatus),
+		migrations:      make(map[dht.ShardID][]wal.LogEntry),
+		bufferSize:      maxBufferedEntries,
+		getLeader:       connMgr.GetJobStore,
+		pollInterval:    pollInterval,
+		log:             log,
+	}
+
+	go r.keepFollowersInSync()
+
+	return r
+}
+
+// Replicate applies the entry replicated by the leader to the follower shard.
+// If the follower has missed the entries before this entry, it catches up with the leader first.
+// It returns the latest offset of the follower shard.
+func (r *Replicator) Replicate(shardID dht.ShardID, le wal.LogEntry) (int64, error) {
+	shard, err := r.getShard(shardID)
+	if err != nil {
+		return 0, err
+	}
+	// Recorded before the entry is applied, so that failover knows the entry exists on the leader
+	shard.RecordHighWaterMark(le.Offset)
+
+	if r.bufferIfMigrating(shardID, le) {
+		return shard.GetLatestOffset(), ErrShardMigrating
+	}
+
+	err = shard.Replicate(le)
+	if err == datashard.ErrReplicationGap {
+		// The leader adds the entry to its wal before replicating it,
+		// hence this entry will also be applied during the catch up
+		err = r.CatchUp(shardID)
+	}
+	if err != nil {
+		return shard.GetLatestOffset(), err
+	}
+
+	return shard.GetLatestOffset(), nil
+}
+
+// CatchUp streams the missing wal entries from the leader of the shard and applies them
+func (r *Replicator) CatchUp(shardID dht.ShardID) error {
+	lock := r.getShardLock(shardID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := r.catchUp(shardID)
+
+	r.mu.Lock()
+	status := r.catchUps[shardID]
+	status.err = err
+	if err == nil {
+		status.lastCatchUp = time.Now()
+	}
+	r.catchUps[shardID] = status
+	r.mu.Unlock()
+
+	return err
+}
+
+func (r *Replicator) catchUp(shardID dht.ShardID) error {
+	shard, err := r.getShard(shardID)
+	if err != nil {
+		return err
+	}
+
+	shardLoc, err := r.dhtMgr.GetShardLocation(shardID)
+	if err != nil {
+		return err
+	}
+
+	if shardLoc.Leader.ID == r.selfNodeID {
+		// Leader shards do not catch up
+		return nil
+	}
+
+	leader, err := r.getLeader(shardLoc.Leader.ID)
+	if err != nil {
+		return err
+	}
+
+	needsSnapshot := false
+	if shard.GetLatestOffset() < 0 {
+		// A new owner of the shard copies the snapshot instead of replaying the whole wal
+		leaderOffset, err := leader.GetShardOffset(shardID)
+		if err != nil {
+			return err
+		}
+		needsSnapshot = leaderOffset >= 0
+	}
+
+	if !needsSnapshot {
+		err = r.streamLogEntries(leader, shardLoc.Leader.ID, shardID, shard)
+		if err != datashard.ErrOffsetNotAvailable {
+			return err
+		}
+		// The entries have been removed from the wal of the leader
+	}
+
+	r.startMigration(shardID)
+	defer r.finishMigration(shardID, shard)
+
+	if err = r.transferSnapshot(leader, shardLoc.Leader.ID, shardID, shard); err != nil {
+		return err
+	}
+
+	// Stream the entries added to the leader after the snapshot
+	return r.streamLogEntries(leader, shardLoc.Leader.ID, shardID, shard)
+}
+
+// streamLogEntries applies the wal entries of the leader after the latest offset of the shard
+func (r *Replicator) streamLogEntries(leader jobstore.JobStoreWithReplicator, leaderID dht.NodeID, shardID dht.ShardID, shard *datashard.DataShard) error {
+	startOffset := shard.GetLatestOffset()
+	var applied int
+	err := leader.StreamLogEntries(r.selfNodeID, shardID, startOffset, func(le wal.LogEntry) error {
+		applied++
+		return shard.Replicate(le)
+	})
+	if err != nil {
+		return err
+	}
+
+	if applied > 0 {
+		r.log.Info("Caught up with leader shard",
+			zap.Int("shardID", int(shardID)),
+			zap.String("leader", string(leaderID)),
+			zap.Int64("from", startOffset),
+			zap.Int64("to", shard.GetLatestOffset()),
+		)
+	}
+
+	return nil
+}
+
+// transferSnapshot copies the snapshot of the leader shard and replaces the data of the shard with it.
+// A transfer that was interrupted earlier is resumed from the bytes already received.
+func (r *Replicator) transferSnapshot(leader jobstore.JobStoreWithReplicator, leaderID dht.NodeID, shardID dht.ShardID, shard *datashard.DataShard) error {
+	snapshotOffset, byteOffset := shard.GetTransferState()
+	r.log.Info("Receiving snapshot from leader shard",
+		zap.Int("shardID", int(shardID)),
+		zap.String("leader", string(leaderID)),
+		zap.Int64("resumeFrom", byteOffset),
+	)
+
+	err := leader.StreamShardSnapshot(r.selfNodeID, shardID, snapshotOffset, byteOffset, shard.WriteSnapshotChunk)
+	if err != nil {
+		return err
+	}
+
+	offset, err := shard.InstallTransfer()
+	if err != nil {
+		return err
+	}
+
+	r.log.Info("Installed snapshot from leader shard",
+		zap.Int("shardID", int(shardID)),
+		zap.String("leader", string(leaderID)),
+		zap.Int64("offset", offset),
+	)
+	return nil
+}
+
+// startMigration starts buffering the entries replicated by the leader to the shard
+func (r *Replicator) startMigration(shardID dht.ShardID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.migrations[shardID] = []wal.LogEntry{}
+}
+
+// bufferIfMigrating buffers the entry if the shard is receiving a snapshot. It returns true if the shard is migrating
+func (r *Replicator) bufferIfMigrating(shardID dht.ShardID, le wal.LogEntry) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, ok := r.migrations[shardID]
+	if !ok {
+		return false
+	}
+
+	if len(entries) < r.bufferSize {
+		r.migrations[shardID] = append(entries, le)
+	}
+
+	return true
+}
+
+// finishMigration stops buffering and applies the buffered entries that are not yet present on the shard
+func (r *Replicator) finishMigration(shardID dht.ShardID, shard *datashard.DataShard) {
+	r.mu.Lock()
+	entries := r.migrations[shardID]
+	delete(r.migrations, shardID)
+	r.mu.Unlock()
+
+	for _, le := range entries {
+		if err := shard.Replicate(le); err != nil {
+			// The remaining entries will be streamed during the next catch up
+			r.log.Warn("Unable to apply buffered entries",
+				zap.Int("shardID", int(shardID)),
+				zap.Int64("offset", le.Offset),
+				zap.Error(err),
+			)
+			return
+		}
+	}
+}
+
+// RecordFollowerOffset records the latest offset acknowledged by a follower of a leader shard on this node
+func (r *Replicator) RecordFollowerOffset(shardID dht.ShardID, follower dht.NodeID, offset int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	followers, ok := r.followerOffsets[shardID]
+	if !ok {
+		followers = make(map[dht.NodeID]FollowerStatus)
+		r.followerOffsets[shardID] = followers
+	}
+
+	fs := followers[follower]
+	if offset > fs.Offset || fs.LastContact.IsZero() {
+		fs.Offset = offset
+	}
+	fs.NodeID = follower
+	fs.LastContact = time.Now()
+	followers[follower] = fs
+}
+
+// Status returns the replication status of all the shards on this node
+func (r *Replicator) Status() []ShardStatus {
+	var statuses []ShardStatus
+
+	for _, shardID := range r.dhtMgr.GetAllShardsForNode(r.selfNodeID) {
+		shardLoc, err := r.dhtMgr.GetShardLocation(shardID)
+		if err != nil {
+			continue
+		}
+
+		shard, err := r.getShard(shardID)
+		if err != nil {
+			continue
+		}
+
+		status := ShardStatus{
+			ShardID: shardID,
+			Leader:  shardLoc.Leader.ID,
+			Offset:  shard.GetLatestOffset(),
+		}
+
+		r.mu.Lock()
+		if shardLoc.Leader.ID == r.selfNodeID {
+			for _, follower := range shardLoc.Followers {
+				fs, ok := r.followerOffsets[shardID][follower.ID]
+				if !ok {
+					fs = FollowerStatus{
+						NodeID: follower.ID,
+						Offset: -1,
+					}
+				}
+				fs.Lag = status.Offset - fs.Offset
+				status.Followers = append(status.Followers, fs)
+			}
+		} else {
+			cs := r.catchUps[shardID]
+			status.LastCatchUp = cs.lastCatchUp
+			if cs.err != nil {
+				status.Error = cs.err.Error()
+			}
+			_, status.Migrating = r.migrations[shardID]
+		}
+		r.mu.Unlock()
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ShardID < statuses[j].ShardID
+	})
+
+	return statuses
+}
+
+// keepFollowersInSync catches up all the follower shards on this node every poll interval.
+// This makes sure that a follower that was down catches up with its leader.
+func (r *Replicator) keepFollowersInSync() {
+	ticker := time.NewTicker(r.pollInterval)
+
+	for range ticker.C {
+		for _, shardID := range r.dhtMgr.GetAllShardsForNode(r.selfNodeID) {
+			if err := r.CatchUp(shardID); err != nil {
+				metrics.ReplicationErrors.WithLabelValues(metrics.ReplicationCatchUp).Inc()
+				r.log.Error("Unable to catch up with leader shard",
+					zap.Int("shardID", int(shardID)),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+func (r *Replicator) getShard(shardID dht.ShardID) (*datashard.DataShard, error) {
+	shard, err := r.dataStoreMgr.GetDataNode(shardID)
+	if err != nil {
+		return nil, err
+	}
+	if shard == nil {
+		return nil, ErrShardNotFound
+	}
+
+	return shard, nil
+}
+
+func (r *Replicator) getShardLock(shardID dht.ShardID) *sync.Mutex {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lock, ok := r.shardLocks[shardID]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.shardLocks[shardID] = lock
+	}
+
+	return lock
+}
@@ -0,0 +1,265 @@
+package replicator
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aarthikrao/timeMachine/components/datashard"
+	"github.com/aarthikrao/timeMachine/components/datashard/wal"
+	"github.com/aarthikrao/timeMachine/components/dht"
+	"github.com/aarthikrao/timeMachine/components/jobstore"
+	jm "github.com/aarthikrao/timeMachine/models/jobmodels"
+	dsm "github.com/aarthikrao/timeMachine/process/datastoremanager"
+	"go.uber.org/zap"
+)
+
+const testCollection = "collection1"
+
+// testLeader serves the wal entries and the snapshots of the leader shard
+type testLeader struct {
+	jobstore.JobStoreWithReplicator
+
+	shard *datashard.DataShard
+
+	mu sync.Mutex
+
+	// The entries till removedTill have been removed from the wal of the leader
+	removedTill int64
+
+	// afterSnapshot is called once the snapshot is streamed
+	afterSnapshot func()
+
+	snapshots int
+}
+
+func (l *testLeader) StreamLogEntries(follower dht.NodeID, shardID dht.ShardID, offset int64, f func(wal.LogEntry) error) error {
+	l.mu.Lock()
+	removedTill := l.removedTill
+	l.mu.Unlock()
+
+	if offset < removedTill {
+		return datashard.ErrOffsetNotAvailable
+	}
+
+	return l.shard.StreamLogEntries(offset, f)
+}
+
+func (l *testLeader) StreamShardSnapshot(follower dht.NodeID, shardID dht.ShardID, snapshotOffset, byteOffset int64, f func(jobstore.SnapshotChunk) error) error {
+	if err := l.shard.StreamSnapshot(snapshotOffset, byteOffset, f); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.snapshots++
+	afterSnapshot := l.afterSnapshot
+	l.mu.Unlock()
+
+	if afterSnapshot != nil {
+		afterSnapshot()
+	}
+
+	return nil
+}
+
+func (l *testLeader) GetShardOffset(shardID dht.ShardID) (int64, error) {
+	return l.shard.GetLatestOffset(), nil
+}
+
+// setJobs sets the jobs on the leader shard and returns their wal entries
+func (l *testLeader) setJobs(t *testing.T, from, to int) []wal.LogEntry {
+	t.Helper()
+
+	start := l.shard.GetLatestOffset()
+	for i := from; i < to; i++ {
+		job := &jm.Job{ID: fmt.Sprintf("job%d", i), TriggerMS: int(time.Now().Add(time.Hour).UnixMilli()), Route: "route1"}
+		if _, err := l.shard.SetJob(testCollection, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := []wal.LogEntry{}
+	err := l.shard.StreamLogEntries(start, func(le wal.LogEntry) error {
+		entries = append(entries, le)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return entries
+}
+
+// createTestReplicator returns the replicator of node2, that follows shard 0 led by node1
+func createTestReplicator(t *testing.T) (*Replicator, *datashard.DataShard, *testLeader) {
+	appDht := dht.Create()
+	appDht.Load(map[dht.ShardID]dht.ShardLocation{
+		0: {ID: 0, Leader: dht.NodeDetails{ID: "node1"}, Followers: []dht.NodeDetails{{ID: "node2"}}},
+	}, 1)
+
+	leaderShard, err := datashard.InitialiseDataShard(0, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { leaderShard.Close() })
+
+	dataStoreMgr := dsm.CreateDataStore(t.TempDir(), zap.NewNop())
+	if err = dataStoreMgr.InitialiseDataStores([]dht.ShardID{0}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(dataStoreMgr.Close)
+
+	follower, err := dataStoreMgr.GetDataNode(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leader := &testLeader{shard: leaderShard, removedTill: -1}
+	r := &Replicator{
+		selfNodeID:      "node2",
+		dhtMgr:          appDht,
+		dataStoreMgr:    dataStoreMgr,
+		shardLocks:      make(map[dht.ShardID]*sync.Mutex),
+		followerOffsets: make(map[dht.ShardID]map[dht.NodeID]FollowerStatus),
+		catchUps:        make(map[dht.ShardID]catchUpStatus),
+		migrations:      make(map[dht.ShardID][]wal.LogEntry),
+		bufferSize:      maxBufferedEntries,
+		getLeader: func(nodeID dht.NodeID) (jobstore.JobStoreWithReplicator, error) {
+			return leader, nil
+		},
+		log: zap.NewNop(),
+	}
+
+	return r, follower, leader
+}
+
+// checkJobs fails the test if the jobs are not present on the shard
+func checkJobs(t *testing.T, shard *datashard.DataShard, from, to int) {
+	t.Helper()
+
+	for i := from; i < to; i++ {
+		jobID := fmt.Sprintf("job%d", i)
+		if job, err := shard.GetJob(testCollection, jobID); err != nil || job == nil || job.ID != jobID {
+			t.Errorf("GetJob(%s) = %v, %v", jobID, job, err)
+		}
+	}
+}
+
+func TestReplicateAfterGap(t *testing.T) {
+	r, follower, leader := createTestReplicator(t)
+
+	entries := leader.setJobs(t, 0, 5)
+	for _, le := range entries[:2] {
+		if _, err := r.Replicate(0, le); err != nil {
+			t.Fatalf("Replicate() error = %v", err)
+		}
+	}
+
+	// The entries 2 and 3 were not replicated to the follower, they are streamed from the leader
+	offset, err := r.Replicate(0, entries[4])
+	if err != nil {
+		t.Fatalf("Replicate() error = %v", err)
+	}
+	if offset != 4 {
+		t.Errorf("Replicate() offset = %d, want 4", offset)
+	}
+	if hwm := follower.GetHighWaterMark(); hwm != 4 {
+		t.Errorf("GetHighWaterMark() = %d, want 4", hwm)
+	}
+	if leader.snapshots != 0 {
+		t.Errorf("got %d snapshots, want the entries to be streamed", leader.snapshots)
+	}
+	checkJobs(t, follower, 0, 5)
+}
+
+func TestCatchUpWithSnapshot(t *testing.T) {
+	tests := []struct {
+		name string
+
+		// Number of the entries replicated to the follower before it stopped
+		replicated int
+		// Entries removed from the wal of the leader
+		removedTill int64
+	}{
+		{name: "entries removed from the leader wal", replicated: 2, removedTill: 5},
+		{name: "new owner of the shard", replicated: 0, removedTill: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, follower, leader := createTestReplicator(t)
+
+			entries := leader.setJobs(t, 0, 10)
+			for _, le := range entries[:tt.replicated] {
+				if _, err := r.Replicate(0, le); err != nil {
+					t.Fatalf("Replicate() error = %v", err)
+				}
+			}
+			leader.removedTill = tt.removedTill
+
+			// The jobs set after the snapshot are streamed from the leader
+			leader.afterSnapshot = func() { leader.setJobs(t, 10, 12) }
+
+			if err := r.CatchUp(0); err != nil {
+				t.Fatalf("CatchUp() error = %v", err)
+			}
+			if leader.snapshots != 1 {
+				t.Errorf("got %d snapshots, want 1", leader.snapshots)
+			}
+			if offset, want := follower.GetLatestOffset(), leader.shard.GetLatestOffset(); offset != want {
+				t.Errorf("GetLatestOffset() = %d, want %d", offset, want)
+			}
+			checkJobs(t, follower, 0, 12)
+
+			status := r.Status()
+			if len(status) != 1 || status[0].Migrating || status[0].Error != "" || status[0].LastCatchUp.IsZero() {
+				t.Errorf("Status() = %+v, want a caught up shard", status)
+			}
+		})
+	}
+}
+
+func TestBufferOverflowDuringSnapshot(t *testing.T) {
+	r, follower, leader := createTestReplicator(t)
+	r.bufferSize = 3
+
+	leader.setJobs(t, 0, 5)
+
+	// The leader replicates the writes made while the snapshot is being installed, the entries after
+	// the first bufferSize entries are dropped and streamed from the leader after the snapshot
+	var buffered int
+	leader.afterSnapshot = func() {
+		for _, le := range leader.setJobs(t, 5, 10) {
+			if _, err := r.Replicate(0, le); err != ErrShardMigrating {
+				t.Errorf("Replicate() error = %v, want %v", err, ErrShardMigrating)
+			}
+		}
+
+		if status := r.Status(); len(status) != 1 || !status[0].Migrating {
+			t.Errorf("Status() = %+v, want a migrating shard", status)
+		}
+
+		r.mu.Lock()
+		buffered = len(r.migrations[0])
+		r.mu.Unlock()
+	}
+
+	if err := r.CatchUp(0); err != nil {
+		t.Fatalf("CatchUp() error = %v", err)
+	}
+	if buffered != 3 {
+		t.Errorf("got %d buffered entries, want 3", buffered)
+	}
+	if offset, want := follower.GetLatestOffset(), leader.shard.GetLatestOffset(); offset != want {
+		t.Errorf("GetLatestOffset() = %d, want %d", offset, want)
+	}
+	checkJobs(t, follower, 0, 10)
+
+	r.mu.Lock()
+	_, migrating := r.migrations[0]
+	r.mu.Unlock()
+	if migrating {
+		t.Errorf("shard is migrating after the catch up")
+	}
+}
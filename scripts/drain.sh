@@ -0,0 +1,16 @@
+#!/bin/bash
+
+# Ensure the ip:port of the raft leader and the nodeID are provided
+if [ "$#" -lt 2 ]; then
+  echo "Usage: $0 <ip:port> <nodeID> [drain|undrain]"
+  exit 1
+fi
+
+# Drain by default
+ACTION=${3:-drain}
+
+# Construct the target URL with the ip:port
+URL="http://$1/cluster/$ACTION/$2"
+
+# Send the HTTP POST request using cURL
+curl --request POST "$URL"
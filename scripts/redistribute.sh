@@ -0,0 +1,13 @@
+#!/bin/bash
+
+# Ensure the ip:port of the raft leader is provided
+if [ "$#" -ne 1 ]; then
+  echo "Usage: $0 <ip:port>"
+  exit 1
+fi
+
+# Construct the target URL with the ip:port
+URL="http://$1/cluster/redistribute"
+
+# Send the HTTP POST request using cURL
+curl --request POST "$URL"
@@ -2,10 +2,13 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/aarthikrao/timeMachine/utils/tracing"
 )
 
 // HTTPClient is a struct that represents an HTTP client.
@@ -57,12 +60,24 @@ func (c *HTTPClient) Get(url string) ([]byte, int, error) {
 
 // Post performs an HTTP POST request and returns the response body, status code, and error if any.
 func (c *HTTPClient) Post(url string, object interface{}) ([]byte, int, error) {
+	return c.PostWithContext(context.Background(), url, object)
+}
+
+// PostWithContext performs an HTTP POST request like Post. The trace context of ctx is sent in the headers.
+func (c *HTTPClient) PostWithContext(ctx context.Context, url string, object interface{}) ([]byte, int, error) {
 	by, err := json.Marshal(object)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	resp, err := c.client.Post(url, "application/json", bytes.NewBuffer(by))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(by))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	tracing.InjectHeaders(req)
+
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,164 @@
+// Package metrics contains the prometheus metrics of a time machine node.
+// They are served on the /metrics endpoint of the HTTP server.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "timemachine"
+
+// Outcomes of a job picked up by the publisher
+const (
+	OutcomePublished = "published"
+	OutcomeFailed    = "failed"
+	OutcomeSkipped   = "skipped"
+)
+
+// Sources of the replication errors
+const (
+	// The leader could not replicate a write to the follower
+	ReplicationWrite = "write"
+
+	// The follower could not catch up with the leader
+	ReplicationCatchUp = "catch_up"
+)
+
+var (
+	RESTRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rest",
+		Name:      "requests_total",
+		Help:      "Number of REST requests by method, route and status code",
+	}, []string{"method", "path", "status"})
+
+	RESTRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rest",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to serve the REST requests",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Number of GRPC requests served by method and status code",
+	}, []string{"method", "code"})
+
+	GRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to serve the GRPC requests. Streams are measured till they end",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	JobsQueued = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "executor",
+		Name:      "jobs_queued_total",
+		Help:      "Number of jobs added to the executor",
+	})
+
+	TriggerDrift = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "publisher",
+		Name:      "trigger_drift_seconds",
+		Help:      "Time between the trigger time of the jobs and their first publish attempt",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	})
+
+	Publishes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "publisher",
+		Name:      "jobs_total",
+		Help:      "Number of jobs picked up by the publisher by route and outcome. The outcome is published, failed or skipped",
+	}, []string{"route", "outcome"})
+
+	WALOffset = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "wal",
+		Name:      "offset",
+		Help:      "Latest wal offset of the shards on this node",
+	}, []string{"shard"})
+
+	WALAppendDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "wal",
+		Name:      "append_duration_seconds",
+		Help:      "Time taken to append an entry to the wal. The wal segments are synced to the disk in the background",
+		Buckets:   prometheus.ExponentialBuckets(0.00005, 2, 14),
+	})
+
+	DatastoreCommitDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "datastore",
+		Name:      "commit_duration_seconds",
+		Help:      "Time taken to commit a write to the datastore of a shard, including the fsync",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 14),
+	})
+
+	ReplicationErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "replication",
+		Name:      "errors_total",
+		Help:      "Number of replication errors by source. The source is write or catch_up",
+	}, []string{"source"})
+
+	NodeReachable = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "health",
+		Name:      "node_reachable",
+		Help:      "Result of the last health check of the nodes, 1 if the node was reachable. The nodes are checked by the raft leader",
+	}, []string{"node"})
+
+	NodePhi = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "health",
+		Name:      "node_phi",
+		Help:      "Suspicion level of the nodes by the phi accrual failure detector on the raft leader",
+	}, []string{"node"})
+
+	HealthChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "health",
+		Name:      "checks_total",
+		Help:      "Number of health checks of the nodes by result. The result is reachable or unreachable",
+	}, []string{"node", "result"})
+
+	ShardsUnavailable = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "health",
+		Name:      "shards_unavailable",
+		Help:      "Number of shards without a reachable replica, as seen by the raft leader",
+	})
+
+	FailoversRefused = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "health",
+		Name:      "failovers_refused_total",
+		Help:      "Number of failovers refused as no healthy follower was caught up with the failed leader",
+	})
+
+	LeaderHandovers = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "health",
+		Name:      "leader_handovers_total",
+		Help:      "Number of shards whose leadership was handed over to a follower leading fewer shards",
+	})
+)
+
+// RegisterExecutorHeapSize registers the gauge of the number of entries in the executor heap
+func RegisterExecutorHeapSize(size func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "executor",
+		Name:      "heap_size",
+		Help:      "Number of entries in the executor heap, including the entries of the updated and deleted jobs yet to be popped",
+	}, func() float64 {
+		return float64(size())
+	})
+}
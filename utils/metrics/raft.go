@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// raftStates are the states reported by raft
+var raftStates = []string{"Follower", "Candidate", "Leader", "Shutdown"}
+
+// raftIndexes are the stats of raft that are exported as gauges
+var raftIndexes = []string{"term", "last_log_index", "commit_index", "applied_index", "num_peers"}
+
+// raftCollector reads the stats of raft when the metrics are scraped
+type raftCollector struct {
+	stats func() map[string]string
+
+	state   *prometheus.Desc
+	indexes map[string]*prometheus.Desc
+}
+
+// RegisterRaftStats registers the state and the indexes of raft on this node
+func RegisterRaftStats(stats func() map[string]string) {
+	prometheus.MustRegister(newRaftCollector(stats))
+}
+
+func newRaftCollector(stats func() map[string]string) *raftCollector {
+	rc := &raftCollector{
+		stats: stats,
+		state: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "raft", "state"),
+			"State of raft on this node. The gauge of the current state is 1",
+			[]string{"state"}, nil,
+		),
+		indexes: make(map[string]*prometheus.Desc),
+	}
+
+	for _, name := range raftIndexes {
+		rc.indexes[name] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "raft", name),
+			"Raft "+name+" on this node",
+			nil, nil,
+		)
+	}
+
+	return rc
+}
+
+func (rc *raftCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rc.state
+	for _, desc := range rc.indexes {
+		ch <- desc
+	}
+}
+
+func (rc *raftCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := rc.stats()
+
+	for _, state := range raftStates {
+		var value float64
+		if stats["state"] == state {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(rc.state, prometheus.GaugeValue, value, state)
+	}
+
+	for name, desc := range rc.indexes {
+		value, err := strconv.ParseFloat(stats[name], 64)
+		if err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRaftCollector(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats map[string]string
+		want  string
+	}{
+		{
+			name:  "leader",
+			stats: map[string]string{"state": "Leader", "term": "3", "commit_index": "42"},
+			want: `
+# HELP timemachine_raft_state State of raft on this node. The gauge of the current state is 1
+# TYPE timemachine_raft_state gauge
+timemachine_raft_state{state="Candidate"} 0
+timemachine_raft_state{state="Follower"} 0
+timemachine_raft_state{state="Leader"} 1
+timemachine_raft_state{state="Shutdown"} 0
+# HELP timemachine_raft_term Raft term on this node
+# TYPE timemachine_raft_term gauge
+timemachine_raft_term 3
+# HELP timemachine_raft_commit_index Raft commit_index on this node
+# TYPE timemachine_raft_commit_index gauge
+timemachine_raft_commit_index 42
+`,
+		},
+		{
+			name:  "invalid index",
+			stats: map[string]string{"state": "Follower", "term": "unknown"},
+			want: `
+# HELP timemachine_raft_state State of raft on this node. The gauge of the current state is 1
+# TYPE timemachine_raft_state gauge
+timemachine_raft_state{state="Candidate"} 0
+timemachine_raft_state{state="Follower"} 1
+timemachine_raft_state{state="Leader"} 0
+timemachine_raft_state{state="Shutdown"} 0
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := newRaftCollector(func() map[string]string { return tt.stats })
+			if err := testutil.CollectAndCompare(rc, strings.NewReader(tt.want)); err != nil {
+				t.Errorf("Unexpected metrics: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package tracing contains the opentelemetry tracing of a time machine node.
+// The trace context is carried over the HTTP headers and the GRPC metadata, and is stored on the
+// jobs so that their publish can be linked to the write that scheduled them.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/aarthikrao/timeMachine"
+
+// Exporters of the spans
+const (
+	// The spans are not recorded. The trace context is still propagated to the other nodes
+	ExporterNone = "none"
+
+	// The spans are written to the stdout
+	ExporterStdout = "stdout"
+)
+
+// Attributes of the spans
+const (
+	CollectionKey = attribute.Key("timemachine.collection")
+	JobIDKey      = attribute.Key("timemachine.job_id")
+	ShardIDKey    = attribute.Key("timemachine.shard_id")
+	NodeIDKey     = attribute.Key("timemachine.node_id")
+	RouteKey      = attribute.Key("timemachine.route")
+	BatchSizeKey  = attribute.Key("timemachine.batch_size")
+)
+
+var ErrInvalidExporter = errors.New("invalid trace exporter")
+
+// Init sets the propagator of the trace context and the tracer provider that sends the spans of this
+// node to the exporter. It returns a function that flushes the remaining spans and stops the provider.
+func Init(exporter, nodeID string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+
+	case ExporterStdout:
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+		tp := NewTracerProvider(sdktrace.NewBatchSpanProcessor(exp), nodeID)
+		otel.SetTracerProvider(tp)
+		return tp.Shutdown, nil
+	}
+
+	return nil, ErrInvalidExporter
+}
+
+// NewTracerProvider returns a tracer provider that sends the spans of this node to the span processor
+func NewTracerProvider(sp sdktrace.SpanProcessor, nodeID string) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(sp),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "timeMachine"),
+			attribute.String("service.instance.id", nodeID),
+		)),
+	)
+}
+
+// Start starts a span as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartLinked starts a new trace with a span that is linked to the trace context stored on a job.
+// The job is published long after the request that set it has returned, hence it is not a child of the request.
+func StartLinked(name string, traceContext map[string]string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{trace.WithNewRoot(), trace.WithAttributes(attrs...)}
+	if sc := trace.SpanContextFromContext(Extract(traceContext)); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+
+	return otel.Tracer(instrumentationName).Start(context.Background(), name, opts...)
+}
+
+// End records the error on the span if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject returns the trace context of ctx to be stored on a job. It is nil if ctx is not traced
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+
+	return carrier
+}
+
+// Extract returns a context with the trace context stored on a job
+func Extract(traceContext map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(traceContext))
+}
+
+// Detach returns a context with the trace of ctx but without its deadline and cancellation.
+// It is used for the work that outlives the request, like the replication to the slower followers.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+}
@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTest(t *testing.T) *tracetest.InMemoryExporter {
+	if _, err := Init(ExporterNone, "node1"); err != nil {
+		t.Fatal(err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := NewTracerProvider(sdktrace.NewSimpleSpanProcessor(exporter), "node1")
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+
+	return exporter
+}
+
+func TestStartLinked(t *testing.T) {
+	exporter := setupTest(t)
+
+	ctx, write := Start(context.Background(), "cordinator.SetJob")
+	traceContext := Inject(ctx)
+	write.End()
+
+	tests := []struct {
+		name         string
+		traceContext map[string]string
+		wantLinks    int
+	}{
+		{name: "job set with a trace", traceContext: traceContext, wantLinks: 1},
+		{name: "job set without a trace", traceContext: nil, wantLinks: 0},
+		{name: "invalid trace context", traceContext: map[string]string{"traceparent": "invalid"}, wantLinks: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+
+			_, publish := StartLinked("publisher.Publish", tt.traceContext)
+			End(publish, nil)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+
+			got := spans[0]
+			if got.Parent.IsValid() {
+				t.Errorf("publish span has parent %v, want a new trace", got.Parent.SpanID())
+			}
+			if got.SpanContext.TraceID() == write.SpanContext().TraceID() {
+				t.Errorf("publish span is in the trace of the write")
+			}
+			if len(got.Links) != tt.wantLinks {
+				t.Fatalf("got %d links, want %d", len(got.Links), tt.wantLinks)
+			}
+			if tt.wantLinks > 0 && got.Links[0].SpanContext.SpanID() != write.SpanContext().SpanID() {
+				t.Errorf("publish span is linked to %v, want %v", got.Links[0].SpanContext.SpanID(), write.SpanContext().SpanID())
+			}
+		})
+	}
+}
+
+func TestGinMiddleware(t *testing.T) {
+	setupTest(t)
+	gin.SetMode(gin.TestMode)
+
+	var got trace.SpanContext
+	r := gin.New()
+	r.Use(GinMiddleware())
+	r.POST("/job/:collection", func(c *gin.Context) {
+		got = trace.SpanContextFromContext(c.Request.Context())
+	})
+
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTraceID string
+	}{
+		{
+			name:        "continues the trace of the client",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name:        "starts a new trace",
+			traceparent: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/job/collection1", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !got.IsValid() {
+				t.Fatalf("request has no span")
+			}
+			if tt.wantTraceID != "" && got.TraceID().String() != tt.wantTraceID {
+				t.Errorf("got trace %v, want %v", got.TraceID(), tt.wantTraceID)
+			}
+		})
+	}
+}